@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"fmt"
+	"kool-dev/kool/cmd/compose"
+	"kool-dev/kool/cmd/parser"
+	"kool-dev/kool/cmd/presets"
+	"kool-dev/kool/cmd/shell"
+	"kool-dev/kool/environment"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// KoolAddFlags holds the flags for the add command
+type KoolAddFlags struct {
+	List bool
+}
+
+// addFileChange is a project file changed by kool add,
+// along with its original content for rolling it back
+type addFileChange struct {
+	fileName string
+	content  string
+	original string
+	existed  bool
+	added    []string
+}
+
+// KoolAdd holds handlers and functions to implement the add command logic
+type KoolAdd struct {
+	DefaultKoolService
+	Flags *KoolAddFlags
+
+	envStorage    environment.EnvStorage
+	presetsParser presets.Parser
+	composeParser compose.Parser
+	table         shell.TableWriter
+}
+
+func init() {
+	rootCmd.AddCommand(NewAddCommand(NewKoolAdd()))
+}
+
+// NewKoolAdd creates a new handler for add logic with default dependencies
+func NewKoolAdd() *KoolAdd {
+	return &KoolAdd{
+		*newDefaultKoolService(),
+		&KoolAddFlags{false},
+		environment.NewEnvStorage(),
+		presets.NewParser(),
+		compose.NewParser(),
+		shell.NewTableWriter(),
+	}
+}
+
+// Execute runs the add logic with incoming arguments.
+func (a *KoolAdd) Execute(args []string) (err error) {
+	var (
+		service, templatePath  string
+		content, scripts       string
+		template               *presets.Template
+		compose, env, koolYml  string
+		localCompose, localEnv string
+		localKoolYml           string
+		hasEnv, hasKoolYml     bool
+		composeAdded, envAdded []string
+		scriptsAdded           []string
+	)
+
+	if err = loadAllPresets(a, a.presetsParser, a.envStorage); err != nil {
+		return
+	}
+
+	if a.Flags.List {
+		return a.listTemplates(args)
+	}
+
+	if len(args) != 2 {
+		err = fmt.Errorf("missing the SERVICE and TEMPLATE arguments; use --list to see the available templates")
+		return
+	}
+
+	service = args[0]
+
+	if templatePath, content, err = findAddTemplate(a.presetsParser.GetTemplates(), service, args[1]); err != nil {
+		return
+	}
+
+	if template, err = presets.ParseTemplate(content); err != nil {
+		err = fmt.Errorf("template %s: %v", templatePath, err)
+		return
+	}
+
+	if compose, err = a.presetsParser.ReadFile("docker-compose.yml"); err != nil {
+		if os.IsNotExist(err) {
+			err = fmt.Errorf("docker-compose.yml not found; kool add works on existing projects")
+		}
+		return
+	}
+
+	localCompose = compose
+
+	if err = a.composeParser.Load(compose); err != nil {
+		return
+	}
+
+	if a.composeParser.HasService(service) {
+		err = fmt.Errorf("service %s already exists in docker-compose.yml", service)
+		return
+	}
+
+	if composeAdded, err = a.mergeService(service, template); err != nil {
+		return
+	}
+
+	if compose, err = a.composeParser.String(); err != nil {
+		return
+	}
+
+	if localEnv, hasEnv, err = a.readProjectFile(".env"); err != nil {
+		return
+	}
+
+	env, envAdded = addEnvKeys(localEnv, template.Env)
+
+	if localKoolYml, hasKoolYml, err = a.readProjectFile("kool.yml"); err != nil {
+		return
+	}
+
+	if scripts, err = addScriptsYaml(template.Scripts); err != nil {
+		return
+	}
+
+	if koolYml, scriptsAdded, err = parser.MergeKoolYaml(localKoolYml, scripts); err != nil {
+		err = fmt.Errorf("failed adding kool.yml scripts: %v", err)
+		return
+	}
+
+	if err = a.writeProjectFiles([]*addFileChange{
+		{"docker-compose.yml", compose, localCompose, true, composeAdded},
+		{".env", env, localEnv, hasEnv, envAdded},
+		{"kool.yml", koolYml, localKoolYml, hasKoolYml, scriptsAdded},
+	}); err != nil {
+		return
+	}
+
+	a.Success("Service ", service, " added from template ", template.Name)
+	return
+}
+
+// listTemplates prints the templates catalog, optionally
+// narrowed down to the given categories.
+func (a *KoolAdd) listTemplates(categories []string) (err error) {
+	var templates = a.presetsParser.GetTemplates()
+
+	a.table.SetWriter(a.GetWriter())
+	a.table.AppendHeader("Category", "Template", "Name", "Ports")
+
+	for _, category := range sortedTemplateKeys(templates) {
+		if len(categories) > 0 && !containsString(categories, category) {
+			continue
+		}
+
+		for _, fileName := range sortedKeys(templates[category]) {
+			var (
+				template *presets.Template
+				ports    []string
+			)
+
+			if template, err = presets.ParseTemplate(templates[category][fileName]); err != nil {
+				err = fmt.Errorf("template %s/%s: %v", category, fileName, err)
+				return
+			}
+
+			for _, port := range template.Ports {
+				ports = append(ports, fmt.Sprint(port))
+			}
+
+			a.table.AppendRow(category, strings.TrimSuffix(fileName, ".yml"), template.Name, strings.Join(ports, ", "))
+		}
+	}
+
+	a.table.Render()
+	return
+}
+
+// mergeService adds the service to the loaded docker-compose along
+// with the named volumes and networks the template refers to.
+func (a *KoolAdd) mergeService(service string, template *presets.Template) (added []string, err error) {
+	var (
+		serviceYaml string
+		snippet     strings.Builder
+	)
+
+	if serviceYaml, err = template.ServiceYaml(); err != nil {
+		return
+	}
+
+	snippet.WriteString("services:\n  " + service + ":\n")
+
+	for _, line := range strings.Split(strings.TrimRight(serviceYaml, "\n"), "\n") {
+		snippet.WriteString("    " + line + "\n")
+	}
+
+	writeSnippetSection(&snippet, "volumes", template.Volumes)
+	writeSnippetSection(&snippet, "networks", template.Networks())
+
+	return a.composeParser.Merge(snippet.String())
+}
+
+// readProjectFile reads the project file, which is empty when missing.
+func (a *KoolAdd) readProjectFile(fileName string) (content string, exists bool, err error) {
+	if content, err = a.presetsParser.ReadFile(fileName); os.IsNotExist(err) {
+		err = nil
+		return
+	}
+
+	exists = err == nil
+	return
+}
+
+// writeProjectFiles writes the changed project files; when writing one
+// of them fails the ones written so far are rolled back, so the project
+// is never left half-modified.
+func (a *KoolAdd) writeProjectFiles(changes []*addFileChange) (err error) {
+	var fileError string
+
+	for i, change := range changes {
+		if len(change.added) == 0 {
+			continue
+		}
+
+		if fileError, err = a.presetsParser.WriteFile(change.fileName, change.content); err != nil {
+			err = fmt.Errorf("Failed to write file %s: %v", fileError, err)
+			a.rollbackProjectFiles(changes[:i+1])
+			return
+		}
+	}
+
+	for _, change := range changes {
+		if len(change.added) > 0 {
+			a.Println("Added", strings.Join(change.added, ", "), "to", change.fileName)
+		}
+	}
+
+	return
+}
+
+// rollbackProjectFiles restores the changed project files
+// original contents, removing the ones that didn't exist.
+func (a *KoolAdd) rollbackProjectFiles(changes []*addFileChange) {
+	for _, change := range changes {
+		var err error
+
+		if len(change.added) == 0 {
+			continue
+		}
+
+		if change.existed {
+			_, err = a.presetsParser.WriteFile(change.fileName, change.original)
+		} else {
+			err = a.presetsParser.RemoveFile(change.fileName)
+		}
+
+		if err != nil {
+			a.Warning("Failed to roll back ", change.fileName, ": ", err)
+		}
+	}
+}
+
+// findAddTemplate looks up the template for the service type by its
+// file name, with or without extension, or by an unambiguous prefix.
+func findAddTemplate(templates map[string]map[string]string, serviceType string, name string) (path string, content string, err error) {
+	var (
+		serviceTemplates = templates[serviceType]
+		names, matched   []string
+	)
+
+	if len(serviceTemplates) == 0 {
+		err = fmt.Errorf("unknown service type %s; available types: %s", serviceType, strings.Join(sortedTemplateKeys(templates), ", "))
+		return
+	}
+
+	for fileName := range serviceTemplates {
+		names = append(names, strings.TrimSuffix(fileName, ".yml"))
+	}
+
+	sort.Strings(names)
+
+	for _, templateName := range names {
+		if templateName == strings.TrimSuffix(name, ".yml") {
+			matched = []string{templateName}
+			break
+		}
+
+		if strings.HasPrefix(templateName, name) {
+			matched = append(matched, templateName)
+		}
+	}
+
+	if len(matched) != 1 {
+		err = fmt.Errorf("unknown %s template %s; available templates: %s", serviceType, name, strings.Join(names, ", "))
+		return
+	}
+
+	path = serviceType + "/" + matched[0] + ".yml"
+	content = serviceTemplates[matched[0]+".yml"]
+	return
+}
+
+// addEnvKeys appends to the .env content the template
+// variables that are missing, set to their default values.
+func addEnvKeys(env string, defaults map[string]string) (content string, added []string) {
+	content = env
+
+	for _, key := range sortedKeys(defaults) {
+		value := defaults[key]
+
+		if hasEnvKey(env, key) {
+			continue
+		}
+
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+
+		content += key + "=" + value + "\n"
+		added = append(added, key)
+	}
+
+	return
+}
+
+func hasEnvKey(env string, key string) bool {
+	for _, line := range strings.Split(env, "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "export ")
+
+		if strings.HasPrefix(line, key+"=") || strings.HasPrefix(line, key+" =") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addScriptsYaml renders the template scripts as a kool.yml content,
+// marshaling them so commands holding YAML syntax are kept verbatim.
+func addScriptsYaml(scripts map[string]string) (content string, err error) {
+	var out []byte
+
+	if out, err = yaml.Marshal(map[string]map[string]string{"scripts": scripts}); err != nil {
+		return
+	}
+
+	content = string(out)
+	return
+}
+
+func writeSnippetSection(snippet *strings.Builder, section string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+
+	snippet.WriteString(section + ":\n")
+
+	for _, item := range items {
+		snippet.WriteString("  " + item + ":\n")
+	}
+}
+
+func sortedTemplateKeys(templates map[string]map[string]string) (keys []string) {
+	for key := range templates {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return
+}
+
+// NewAddCommand initializes new kool add command
+func NewAddCommand(add *KoolAdd) (addCmd *cobra.Command) {
+	addCmd = &cobra.Command{
+		Use:   "add SERVICE TEMPLATE",
+		Short: "Add a service from the kool templates to the project docker-compose.yml",
+		Long: `Add a service to the project docker-compose.yml from the kool templates, e.g
+"kool add database mysql80" or "kool add cache redis". The service named volumes
+and networks, its .env variables and kool.yml helper scripts are added as well.
+Use "kool add --list [CATEGORY]" to see the templates catalog.`,
+		Args: cobra.MaximumNArgs(2),
+		Run:  DefaultCommandRunFunction(add),
+	}
+
+	addCmd.Flags().BoolVarP(&add.Flags.List, "list", "l", false, "List the available templates, optionally of the given categories")
+	return
+}
@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"errors"
+	"kool-dev/kool/cmd/compose"
+	"kool-dev/kool/cmd/presets"
+	"kool-dev/kool/cmd/shell"
+	"kool-dev/kool/environment"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newFakeKoolAdd() *KoolAdd {
+	return &KoolAdd{
+		*newFakeKoolService(),
+		&KoolAddFlags{false},
+		environment.NewFakeEnvStorage(),
+		&presets.FakeParser{
+			MockTemplates: map[string]map[string]string{
+				"database": map[string]string{
+					"mysql57.yml": mysqlTemplate,
+					"mysql80.yml": mysqlTemplate,
+				},
+			},
+			MockFiles: map[string]string{
+				"docker-compose.yml": "services:\n  app:\n    image: app\n",
+				".env":               "DB_PASSWORD=secret",
+			},
+		},
+		&compose.FakeParser{},
+		&shell.FakeTableWriter{},
+	}
+}
+
+func TestNewKoolAdd(t *testing.T) {
+	k := NewKoolAdd()
+
+	if _, ok := k.DefaultKoolService.out.(*shell.DefaultOutputWriter); !ok {
+		t.Errorf("unexpected shell.OutputWriter on default KoolAdd instance")
+	}
+
+	if _, ok := k.envStorage.(*environment.DefaultEnvStorage); !ok {
+		t.Errorf("unexpected environment.EnvStorage on default KoolAdd instance")
+	}
+
+	if _, ok := k.presetsParser.(*presets.DefaultParser); !ok {
+		t.Errorf("unexpected presets.Parser on default KoolAdd instance")
+	}
+
+	if _, ok := k.composeParser.(*compose.DefaultParser); !ok {
+		t.Errorf("unexpected compose.Parser on default KoolAdd instance")
+	}
+
+	if _, ok := k.table.(*shell.DefaultTableWriter); !ok {
+		t.Errorf("unexpected shell.TableWriter on default KoolAdd instance")
+	}
+}
+
+func TestAddCommand(t *testing.T) {
+	f := newFakeKoolAdd()
+	f.composeParser.(*compose.FakeParser).MockMergeAdded = []string{"services.database", "volumes.database"}
+	f.composeParser.(*compose.FakeParser).MockString = "merged compose"
+
+	cmd := NewAddCommand(f)
+	cmd.SetArgs([]string{"database", "mysql8"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing add command; error: %v", err)
+	}
+
+	if f.out.(*shell.FakeOutputWriter).CalledError {
+		t.Errorf("unexpected error: %v", f.out.(*shell.FakeOutputWriter).Err)
+	}
+
+	if !f.composeParser.(*compose.FakeParser).CalledHasService["database"] {
+		t.Error("did not check whether the service already exists")
+	}
+
+	var merged string
+	for compose := range f.composeParser.(*compose.FakeParser).CalledMerge {
+		merged = compose
+	}
+
+	if !strings.HasPrefix(merged, "services:\n  database:\n    image: mysql:8.0\n") ||
+		!strings.HasSuffix(merged, "volumes:\n  database:\nnetworks:\n  kool_local:\n") {
+		t.Errorf("unexpected docker-compose merged for the service: %s", merged)
+	}
+
+	written := f.presetsParser.(*presets.FakeParser).CalledWriteFile
+
+	if !written["docker-compose.yml"]["merged compose"] {
+		t.Error("did not write docker-compose.yml")
+	}
+
+	expectedEnv := "DB_PASSWORD=secret\nDB_DATABASE=database\nKOOL_DATABASE_PORT=3306\n"
+
+	if !written[".env"][expectedEnv] {
+		t.Errorf("did not write the missing .env keys; written: %v", written[".env"])
+	}
+
+	if !written["kool.yml"]["scripts:\n  mysql: kool exec database mysql -uroot -p$DB_PASSWORD\n"] {
+		t.Errorf("did not write the kool.yml helper scripts; written: %v", written["kool.yml"])
+	}
+
+	if !f.out.(*shell.FakeOutputWriter).CalledSuccess {
+		t.Error("did not call Success")
+	}
+}
+
+func TestExistingServiceAddCommand(t *testing.T) {
+	f := newFakeKoolAdd()
+	f.composeParser.(*compose.FakeParser).MockHasService = true
+
+	cmd := NewAddCommand(f)
+	cmd.SetArgs([]string{"database", "mysql80"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing add command; error: %v", err)
+	}
+
+	expected := "service database already exists in docker-compose.yml"
+
+	if err := f.out.(*shell.FakeOutputWriter).Err; err == nil || err.Error() != expected {
+		t.Errorf("expecting error '%s', got '%v'", expected, err)
+	}
+
+	if len(f.presetsParser.(*presets.FakeParser).CalledWriteFile) > 0 {
+		t.Error("unexpected file writing")
+	}
+}
+
+func TestNoComposeAddCommand(t *testing.T) {
+	f := newFakeKoolAdd()
+	delete(f.presetsParser.(*presets.FakeParser).MockFiles, "docker-compose.yml")
+
+	cmd := NewAddCommand(f)
+	cmd.SetArgs([]string{"database", "mysql80"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing add command; error: %v", err)
+	}
+
+	expected := "docker-compose.yml not found; kool add works on existing projects"
+
+	if err := f.out.(*shell.FakeOutputWriter).Err; err == nil || err.Error() != expected {
+		t.Errorf("expecting error '%s', got '%v'", expected, err)
+	}
+}
+
+func TestRollbackAddCommand(t *testing.T) {
+	f := newFakeKoolAdd()
+	f.composeParser.(*compose.FakeParser).MockMergeAdded = []string{"services.database"}
+	f.composeParser.(*compose.FakeParser).MockString = "merged compose"
+	f.presetsParser.(*presets.FakeParser).MockWriteErrors = map[string]error{"kool.yml": errors.New("disk full")}
+
+	cmd := NewAddCommand(f)
+	cmd.SetArgs([]string{"database", "mysql80"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing add command; error: %v", err)
+	}
+
+	expected := "Failed to write file kool.yml: disk full"
+
+	if err := f.out.(*shell.FakeOutputWriter).Err; err == nil || err.Error() != expected {
+		t.Errorf("expecting error '%s', got '%v'", expected, err)
+	}
+
+	written := f.presetsParser.(*presets.FakeParser).CalledWriteFile
+
+	if !written["docker-compose.yml"]["services:\n  app:\n    image: app\n"] {
+		t.Errorf("did not restore docker-compose.yml; written: %v", written["docker-compose.yml"])
+	}
+
+	if !written[".env"]["DB_PASSWORD=secret"] {
+		t.Errorf("did not restore .env; written: %v", written[".env"])
+	}
+
+	if !f.presetsParser.(*presets.FakeParser).CalledRemoveFile["kool.yml"] {
+		t.Error("did not remove the kool.yml that didn't exist")
+	}
+
+	if len(f.out.(*shell.FakeOutputWriter).OutLines) > 0 {
+		t.Errorf("unexpected output for files rolled back: %v", f.out.(*shell.FakeOutputWriter).OutLines)
+	}
+}
+
+func TestAddScriptsYaml(t *testing.T) {
+	content, err := addScriptsYaml(map[string]string{
+		"mysql": "kool exec database mysql -uroot -p$DB_PASSWORD",
+		"query": "kool exec database psql -c 'SELECT 1' # run: a query",
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error rendering scripts: %v", err)
+	}
+
+	expected := "scripts:\n    mysql: kool exec database mysql -uroot -p$DB_PASSWORD\n    query: 'kool exec database psql -c ''SELECT 1'' # run: a query'\n"
+
+	if content != expected {
+		t.Errorf("expected scripts:\n%s\ngot:\n%s", expected, content)
+	}
+}
+
+func TestFindAddTemplate(t *testing.T) {
+	templates := map[string]map[string]string{
+		"database": map[string]string{
+			"mysql57.yml":       "mysql57",
+			"mysql80.yml":       "mysql80",
+			"postgresql130.yml": "postgresql130",
+		},
+		"cache": map[string]string{
+			"redis60.yml": "redis60",
+		},
+	}
+
+	tests := []struct {
+		serviceType, name, path, err string
+	}{
+		{"database", "mysql80", "database/mysql80.yml", ""},
+		{"database", "mysql57.yml", "database/mysql57.yml", ""},
+		{"database", "postgres", "database/postgresql130.yml", ""},
+		{"cache", "redis", "cache/redis60.yml", ""},
+		{"database", "mysql", "", "unknown database template mysql; available templates: mysql57, mysql80, postgresql130"},
+		{"queue", "rabbitmq", "", "unknown service type queue; available types: cache, database"},
+	}
+
+	for _, test := range tests {
+		path, content, err := findAddTemplate(templates, test.serviceType, test.name)
+
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("expecting error '%s' for %s %s, got '%v'", test.err, test.serviceType, test.name, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("unexpected error for %s %s: %v", test.serviceType, test.name, err)
+		} else if path != test.path || content != templates[test.serviceType][strings.TrimPrefix(path, test.serviceType+"/")] {
+			t.Errorf("expecting template %s for %s %s, got %s", test.path, test.serviceType, test.name, path)
+		}
+	}
+}
+
+func TestAddEnvKeys(t *testing.T) {
+	env, added := addEnvKeys("export DB_PASSWORD=secret\nAPP=app", map[string]string{
+		"DB_PASSWORD": "pass",
+		"IMAGE":       "app",
+		"EMPTY":       "",
+	})
+
+	if expected := []string{"EMPTY", "IMAGE"}; !reflect.DeepEqual(added, expected) {
+		t.Errorf("expecting added keys %v, got %v", expected, added)
+	}
+
+	if expected := "export DB_PASSWORD=secret\nAPP=app\nEMPTY=\nIMAGE=app\n"; env != expected {
+		t.Errorf("expecting .env '%s', got '%s'", expected, env)
+	}
+}
+
+func TestListAddCommand(t *testing.T) {
+	f := newFakeKoolAdd()
+	f.presetsParser.(*presets.FakeParser).MockTemplates["cache"] = map[string]string{
+		"redis60.yml": "name: Redis 6.0\ncategory: cache\nports:\n  - 6379\nservice:\n  image: redis:6-alpine\n",
+	}
+	f.presetsParser.(*presets.FakeParser).MockTemplates["database"]["postgresql130.yml"] = "name: PostgreSQL 13.0\ncategory: database\nports:\n  - 5432\n  - 5433\nservice:\n  image: postgres:13-alpine\n"
+
+	cmd := NewAddCommand(f)
+	cmd.SetArgs([]string{"--list", "cache", "database"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing add command; error: %v", err)
+	}
+
+	table := f.table.(*shell.FakeTableWriter)
+
+	if !table.CalledRender {
+		t.Error("did not render the templates table")
+	}
+
+	expected := [][]interface{}{
+		{"cache", "redis60", "Redis 6.0", "6379"},
+		{"database", "mysql57", "MySQL 8.0", ""},
+		{"database", "mysql80", "MySQL 8.0", ""},
+		{"database", "postgresql130", "PostgreSQL 13.0", "5432, 5433"},
+	}
+
+	if !reflect.DeepEqual(table.Rows, expected) {
+		t.Errorf("expecting rows %v, got %v", expected, table.Rows)
+	}
+
+	if len(f.presetsParser.(*presets.FakeParser).CalledWriteFile) > 0 {
+		t.Error("unexpected file writing")
+	}
+}
+
+func TestListCategoryAddCommand(t *testing.T) {
+	f := newFakeKoolAdd()
+
+	cmd := NewAddCommand(f)
+	cmd.SetArgs([]string{"--list", "cache"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing add command; error: %v", err)
+	}
+
+	if rows := f.table.(*shell.FakeTableWriter).Rows; len(rows) != 0 {
+		t.Errorf("unexpected templates listed: %v", rows)
+	}
+}
+
+func TestMissingArgsAddCommand(t *testing.T) {
+	f := newFakeKoolAdd()
+
+	cmd := NewAddCommand(f)
+	cmd.SetArgs([]string{"database"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing add command; error: %v", err)
+	}
+
+	expected := "missing the SERVICE and TEMPLATE arguments; use --list to see the available templates"
+
+	if err := f.out.(*shell.FakeOutputWriter).Err; err == nil || err.Error() != expected {
+		t.Errorf("expecting error '%s', got '%v'", expected, err)
+	}
+}
@@ -2,6 +2,7 @@ package builder
 
 import (
 	"fmt"
+	"io"
 	"kool-dev/kool/cmd/shell"
 	"os"
 	"os/exec"
@@ -29,6 +30,18 @@ type Runner interface {
 	LookPath() error
 }
 
+// SignalRunner holds the method for running commands interactively
+// while forwarding them signals, like for stopping them.
+type SignalRunner interface {
+	InteractiveSignals(<-chan os.Signal, ...string) error
+}
+
+// StreamRunner holds the method for running commands
+// while handling their output as it comes.
+type StreamRunner interface {
+	Stream(io.Writer, ...string) error
+}
+
 // Parser holds available methods for parse commands
 type Parser interface {
 	Parse(string) error
@@ -38,6 +51,7 @@ type Parser interface {
 type Command interface {
 	Builder
 	Runner
+	StreamRunner
 	Parser
 }
 
@@ -88,6 +102,32 @@ func (c *DefaultCommand) Interactive(args ...string) (err error) {
 	return
 }
 
+// InteractiveSignals will send the command to an interactive execution,
+// forwarding it the signals sent through the given channel.
+func (c *DefaultCommand) InteractiveSignals(signals <-chan os.Signal, args ...string) (err error) {
+	var finalArgs []string = c.args
+
+	if len(args) > 0 {
+		finalArgs = append(finalArgs, args...)
+	}
+
+	err = shell.InteractiveSignals(signals, c.command, finalArgs...)
+	return
+}
+
+// Stream will send the command to shell execution, writing
+// its output to the given writer.
+func (c *DefaultCommand) Stream(w io.Writer, args ...string) (err error) {
+	var finalArgs []string = c.args
+
+	if len(args) > 0 {
+		finalArgs = append(finalArgs, args...)
+	}
+
+	err = shell.Stream(w, c.command, finalArgs...)
+	return
+}
+
 // Exec will send the command to shell execution.
 func (c *DefaultCommand) Exec(args ...string) (outStr string, err error) {
 	var finalArgs []string = c.args
@@ -131,6 +131,20 @@ func TestExecArgs(t *testing.T) {
 	}
 }
 
+func TestStream(t *testing.T) {
+	var output strings.Builder
+
+	cmd := NewCommand("echo", "x")
+
+	if err := cmd.Stream(&output, "y"); err != nil {
+		t.Fatal(err)
+	}
+
+	if out := strings.TrimSpace(output.String()); out != "x y" {
+		t.Errorf("Stream failed; expected output 'x y', got '%s'", out)
+	}
+}
+
 func TestInteractive(t *testing.T) {
 	r, w, err := os.Pipe()
 
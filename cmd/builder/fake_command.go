@@ -1,20 +1,32 @@
 package builder
 
+import (
+	"io"
+	"os"
+)
+
 // FakeCommand implements the Command interface and is used for mocking on testing scenarios
 type FakeCommand struct {
 	ArgsAppend         []string
 	ArgsInteractive    []string
 	ArgsExec           []string
+	ArgsStream         []string
 	CalledAppendArgs   bool
 	CalledString       bool
 	CalledLookPath     bool
 	CalledInteractive  bool
+	CalledSignals      bool
+	CalledStream       bool
 	CalledExec         bool
 	CalledParseCommand bool
 
 	MockExecOut       string
+	MockStreamOut     string
 	MockError         error
 	MockLookPathError error
+
+	// MockSignalsFn is run by InteractiveSignals, when set
+	MockSignalsFn func(<-chan os.Signal) error
 }
 
 // AppendArgs mocked function for testing
@@ -44,6 +56,35 @@ func (f *FakeCommand) Interactive(args ...string) (err error) {
 	return
 }
 
+// InteractiveSignals will send the command to an interactive execution
+// forwarding it the signals.
+func (f *FakeCommand) InteractiveSignals(signals <-chan os.Signal, args ...string) (err error) {
+	f.CalledInteractive = true
+	f.CalledSignals = true
+	f.ArgsInteractive = args
+
+	if f.MockSignalsFn != nil {
+		return f.MockSignalsFn(signals)
+	}
+
+	err = f.MockError
+	return
+}
+
+// Stream will send the command to shell execution, writing
+// the mocked stream output to the given writer.
+func (f *FakeCommand) Stream(w io.Writer, args ...string) (err error) {
+	f.CalledStream = true
+	f.ArgsStream = args
+
+	if _, err = io.WriteString(w, f.MockStreamOut); err != nil {
+		return
+	}
+
+	err = f.MockError
+	return
+}
+
 // Exec will send the command to shell execution.
 func (f *FakeCommand) Exec(args ...string) (outStr string, err error) {
 	f.CalledExec = true
@@ -2,6 +2,8 @@ package builder
 
 import (
 	"errors"
+	"os"
+	"strings"
 	"testing"
 )
 
@@ -32,6 +34,25 @@ func TestFakeCommand(t *testing.T) {
 		t.Errorf("failed to use mocked Interactive function on FakeCommand")
 	}
 
+	_ = f.InteractiveSignals(nil, "arg3")
+
+	if !f.CalledSignals || len(f.ArgsInteractive) != 1 || f.ArgsInteractive[0] != "arg3" {
+		t.Errorf("failed to use mocked InteractiveSignals function on FakeCommand")
+	}
+
+	f.MockSignalsFn = func(signals <-chan os.Signal) error { return errors.New("signals") }
+
+	if err := f.InteractiveSignals(nil); err == nil || err.Error() != "signals" {
+		t.Errorf("failed to use MockSignalsFn on FakeCommand; got %v", err)
+	}
+
+	var output strings.Builder
+	f.MockStreamOut = "streamed"
+
+	if err := f.Stream(&output, "arg1"); err != nil || !f.CalledStream || f.ArgsStream[0] != "arg1" || output.String() != "streamed" {
+		t.Errorf("failed to use mocked Stream function on FakeCommand")
+	}
+
 	_, _ = f.Exec("arg1", "arg2")
 
 	if !f.CalledExec || f.ArgsExec == nil || f.ArgsExec[0] != "arg1" || f.ArgsExec[1] != "arg2" {
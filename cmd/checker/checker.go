@@ -5,12 +5,16 @@ import "kool-dev/kool/cmd/builder"
 // Checker defines the check kool dependencies method
 type Checker interface {
 	Check() error
+	Versions() (string, string, error)
 }
 
 // DefaultChecker holds commands to be checked.
 type DefaultChecker struct {
 	dockerCmd        builder.Runner
 	dockerComposeCmd builder.Runner
+
+	dockerVersionCmd        builder.Runner
+	dockerComposeVersionCmd builder.Runner
 }
 
 // NewChecker initializes checker
@@ -20,7 +24,12 @@ func NewChecker() *DefaultChecker {
 	dockerInfoCmd = builder.NewCommand("docker", "info")
 	dockerComposePsCmd = builder.NewCommand("docker-compose", "ps")
 
-	return &DefaultChecker{dockerInfoCmd, dockerComposePsCmd}
+	return &DefaultChecker{
+		dockerInfoCmd,
+		dockerComposePsCmd,
+		builder.NewCommand("docker", "--version"),
+		builder.NewCommand("docker-compose", "--version"),
+	}
 }
 
 // Check checks kool dependencies
@@ -39,3 +48,13 @@ func (c *DefaultChecker) Check() error {
 
 	return nil
 }
+
+// Versions gets the installed docker and docker-compose versions
+func (c *DefaultChecker) Versions() (docker string, dockerCompose string, err error) {
+	if docker, err = c.dockerVersionCmd.Exec(); err != nil {
+		return
+	}
+
+	dockerCompose, err = c.dockerComposeVersionCmd.Exec()
+	return
+}
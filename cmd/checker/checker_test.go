@@ -20,7 +20,7 @@ func TestDockerNotInstalled(t *testing.T) {
 	dockerCmd := &builder.FakeCommand{MockLookPathError: errors.New("not installed")}
 	dockerComposeCmd := &builder.FakeCommand{}
 
-	c = &DefaultChecker{dockerCmd, dockerComposeCmd}
+	c = &DefaultChecker{dockerCmd, dockerComposeCmd, &builder.FakeCommand{}, &builder.FakeCommand{}}
 
 	err := c.Check()
 
@@ -40,7 +40,7 @@ func TestDockerComposeNotInstalled(t *testing.T) {
 	dockerCmd := &builder.FakeCommand{}
 	dockerComposeCmd := &builder.FakeCommand{MockLookPathError: errors.New("not installed")}
 
-	c = &DefaultChecker{dockerCmd, dockerComposeCmd}
+	c = &DefaultChecker{dockerCmd, dockerComposeCmd, &builder.FakeCommand{}, &builder.FakeCommand{}}
 
 	err := c.Check()
 
@@ -60,7 +60,7 @@ func TestDockerNotRunning(t *testing.T) {
 	dockerCmd := &builder.FakeCommand{MockError: errors.New("not running")}
 	dockerComposeCmd := &builder.FakeCommand{}
 
-	c = &DefaultChecker{dockerCmd, dockerComposeCmd}
+	c = &DefaultChecker{dockerCmd, dockerComposeCmd, &builder.FakeCommand{}, &builder.FakeCommand{}}
 
 	err := c.Check()
 
@@ -80,7 +80,7 @@ func TestCheckKoolDependencies(t *testing.T) {
 	dockerCmd := &builder.FakeCommand{}
 	dockerComposeCmd := &builder.FakeCommand{}
 
-	c = &DefaultChecker{dockerCmd, dockerComposeCmd}
+	c = &DefaultChecker{dockerCmd, dockerComposeCmd, &builder.FakeCommand{}, &builder.FakeCommand{}}
 
 	if err := c.Check(); err != nil {
 		t.Errorf("Expected no errors, got %v.", err)
@@ -99,3 +99,28 @@ func TestCheckKoolDependencies(t *testing.T) {
 		t.Error("did not call Exec for dockerCmd")
 	}
 }
+
+func TestCheckerVersions(t *testing.T) {
+	c := &DefaultChecker{
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{MockExecOut: "Docker version 20.10.2"},
+		&builder.FakeCommand{MockExecOut: "docker-compose version 1.27.4"},
+	}
+
+	docker, dockerCompose, err := c.Versions()
+
+	if err != nil {
+		t.Errorf("Expected no errors, got %v.", err)
+	}
+
+	if docker != "Docker version 20.10.2" || dockerCompose != "docker-compose version 1.27.4" {
+		t.Errorf("unexpected versions %s and %s", docker, dockerCompose)
+	}
+
+	c.dockerVersionCmd = &builder.FakeCommand{MockError: errors.New("not installed")}
+
+	if _, _, err = c.Versions(); err == nil || err.Error() != "not installed" {
+		t.Errorf("Expected error 'not installed', got %v", err)
+	}
+}
@@ -2,8 +2,13 @@ package checker
 
 // FakeChecker implements all fake behaviors for using checker in tests.
 type FakeChecker struct {
-	CalledCheck bool
-	MockError   error
+	CalledCheck    bool
+	CalledVersions bool
+	MockError      error
+
+	MockDockerVersion        string
+	MockDockerComposeVersion string
+	MockVersionsError        error
 }
 
 // Check implements fake Check behavior
@@ -12,3 +17,12 @@ func (f *FakeChecker) Check() (err error) {
 	err = f.MockError
 	return
 }
+
+// Versions implements fake Versions behavior
+func (f *FakeChecker) Versions() (docker string, dockerCompose string, err error) {
+	f.CalledVersions = true
+	docker = f.MockDockerVersion
+	dockerCompose = f.MockDockerComposeVersion
+	err = f.MockVersionsError
+	return
+}
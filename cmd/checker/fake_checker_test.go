@@ -13,6 +13,13 @@ func TestFakeChecker(t *testing.T) {
 	if !f.CalledCheck {
 		t.Error("failed to use mocked Check function on FakeChecker")
 	}
+
+	f.MockDockerVersion = "docker"
+	f.MockDockerComposeVersion = "docker-compose"
+
+	if docker, dockerCompose, _ := f.Versions(); !f.CalledVersions || docker != "docker" || dockerCompose != "docker-compose" {
+		t.Error("failed to use mocked Versions function on FakeChecker")
+	}
 }
 
 func TestFailedFakeChecker(t *testing.T) {
@@ -3,12 +3,19 @@ package compose
 // FakeParser implements all fake behaviors for using parser in tests.
 type FakeParser struct {
 	CalledLoad                              map[string]bool
+	CalledHasService                        map[string]bool
 	CalledSetService                        map[string]map[string]bool
 	CalledRemoveService, CalledRemoveVolume map[string]bool
-	CalledString                            bool
+	CalledString, CalledServices            bool
+	CalledMerge                             map[string]bool
 	MockLoadError                           error
+	MockHasService                          bool
+	MockServices                            []string
 	MockSetServiceError                     error
+	MockMergeAdded                          []string
+	MockMergeError                          error
 	MockStringError                         error
+	MockString                              string
 }
 
 // Load implements fake Load behavior
@@ -22,6 +29,22 @@ func (f *FakeParser) Load(compose string) (err error) {
 	return
 }
 
+// HasService implements fake HasService behavior
+func (f *FakeParser) HasService(service string) bool {
+	if f.CalledHasService == nil {
+		f.CalledHasService = make(map[string]bool)
+	}
+
+	f.CalledHasService[service] = true
+	return f.MockHasService
+}
+
+// Services implements fake Services behavior
+func (f *FakeParser) Services() []string {
+	f.CalledServices = true
+	return f.MockServices
+}
+
 // SetService implements fake SetService behavior
 func (f *FakeParser) SetService(service string, content string) (err error) {
 	if f.CalledSetService == nil {
@@ -55,9 +78,22 @@ func (f *FakeParser) RemoveVolume(volume string) {
 	f.CalledRemoveVolume[volume] = true
 }
 
+// Merge implements fake Merge behavior
+func (f *FakeParser) Merge(compose string) (added []string, err error) {
+	if f.CalledMerge == nil {
+		f.CalledMerge = make(map[string]bool)
+	}
+
+	f.CalledMerge[compose] = true
+	added = f.MockMergeAdded
+	err = f.MockMergeError
+	return
+}
+
 // String implements fake String behavior
 func (f *FakeParser) String() (content string, err error) {
 	f.CalledString = true
+	content = f.MockString
 	err = f.MockStringError
 	return
 }
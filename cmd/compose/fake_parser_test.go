@@ -23,6 +23,18 @@ func TestFakeParser(t *testing.T) {
 		t.Error("failed calling Load")
 	}
 
+	f.MockHasService = true
+
+	if !f.HasService("service") || !f.CalledHasService["service"] {
+		t.Error("failed calling HasService")
+	}
+
+	f.MockServices = []string{"service"}
+
+	if services := f.Services(); len(services) != 1 || !f.CalledServices {
+		t.Error("failed calling Services")
+	}
+
 	f.MockSetServiceError = errors.New("set service error")
 	err = f.SetService("service", "content")
 
@@ -48,6 +60,12 @@ func TestFakeParser(t *testing.T) {
 		t.Error("failed calling RemoveVolume")
 	}
 
+	f.MockMergeAdded = []string{"services.cache"}
+
+	if added, _ := f.Merge("compose"); !f.CalledMerge["compose"] || len(added) != 1 || added[0] != "services.cache" {
+		t.Error("failed calling Merge")
+	}
+
 	f.MockStringError = errors.New("string error")
 
 	_, err = f.String()
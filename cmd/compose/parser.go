@@ -1,8 +1,10 @@
 package compose
 
 import (
+	"bytes"
 	"fmt"
-	"gopkg.in/yaml.v2"
+
+	"gopkg.in/yaml.v3"
 )
 
 type yamlUnmarshalFnType func([]byte, interface{}) error
@@ -11,20 +13,24 @@ type yamlMarshalFnType func(interface{}) ([]byte, error)
 // Parser holds logic for handling docker-compose
 type Parser interface {
 	Load(string) error
+	HasService(string) bool
+	Services() []string
 	SetService(string, string) error
 	RemoveService(string)
 	RemoveVolume(string)
+	Merge(string) ([]string, error)
 	String() (string, error)
 }
 
-// DefaultParser holds data for docker-compose
+// DefaultParser holds data for docker-compose; it keeps the
+// YAML node tree so comments, anchors and quoting survive edits.
 type DefaultParser struct {
-	yamlData yaml.MapSlice
+	yamlData *yaml.Node
 }
 
 var (
 	yamlUnmarshalFn yamlUnmarshalFnType = yaml.Unmarshal
-	yamlMarshalFn   yamlMarshalFnType   = yaml.Marshal
+	yamlMarshalFn   yamlMarshalFnType   = marshalYaml
 )
 
 // NewParser creates new docker-compose parser
@@ -38,36 +44,106 @@ func (p *DefaultParser) Load(compose string) (err error) {
 	return
 }
 
+// HasService tells whether docker-compose has the service
+func (p *DefaultParser) HasService(serviceName string) bool {
+	return indexOfKey(mappingValue(p.root(), "services"), serviceName) != -1
+}
+
+// Services lists the docker-compose services in order
+func (p *DefaultParser) Services() (services []string) {
+	mapping := mappingValue(p.root(), "services")
+
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i < len(mapping.Content); i += 2 {
+		services = append(services, mapping.Content[i].Value)
+	}
+
+	return
+}
+
 // SetService set docker-compose service
 func (p *DefaultParser) SetService(serviceName string, serviceContent string) (err error) {
-	for sectionKey, section := range p.yamlData {
-		if section.Key == "services" {
-			for serviceKey, service := range section.Value.(yaml.MapSlice) {
-				if service.Key == serviceName {
-					var template yaml.MapSlice
-
-					if template, err = parseYaml(serviceContent); err != nil {
-						return
-					}
-
-					p.yamlData[sectionKey].Value.(yaml.MapSlice)[serviceKey].Value = template
-					return
-				}
-			}
-		}
+	var (
+		services = mappingValue(p.root(), "services")
+		index    = indexOfKey(services, serviceName)
+		template *yaml.Node
+	)
+
+	if index == -1 {
+		return fmt.Errorf("service %s not found", serviceName)
+	}
+
+	if template, err = parseYaml(serviceContent); err != nil {
+		return
 	}
 
-	return fmt.Errorf("service %s not found", serviceName)
+	services.Content[index+1] = documentContent(template)
+	return
 }
 
 // RemoveService remove a docker-compose service
 func (p *DefaultParser) RemoveService(service string) {
-	p.yamlData = removeSubItem(p.yamlData, "services", service)
+	removeSubItem(p.root(), "services", service)
 }
 
 // RemoveVolume remove a docker-compose volume
 func (p *DefaultParser) RemoveVolume(volume string) {
-	p.yamlData = removeSubItem(p.yamlData, "volumes", volume)
+	removeSubItem(p.root(), "volumes", volume)
+}
+
+// mergeableSections are the docker-compose sections whose items
+// are merged one by one; other sections are only added if missing.
+var mergeableSections = []string{"services", "volumes", "networks", "configs", "secrets"}
+
+// Merge adds to the loaded docker-compose the sections, services, volumes
+// and networks of the given docker-compose that are missing, keeping
+// the existing ones untouched and in order. It returns the added items.
+func (p *DefaultParser) Merge(compose string) (added []string, err error) {
+	var (
+		other *yaml.Node
+		root  = p.root()
+	)
+
+	if other, err = parseYaml(compose); err != nil {
+		return
+	}
+
+	otherRoot := documentContent(other)
+
+	for i := 0; i+1 < len(otherRoot.Content); i += 2 {
+		key, section := otherRoot.Content[i], otherRoot.Content[i+1]
+		index := indexOfKey(root, key.Value)
+
+		if index == -1 {
+			root.Content = append(root.Content, key, section)
+			added = append(added, key.Value)
+			continue
+		}
+
+		if !isMergeableSection(key.Value) || section.Kind != yaml.MappingNode {
+			continue
+		}
+
+		items := root.Content[index+1]
+
+		if items.Kind != yaml.MappingNode {
+			// an empty section (e.g "volumes:") holds a null scalar
+			items = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			root.Content[index+1] = items
+		}
+
+		for j := 0; j+1 < len(section.Content); j += 2 {
+			if indexOfKey(items, section.Content[j].Value) == -1 {
+				items.Content = append(items.Content, section.Content[j], section.Content[j+1])
+				added = append(added, fmt.Sprintf("%s.%s", key.Value, section.Content[j].Value))
+			}
+		}
+	}
+
+	return
 }
 
 // String returns docker-compose as string
@@ -82,32 +158,96 @@ func (p *DefaultParser) String() (content string, err error) {
 	return
 }
 
-func parseYaml(content string) (yaml.MapSlice, error) {
-	parsed := yaml.MapSlice{}
+// root returns the top level mapping of the loaded docker-compose,
+// turning an empty document into an empty mapping.
+func (p *DefaultParser) root() *yaml.Node {
+	if p.yamlData == nil {
+		p.yamlData = &yaml.Node{}
+	}
+
+	return documentContent(p.yamlData)
+}
+
+func parseYaml(content string) (*yaml.Node, error) {
+	parsed := &yaml.Node{}
 
-	if err := yamlUnmarshalFn([]byte(content), &parsed); err != nil {
+	if err := yamlUnmarshalFn([]byte(content), parsed); err != nil {
 		return nil, err
 	}
 
 	return parsed, nil
 }
 
-func removeSubItem(originalCompose yaml.MapSlice, item string, subItem string) (compose yaml.MapSlice) {
-	for _, section := range originalCompose {
-		if section.Key != item {
-			compose = append(compose, section)
-			continue
-		}
+func marshalYaml(in interface{}) (out []byte, err error) {
+	var buf bytes.Buffer
 
-		var sectionItems yaml.MapSlice
-		for _, sectionItem := range section.Value.(yaml.MapSlice) {
-			if sectionItem.Key != subItem {
-				sectionItems = append(sectionItems, sectionItem)
-			}
-		}
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err = encoder.Encode(in); err != nil {
+		return
+	}
 
-		compose = append(compose, yaml.MapItem{Key: item, Value: sectionItems})
+	if err = encoder.Close(); err != nil {
+		return
 	}
 
+	out = buf.Bytes()
 	return
 }
+
+// documentContent returns the mapping held by the document node,
+// creating it in case the document is empty.
+func documentContent(document *yaml.Node) *yaml.Node {
+	if document.Kind != yaml.DocumentNode {
+		document.Kind = yaml.DocumentNode
+	}
+
+	if len(document.Content) == 0 {
+		document.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+
+	return document.Content[0]
+}
+
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if index := indexOfKey(mapping, key); index != -1 {
+		return mapping.Content[index+1]
+	}
+
+	return nil
+}
+
+func removeSubItem(compose *yaml.Node, item string, subItem string) {
+	section := mappingValue(compose, item)
+
+	if index := indexOfKey(section, subItem); index != -1 {
+		section.Content = append(section.Content[:index], section.Content[index+2:]...)
+	}
+}
+
+// indexOfKey returns the index of the key node within the
+// mapping content, its value being the following node.
+func indexOfKey(mapping *yaml.Node, key string) int {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return -1
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func isMergeableSection(key string) bool {
+	for _, section := range mergeableSections {
+		if section == key {
+			return true
+		}
+	}
+
+	return false
+}
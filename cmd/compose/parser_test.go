@@ -6,9 +6,6 @@ import (
 	"reflect"
 	"strings"
 	"testing"
-	"unsafe"
-
-	"gopkg.in/yaml.v2"
 )
 
 const composeFile string = `version: "3.7"
@@ -16,11 +13,11 @@ services:
   service:
     image: service-image
     volumes:
-    - service:/app:delegated
+      - service:/app:delegated
   service2:
     image: service-image2
     volumes:
-    - service2:/app:delegated
+      - service2:/app:delegated
 volumes:
   service: null
   service2: null
@@ -31,7 +28,7 @@ services:
   service2:
     image: service-image2
     volumes:
-    - service2:/app:delegated
+      - service2:/app:delegated
 volumes:
   service: null
   service2: null
@@ -42,18 +39,18 @@ services:
   service:
     image: service-image
     volumes:
-    - service:/app:delegated
+      - service:/app:delegated
   service2:
     image: service-image2
     volumes:
-    - service2:/app:delegated
+      - service2:/app:delegated
 volumes:
   service2: null
 `
 
 const newComposeService string = `image: new-service-image
 volumes:
-- service:/app:delegated
+  - service:/app:delegated
 `
 
 const composeWithNewService string = `version: "3.7"
@@ -61,11 +58,11 @@ services:
   service:
     image: new-service-image
     volumes:
-    - service:/app:delegated
+      - service:/app:delegated
   service2:
     image: service-image2
     volumes:
-    - service2:/app:delegated
+      - service2:/app:delegated
 volumes:
   service: null
   service2: null
@@ -86,13 +83,8 @@ func TestLoadDefaultParser(t *testing.T) {
 		t.Errorf("unexpected error loading docker compose file; error: %v", err)
 	}
 
-	yamlData := getYamlData(p.(*DefaultParser))
-
-	parsed := yaml.MapSlice{}
-	_ = yaml.Unmarshal([]byte(composeFile), &parsed)
-
-	if !reflect.DeepEqual(yamlData, parsed) {
-		t.Error("failed loading docker compose file content")
+	if content, _ := p.String(); content != composeFile {
+		t.Errorf("failed loading docker compose file content; expected '%s', got '%s'", composeFile, content)
 	}
 }
 
@@ -119,13 +111,8 @@ func TestRemoveServiceDefaultParser(t *testing.T) {
 
 	p.RemoveService("service")
 
-	yamlData := getYamlData(p.(*DefaultParser))
-
-	parsed := yaml.MapSlice{}
-	_ = yaml.Unmarshal([]byte(composeWithoutService), &parsed)
-
-	if !reflect.DeepEqual(yamlData, parsed) {
-		t.Error("failed removing docker compose file service")
+	if content, _ := p.String(); content != composeWithoutService {
+		t.Errorf("failed removing docker compose file service; expected '%s', got '%s'", composeWithoutService, content)
 	}
 }
 
@@ -136,13 +123,38 @@ func TestRemoveVolumeDefaultParser(t *testing.T) {
 
 	p.RemoveVolume("service")
 
-	yamlData := getYamlData(p.(*DefaultParser))
+	if content, _ := p.String(); content != composeWithouServiceVolume {
+		t.Errorf("failed removing docker compose file volume; expected '%s', got '%s'", composeWithouServiceVolume, content)
+	}
+}
+
+func TestHasServiceDefaultParser(t *testing.T) {
+	p := NewParser()
+
+	_ = p.Load(composeFile)
+
+	if !p.HasService("service2") {
+		t.Error("expecting service2 to be found")
+	}
+
+	if p.HasService("service3") {
+		t.Error("unexpected service3 found")
+	}
+}
+
+func TestServicesDefaultParser(t *testing.T) {
+	p := NewParser()
+
+	_ = p.Load(composeFile)
 
-	parsed := yaml.MapSlice{}
-	_ = yaml.Unmarshal([]byte(composeWithouServiceVolume), &parsed)
+	if services := p.Services(); !reflect.DeepEqual(services, []string{"service", "service2"}) {
+		t.Errorf("unexpected services %v", services)
+	}
 
-	if !reflect.DeepEqual(yamlData, parsed) {
-		t.Error("failed removing docker compose file volume")
+	_ = p.Load("version: \"3.7\"\n")
+
+	if services := p.Services(); len(services) != 0 {
+		t.Errorf("unexpected services %v", services)
 	}
 }
 
@@ -155,13 +167,8 @@ func TestSetServiceDefaultParser(t *testing.T) {
 		t.Errorf("unexpected error setting docker compose service; error: %v", err)
 	}
 
-	yamlData := getYamlData(p.(*DefaultParser))
-
-	parsed := yaml.MapSlice{}
-	_ = yaml.Unmarshal([]byte(composeWithNewService), &parsed)
-
-	if !reflect.DeepEqual(yamlData, parsed) {
-		t.Error("failed setting docker compose file service")
+	if content, _ := p.String(); content != composeWithNewService {
+		t.Errorf("failed setting docker compose file service; expected '%s', got '%s'", composeWithNewService, content)
 	}
 }
 
@@ -223,8 +230,135 @@ func TestErrorStringDefaultParser(t *testing.T) {
 	}
 }
 
-func getYamlData(p *DefaultParser) yaml.MapSlice {
-	parserStruct := reflect.ValueOf(p).Elem()
-	reflectYamlData := parserStruct.FieldByName("yamlData")
-	return reflect.NewAt(reflectYamlData.Type(), unsafe.Pointer(reflectYamlData.UnsafeAddr())).Elem().Interface().(yaml.MapSlice)
+func TestMergeDefaultParser(t *testing.T) {
+	p := NewParser()
+
+	_ = p.Load(`version: "3.7"
+services:
+  app:
+    image: my-app
+    ports:
+      - "8080:80"
+volumes:
+networks:
+  legacy: null
+`)
+
+	added, err := p.Merge(`version: "3.8"
+services:
+  app:
+    image: kooldev/php:7.4-nginx
+  cache:
+    image: redis:6-alpine
+volumes:
+  cache: null
+networks:
+  kool_local: null
+  kool_global:
+    external: true
+x-kool:
+  preset: laravel
+`)
+
+	if err != nil {
+		t.Fatalf("unexpected error merging compose: %v", err)
+	}
+
+	expectedAdded := []string{"services.cache", "volumes.cache", "networks.kool_local", "networks.kool_global", "x-kool"}
+
+	if !reflect.DeepEqual(added, expectedAdded) {
+		t.Errorf("expected added %v; got %v", expectedAdded, added)
+	}
+
+	merged, _ := p.String()
+
+	expected := `version: "3.7"
+services:
+  app:
+    image: my-app
+    ports:
+      - "8080:80"
+  cache:
+    image: redis:6-alpine
+volumes:
+  cache: null
+networks:
+  legacy: null
+  kool_local: null
+  kool_global:
+    external: true
+x-kool:
+  preset: laravel
+`
+
+	if merged != expected {
+		t.Errorf("expected merged compose:\n%s\ngot:\n%s", expected, merged)
+	}
+}
+
+func TestErrorMergeDefaultParser(t *testing.T) {
+	p := NewParser()
+	_ = p.Load(composeFile)
+
+	if _, err := p.Merge("services: ["); err == nil {
+		t.Error("expecting error merging invalid compose, got none")
+	}
+}
+
+func TestPreserveCommentsDefaultParser(t *testing.T) {
+	p := NewParser()
+
+	_ = p.Load(`version: "3.7"
+x-env: &env
+  APP_ENV: "local"
+services:
+  app:
+    image: app # possibly change to: app:latest
+    environment: *env
+    volumes:
+      - .:/app:delegated
+    #  - $HOME/.ssh:/home/kool/.ssh:delegated
+  # database comment
+  database:
+    image: mysql:8.0
+  cache:
+    image: redis
+volumes:
+  database:
+  cache:
+`)
+
+	p.RemoveService("cache")
+	p.RemoveVolume("cache")
+
+	if err := p.SetService("database", "image: postgres:13-alpine # possibly change to: postgres:12\n"); err != nil {
+		t.Fatalf("unexpected error setting docker compose service; error: %v", err)
+	}
+
+	content, err := p.String()
+
+	if err != nil {
+		t.Fatalf("unexpected error getting docker compose file content; error: %v", err)
+	}
+
+	expected := `version: "3.7"
+x-env: &env
+  APP_ENV: "local"
+services:
+  app:
+    image: app # possibly change to: app:latest
+    environment: *env
+    volumes:
+      - .:/app:delegated
+      #  - $HOME/.ssh:/home/kool/.ssh:delegated
+  # database comment
+  database:
+    image: postgres:13-alpine # possibly change to: postgres:12
+volumes:
+  database:
+`
+
+	if content != expected {
+		t.Errorf("expected docker compose:\n%s\ngot:\n%s", expected, content)
+	}
 }
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"kool-dev/kool/cmd/compose"
+	"kool-dev/kool/environment"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// projectComposeFiles gets the project docker-compose files, as set
+// by COMPOSE_FILE or the default docker-compose.yml and its override.
+func projectComposeFiles(envStorage environment.EnvStorage) []string {
+	if files := envStorage.Get("COMPOSE_FILE"); files != "" {
+		separator := envStorage.Get("COMPOSE_PATH_SEPARATOR")
+
+		if separator == "" {
+			separator = string(os.PathListSeparator)
+		}
+
+		return strings.Split(files, separator)
+	}
+
+	return []string{"docker-compose.yml", "docker-compose.override.yml"}
+}
+
+// projectServices lists the services of the project docker-compose
+// files without calling Docker; missing or broken files are skipped.
+func projectServices(envStorage environment.EnvStorage, parser compose.Parser) (services []string) {
+	for _, file := range projectComposeFiles(envStorage) {
+		content, err := ioutil.ReadFile(file)
+
+		if err != nil || parser.Load(string(content)) != nil {
+			continue
+		}
+
+		for _, service := range parser.Services() {
+			if !containsString(services, service) {
+				services = append(services, service)
+			}
+		}
+	}
+
+	return
+}
+
+// compListServices lists the project services starting with
+// toComplete that are not among the given arguments yet.
+func compListServices(toComplete string, args []string, envStorage environment.EnvStorage, parser compose.Parser) (services []string) {
+	for _, service := range projectServices(envStorage, parser) {
+		if strings.HasPrefix(service, toComplete) && !containsString(args, service) {
+			services = append(services, service)
+		}
+	}
+
+	return
+}
+
+// completeServices completes any number of service arguments
+func completeServices(envStorage environment.EnvStorage, parser compose.Parser) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return compListServices(toComplete, args, envStorage, parser), cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"kool-dev/kool/cmd/compose"
+	"kool-dev/kool/environment"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProjectComposeFiles(t *testing.T) {
+	envStorage := environment.NewFakeEnvStorage()
+
+	if files := projectComposeFiles(envStorage); !reflect.DeepEqual(files, []string{"docker-compose.yml", "docker-compose.override.yml"}) {
+		t.Errorf("unexpected default compose files %v", files)
+	}
+
+	envStorage.Set("COMPOSE_FILE", "base.yml;dev.yml")
+	envStorage.Set("COMPOSE_PATH_SEPARATOR", ";")
+
+	if files := projectComposeFiles(envStorage); !reflect.DeepEqual(files, []string{"base.yml", "dev.yml"}) {
+		t.Errorf("unexpected COMPOSE_FILE compose files %v", files)
+	}
+}
+
+func TestCompListServices(t *testing.T) {
+	wd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(wd) }()
+
+	tmp := t.TempDir()
+	_ = ioutil.WriteFile(filepath.Join(tmp, "docker-compose.yml"), []byte("services:\n  app:\n    image: app\n  worker:\n    image: app\n"), os.ModePerm)
+	_ = ioutil.WriteFile(filepath.Join(tmp, "docker-compose.override.yml"), []byte("services:\n  app:\n    ports: [80]\n  web:\n    image: nginx\n"), os.ModePerm)
+	_ = os.Chdir(tmp)
+
+	envStorage := environment.NewFakeEnvStorage()
+
+	if services := compListServices("", nil, envStorage, compose.NewParser()); !reflect.DeepEqual(services, []string{"app", "worker", "web"}) {
+		t.Errorf("unexpected services %v", services)
+	}
+
+	if services := compListServices("w", []string{"worker"}, envStorage, compose.NewParser()); !reflect.DeepEqual(services, []string{"web"}) {
+		t.Errorf("unexpected services %v", services)
+	}
+
+	cmd := NewStopCommand(newFakeKoolStop())
+	cmd.ValidArgsFunction = completeServices(envStorage, compose.NewParser())
+
+	if services, _ := cmd.ValidArgsFunction(cmd, []string{"app"}, ""); !reflect.DeepEqual(services, []string{"worker", "web"}) {
+		t.Errorf("unexpected completion %v", services)
+	}
+}
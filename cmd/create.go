@@ -2,19 +2,32 @@ package cmd
 
 import (
 	"fmt"
+	"io/ioutil"
 	"kool-dev/kool/cmd/builder"
 	"kool-dev/kool/cmd/presets"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 )
 
+// KoolCreateFlags holds the flags for the create command
+type KoolCreateFlags struct {
+	NoSetup bool
+}
+
 // KoolCreate holds handlers and functions to implement the preset command logic
 type KoolCreate struct {
 	DefaultKoolService
+	Flags         *KoolCreateFlags
 	parser        presets.Parser
 	createCommand builder.Command
+	cloneCommand  builder.Command
 	KoolPreset
+
+	setupCommand builder.Command
+	readiness    *serviceReadiness
+	down         builder.Command
 }
 
 func init() {
@@ -30,57 +43,188 @@ func init() {
 func NewKoolCreate() *KoolCreate {
 	return &KoolCreate{
 		*newDefaultKoolService(),
+		&KoolCreateFlags{false},
 		presets.NewParser(),
 		&builder.DefaultCommand{},
+		builder.NewCommand("git", "clone", "--depth", "1"),
 		*NewKoolPreset(),
+		&builder.DefaultCommand{},
+		newServiceReadiness(),
+		builder.NewCommand("docker-compose", "down", "--volumes", "--remove-orphans"),
 	}
 }
 
 // Execute runs the create logic with incoming arguments.
 func (c *KoolCreate) Execute(originalArgs []string) (err error) {
-	preset := originalArgs[0]
-	dir := originalArgs[1]
+	var (
+		preset   = originalArgs[0]
+		dir      = originalArgs[1]
+		manifest *presets.Manifest
+		wd       string
+		created  bool
+		template = isTemplateSource(preset)
+	)
+
+	if !template {
+		if err = c.parseCreateCommand(preset); err != nil {
+			return
+		}
+	}
 
-	c.parser.LoadPresets(presets.GetAll())
+	if c.KoolPreset.Flags.Answers != "" {
+		// the answers file path must hold after moving to the project folder
+		if c.KoolPreset.Flags.Answers, err = filepath.Abs(c.KoolPreset.Flags.Answers); err != nil {
+			return
+		}
+	}
 
-	if !c.parser.Exists(preset) {
-		err = fmt.Errorf("Unknown preset %s", preset)
+	if wd, err = os.Getwd(); err != nil {
+		return
+	}
+
+	if dir, err = filepath.Abs(dir); err != nil {
 		return
 	}
 
-	createCmd, err := c.parser.GetCreateCommand(preset)
+	// only a project folder created by kool create is removed on failure
+	created = isMissingOrEmptyDir(dir)
+
+	defer func() {
+		if err != nil && created {
+			c.rollback(wd, dir)
+		}
+	}()
+
+	if template {
+		err = c.fetchTemplate(preset, dir)
+	} else {
+		err = c.createCommand.Interactive(dir)
+	}
 
 	if err != nil {
 		return
 	}
 
-	err = c.createCommand.Parse(createCmd)
+	if err = os.Chdir(dir); err != nil {
+		return
+	}
+
+	if template {
+		manifest, err = c.applyTemplate(preset)
+	} else {
+		manifest, err = c.KoolPreset.initialize([]string{preset})
+	}
 
 	if err != nil {
 		return
 	}
 
-	err = c.createCommand.Interactive(dir)
+	if c.Flags.NoSetup || len(manifest.Setup) == 0 {
+		c.KoolPreset.printPostInstall(manifest)
+		return
+	}
 
-	if err != nil {
+	err = c.setup(manifest.Setup)
+	return
+}
+
+// parseCreateCommand gets the preset create command ready to run
+func (c *KoolCreate) parseCreateCommand(preset string) (err error) {
+	var createCmd string
+
+	if err = loadAllPresets(c, c.parser, c.envStorage); err != nil {
+		return
+	}
+
+	if !c.parser.Exists(preset) {
+		err = fmt.Errorf("Unknown preset %s", preset)
+		return
+	}
+
+	if createCmd, err = c.parser.GetCreateCommand(preset); err != nil {
 		return
 	}
 
-	_ = os.Chdir(dir)
+	err = c.createCommand.Parse(createCmd)
+	return
+}
+
+// setup runs the preset setup steps with progress; steps waiting
+// for services the project doesn't have are skipped.
+func (c *KoolCreate) setup(steps []*presets.SetupStep) (err error) {
+	var compose string
+
+	if compose, err = c.KoolPreset.presetsParser.ReadFile("docker-compose.yml"); err == nil {
+		err = c.KoolPreset.composeParser.Load(compose)
+	}
+
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
 
-	err = c.KoolPreset.Execute([]string{preset})
+	for _, step := range steps {
+		if step.Wait != "" && (compose == "" || !c.KoolPreset.composeParser.HasService(step.Wait)) {
+			continue
+		}
+
+		task := NewKoolTask(step.Name, &setupStep{
+			c.DefaultKoolService,
+			step,
+			c.setupCommand,
+			c.readiness,
+		})
+
+		if err = task.Run(nil); err != nil {
+			err = fmt.Errorf("setup step '%s' failed: %v", step.Name, err)
+			return
+		}
+	}
 
+	c.Success("Project set up and ready!")
 	return
 }
 
+// rollback removes the project folder of a failed creation, along
+// with the containers and volumes the setup steps may have created
+func (c *KoolCreate) rollback(wd string, dir string) {
+	if os.Chdir(dir) == nil {
+		if _, err := c.KoolPreset.presetsParser.ReadFile("docker-compose.yml"); err == nil {
+			if output, err := c.down.Exec(); err != nil {
+				c.Warning("Failed removing the project containers and volumes: ", err, " ", output)
+			}
+		}
+	}
+
+	_ = os.Chdir(wd)
+
+	c.Warning("Removing ", dir, " after the failed project creation")
+
+	if err := os.RemoveAll(dir); err != nil {
+		c.Warning("Failed removing ", dir, ": ", err)
+	}
+}
+
+func isMissingOrEmptyDir(dir string) bool {
+	files, err := ioutil.ReadDir(dir)
+	return os.IsNotExist(err) || (err == nil && len(files) == 0)
+}
+
 // NewCreateCommand initializes new kool create command
 func NewCreateCommand(create *KoolCreate) (createCmd *cobra.Command) {
 	createCmd = &cobra.Command{
-		Use:   "create [preset] [project]",
-		Short: "Create a new project using preset",
-		Args:  cobra.ExactArgs(2),
-		Run:   DefaultCommandRunFunction(create),
+		Use:   "create [preset|template] [project]",
+		Short: "Create a new project using a preset or a template repository",
+		Long: `Create a new project using a preset, or a template repository given
+by its git URL (like github.com/org/template) or local folder path.
+
+Template repositories are copied without their git history; their files
+ending with .tmpl are rendered with the variables of the preset.yml
+manifest the repository may hold.`,
+		Args: cobra.ExactArgs(2),
+		Run:  DefaultCommandRunFunction(create),
 	}
 
+	createCmd.Flags().BoolVarP(&create.Flags.NoSetup, "no-setup", "", false, "Skip the preset setup steps, only initializing the project files")
+	addPresetAnswersFlags(createCmd.Flags(), create.KoolPreset.Flags)
 	return
 }
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/cmd/presets"
+	"time"
+)
+
+// setupStepInterval is the time between the attempts of a setup step
+var setupStepInterval = time.Second
+
+// setupWaitRetries is the default number of attempts waiting for a service
+const setupWaitRetries = 60
+
+// setupStep runs a preset setup step as the service of a KoolTask
+type setupStep struct {
+	DefaultKoolService
+	step *presets.SetupStep
+
+	command   builder.Command
+	readiness *serviceReadiness
+}
+
+// Execute runs the setup step, retrying it in case of failure; the
+// command output is streamed as it runs.
+func (s *setupStep) Execute(args []string) (err error) {
+	var retries = s.step.Retries
+
+	if s.step.Run != "" {
+		if err = s.command.Parse(s.step.Run); err != nil {
+			return
+		}
+	} else if retries == 0 {
+		retries = setupWaitRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		if s.step.Run != "" {
+			err = s.command.Stream(s.GetWriter())
+		} else {
+			err = s.readiness.check(s.step.Wait)
+		}
+
+		if err == nil || attempt >= retries {
+			break
+		}
+
+		time.Sleep(setupStepInterval)
+	}
+
+	return
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/cmd/presets"
+	"kool-dev/kool/cmd/shell"
+	"testing"
+)
+
+func newFakeSetupStep(step *presets.SetupStep) *setupStep {
+	s := &setupStep{
+		*newFakeKoolService(),
+		step,
+		&builder.FakeCommand{},
+		newFakeServiceReadiness(),
+	}
+
+	s.out.(*shell.FakeOutputWriter).MockWriter = new(bytes.Buffer)
+	return s
+}
+
+func TestRunSetupStep(t *testing.T) {
+	s := newFakeSetupStep(&presets.SetupStep{Run: "kool start"})
+	s.command.(*builder.FakeCommand).MockStreamOut = "started\n"
+
+	if err := s.Execute(nil); err != nil {
+		t.Errorf("unexpected error running setup step: %v", err)
+	}
+
+	if !s.command.(*builder.FakeCommand).CalledParseCommand || !s.command.(*builder.FakeCommand).CalledStream {
+		t.Error("did not run the setup step command")
+	}
+
+	if out := s.out.(*shell.FakeOutputWriter).MockWriter.(*bytes.Buffer).String(); out != "started\n" {
+		t.Errorf("expecting the command output to be streamed, got %q", out)
+	}
+}
+
+func TestWaitSetupStep(t *testing.T) {
+	setupStepInterval = 0
+
+	s := newFakeSetupStep(&presets.SetupStep{Wait: "database", Retries: 2})
+	s.readiness.id.(*builder.FakeCommand).MockExecOut = "id"
+	s.readiness.status.(*builder.FakeCommand).MockExecOut = "starting"
+
+	expected := "service database is not ready (starting)"
+
+	if err := s.Execute(nil); err == nil || err.Error() != expected {
+		t.Errorf("expecting error '%s', got '%v'", expected, err)
+	}
+
+	s.readiness.status.(*builder.FakeCommand).MockExecOut = "healthy"
+
+	if err := s.Execute(nil); err != nil {
+		t.Errorf("unexpected error waiting for healthy service: %v", err)
+	}
+
+	if s.command.(*builder.FakeCommand).CalledStream {
+		t.Error("unexpected command running for a wait step")
+	}
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"kool-dev/kool/cmd/presets"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// templateFileSuffix marks the template repository files rendered
+// with the preset variables; the suffix is dropped once rendered.
+const templateFileSuffix = ".tmpl"
+
+// isTemplateSource tells whether the create argument is a template
+// repository (a git URL or a local folder) rather than a preset name.
+func isTemplateSource(source string) bool {
+	return strings.HasPrefix(source, ".") || strings.ContainsAny(source, `/\:`)
+}
+
+// templateGitURL gets the URL to clone the template repository from;
+// sources without scheme, like github.com/org/template, use https.
+func templateGitURL(source string) string {
+	if strings.Contains(source, "://") || strings.HasPrefix(source, "git@") {
+		return source
+	}
+
+	return "https://" + source
+}
+
+// fetchTemplate copies or clones the template repository
+// into the project folder, stripping its git history.
+func (c *KoolCreate) fetchTemplate(source string, dir string) (err error) {
+	if info, statErr := os.Stat(source); statErr == nil && info.IsDir() {
+		err = copyTemplateFolder(source, dir)
+	} else {
+		err = c.cloneCommand.Interactive(templateGitURL(source), dir)
+	}
+
+	if err != nil {
+		err = fmt.Errorf("failed fetching template %s: %v", source, err)
+		return
+	}
+
+	err = os.RemoveAll(filepath.Join(dir, ".git"))
+	return
+}
+
+// applyTemplate applies the preset manifest embedded in the template
+// repository, if any, to the project on the working directory: its
+// services and variables are asked and the templated files rendered.
+func (c *KoolCreate) applyTemplate(source string) (manifest *presets.Manifest, err error) {
+	var (
+		p                      = &c.KoolPreset
+		content                string
+		answers                *presets.Answers
+		services, sets, values map[string]string
+	)
+
+	if content, err = p.presetsParser.ReadFile(presets.PresetManifestFile); err != nil && !os.IsNotExist(err) {
+		return
+	}
+
+	if manifest, err = presets.ParseManifest(content); err != nil {
+		return
+	}
+
+	if err = loadAllPresets(p, p.presetsParser, p.envStorage); err != nil {
+		return
+	}
+
+	if answers, err = p.readAnswers(); err != nil {
+		return
+	}
+
+	if services, err = p.presetServices(source, manifest, answers.Services); err != nil {
+		return
+	}
+
+	if sets, err = p.presetSets(answers); err != nil {
+		return
+	}
+
+	if values, err = p.presetVariables(source, manifest, sets); err != nil {
+		return
+	}
+
+	p.Println("Template", source, "is initializing!")
+
+	if err = renderTemplateFiles(values); err != nil {
+		return
+	}
+
+	if len(services) > 0 {
+		if content, err = p.presetsParser.ReadFile("docker-compose.yml"); err != nil {
+			err = fmt.Errorf("template %s has service questions but no docker-compose.yml", source)
+			return
+		}
+
+		if content, err = p.composeServices(manifest, content, services); err != nil {
+			return
+		}
+
+		if err = p.writeFile("docker-compose.yml", content); err != nil {
+			return
+		}
+	}
+
+	if err = os.Remove(presets.PresetManifestFile); os.IsNotExist(err) {
+		err = nil
+	}
+
+	if err != nil {
+		return
+	}
+
+	p.Success("Template ", source, " initialized!")
+	return
+}
+
+// renderTemplateFiles renders the templated files of the working
+// directory with the variables values, dropping their suffix.
+func renderTemplateFiles(values map[string]string) error {
+	return filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+		var content []byte
+
+		if err != nil || !info.Mode().IsRegular() || !strings.HasSuffix(path, templateFileSuffix) {
+			return err
+		}
+
+		if content, err = ioutil.ReadFile(path); err != nil {
+			return err
+		}
+
+		rendered, err := presets.RenderFile(path, string(content), values)
+
+		if err != nil {
+			return fmt.Errorf("failed rendering template file %s: %v", path, err)
+		}
+
+		if err = ioutil.WriteFile(strings.TrimSuffix(path, templateFileSuffix), []byte(rendered), info.Mode()); err != nil {
+			return err
+		}
+
+		return os.Remove(path)
+	})
+}
+
+// copyTemplateFolder copies the local template folder into the
+// project folder, leaving out its git folder and the project folder
+// itself when created within the template.
+func copyTemplateFolder(source string, dir string) (err error) {
+	if source, err = filepath.Abs(source); err != nil {
+		return
+	}
+
+	err = filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+		var relPath string
+
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && path == dir {
+			return filepath.SkipDir
+		}
+
+		if relPath, err = filepath.Rel(source, path); err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, relPath)
+
+		switch {
+		case info.IsDir() && info.Name() == ".git":
+			return filepath.SkipDir
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+
+			if err != nil {
+				return err
+			}
+
+			return os.Symlink(link, target)
+		default:
+			return copyTemplateFile(path, target, info.Mode())
+		}
+	})
+	return
+}
+
+func copyTemplateFile(path string, target string, mode os.FileMode) (err error) {
+	var source, dest *os.File
+
+	if source, err = os.Open(path); err != nil {
+		return
+	}
+
+	defer source.Close()
+
+	if dest, err = os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode); err != nil {
+		return
+	}
+
+	if _, err = io.Copy(dest, source); err != nil {
+		dest.Close()
+		return
+	}
+
+	err = dest.Close()
+	return
+}
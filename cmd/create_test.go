@@ -2,19 +2,83 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
+	"io/ioutil"
 	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/cmd/compose"
 	"kool-dev/kool/cmd/presets"
 	"kool-dev/kool/cmd/shell"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
 func newFakeKoolCreate() *KoolCreate {
 	return &KoolCreate{
 		*newFakeKoolService(),
+		&KoolCreateFlags{false},
 		&presets.FakeParser{},
 		&builder.FakeCommand{},
+		&builder.FakeCommand{},
 		*newFakeKoolPreset(),
+		&builder.FakeCommand{},
+		newFakeServiceReadiness(),
+		&builder.FakeCommand{},
+	}
+}
+
+// newCreateTestDir makes an empty folder for the project being created,
+// returning it along with a function restoring the working directory.
+func newCreateTestDir(t *testing.T) (dir string, restore func()) {
+	var (
+		tmp, wd string
+		err     error
+	)
+
+	if wd, err = os.Getwd(); err != nil {
+		t.Fatal(err)
+	}
+
+	if tmp, err = ioutil.TempDir("", "kool-create"); err != nil {
+		t.Fatal(err)
+	}
+
+	dir = filepath.Join(tmp, "my-app")
+
+	if err = os.Mkdir(dir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	restore = func() {
+		_ = os.Chdir(wd)
+		_ = os.RemoveAll(tmp)
+	}
+	return
+}
+
+func newFakeKoolCreateWithSetup() *KoolCreate {
+	f := newFakeKoolCreate()
+	f.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+	f.out.(*shell.FakeOutputWriter).MockWriter = ioutil.Discard
+
+	f.parser.(*presets.FakeParser).MockExists = true
+	f.KoolPreset.presetsParser.(*presets.FakeParser).MockExists = true
+	f.parser.(*presets.FakeParser).MockCreateCommand = "kool docker create command"
+
+	f.KoolPreset.presetsParser.(*presets.FakeParser).MockManifest = map[string]*presets.Manifest{
+		"laravel": {
+			Language: "php",
+			Setup: []*presets.SetupStep{
+				{Name: "Starting", Run: "kool start"},
+				{Name: "Waiting database", Wait: "database"},
+				{Name: "Waiting cache", Wait: "cache", Retries: 1},
+			},
+		},
+	}
+	f.KoolPreset.presetsParser.(*presets.FakeParser).MockFiles = map[string]string{
+		"docker-compose.yml": "services:\n  database:\n    image: mysql\n",
 	}
+	return f
 }
 
 func TestNewKoolCreate(t *testing.T) {
@@ -39,6 +103,14 @@ func TestNewKoolCreate(t *testing.T) {
 	if _, ok := k.parser.(*presets.DefaultParser); !ok {
 		t.Errorf("unexpected presets.Parser on default KoolCreate instance")
 	}
+
+	if _, ok := k.setupCommand.(*builder.DefaultCommand); !ok {
+		t.Errorf("unexpected builder.Command on default KoolCreate instance")
+	}
+
+	if k.Flags == nil || k.Flags.NoSetup {
+		t.Errorf("unexpected default flags on KoolCreate instance")
+	}
 }
 
 func TestNewKoolCreateCommand(t *testing.T) {
@@ -48,8 +120,11 @@ func TestNewKoolCreateCommand(t *testing.T) {
 	f.KoolPreset.presetsParser.(*presets.FakeParser).MockExists = true
 	f.parser.(*presets.FakeParser).MockCreateCommand = "kool docker create command"
 
+	dir, restore := newCreateTestDir(t)
+	defer restore()
+
 	cmd := NewCreateCommand(f)
-	cmd.SetArgs([]string{"laravel", "my-app"})
+	cmd.SetArgs([]string{"laravel", dir})
 
 	if err := cmd.Execute(); err != nil {
 		t.Errorf("unexpected error executing create command; error: %v", err)
@@ -124,3 +199,306 @@ func TestNoArgsNewCreateCommand(t *testing.T) {
 		t.Error("expecting no arguments error executing create command")
 	}
 }
+
+func TestAnswersCreateCommand(t *testing.T) {
+	f := newFakeKoolCreate()
+
+	f.parser.(*presets.FakeParser).MockExists = true
+	f.KoolPreset.presetsParser.(*presets.FakeParser).MockExists = true
+	f.parser.(*presets.FakeParser).MockCreateCommand = "kool docker create command"
+
+	f.KoolPreset.presetsParser.(*presets.FakeParser).MockManifest = newDatabaseQuestionManifest()
+
+	answersPath, _ := filepath.Abs("answers.yml")
+	f.KoolPreset.presetsParser.(*presets.FakeParser).MockFiles = map[string]string{
+		answersPath: "variables: {}\n",
+	}
+
+	dir, restore := newCreateTestDir(t)
+	defer restore()
+
+	cmd := NewCreateCommand(f)
+	cmd.SetArgs([]string{"laravel", dir, "--answers", "answers.yml", "--database", "mysql80"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing create command; error: %v", err)
+	}
+
+	if f.KoolPreset.Flags.Answers != answersPath {
+		t.Errorf("expecting answers file path to be made absolute, got %s", f.KoolPreset.Flags.Answers)
+	}
+
+	if f.KoolPreset.Flags.Services["database"] != "mysql80" {
+		t.Errorf("expecting database service flag, got %v", f.KoolPreset.Flags.Services)
+	}
+
+	if f.out.(*shell.FakeOutputWriter).CalledError {
+		t.Errorf("unexpected error: %v", f.out.(*shell.FakeOutputWriter).Err)
+	}
+}
+
+func TestSetupCreateCommand(t *testing.T) {
+	setupStepInterval = 0
+	f := newFakeKoolCreateWithSetup()
+	f.readiness.id.(*builder.FakeCommand).MockExecOut = "container-id\n"
+	f.readiness.status.(*builder.FakeCommand).MockExecOut = "healthy\n"
+	f.KoolPreset.composeParser.(*compose.FakeParser).MockHasService = true
+
+	dir, restore := newCreateTestDir(t)
+	defer restore()
+
+	cmd := NewCreateCommand(f)
+	cmd.SetArgs([]string{"laravel", dir})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing create command; error: %v", err)
+	}
+
+	if f.out.(*shell.FakeOutputWriter).CalledError {
+		t.Errorf("unexpected error: %v", f.out.(*shell.FakeOutputWriter).Err)
+	}
+
+	if !f.setupCommand.(*builder.FakeCommand).CalledStream {
+		t.Error("did not run the setup command step")
+	}
+
+	if called := f.KoolPreset.composeParser.(*compose.FakeParser).CalledHasService; !called["database"] || !called["cache"] {
+		t.Errorf("did not check the services being waited for; checked: %v", called)
+	}
+
+	if args := f.readiness.id.(*builder.FakeCommand).ArgsExec; len(args) != 1 || args[0] != "cache" {
+		t.Errorf("expecting to wait for the cache service, got %v", args)
+	}
+
+	if args := f.readiness.status.(*builder.FakeCommand).ArgsExec; len(args) != 1 || args[0] != "container-id" {
+		t.Errorf("expecting to check the service container status, got %v", args)
+	}
+
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("unexpected project folder removal: %v", err)
+	}
+}
+
+func TestMissingServiceSetupCreateCommand(t *testing.T) {
+	f := newFakeKoolCreateWithSetup()
+
+	dir, restore := newCreateTestDir(t)
+	defer restore()
+
+	cmd := NewCreateCommand(f)
+	cmd.SetArgs([]string{"laravel", dir})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing create command; error: %v", err)
+	}
+
+	if f.out.(*shell.FakeOutputWriter).CalledError {
+		t.Errorf("unexpected error: %v", f.out.(*shell.FakeOutputWriter).Err)
+	}
+
+	if !f.setupCommand.(*builder.FakeCommand).CalledStream {
+		t.Error("did not run the setup command step")
+	}
+
+	if f.readiness.id.(*builder.FakeCommand).CalledExec {
+		t.Error("unexpected waiting for services missing on docker-compose.yml")
+	}
+}
+
+func TestNoSetupCreateCommand(t *testing.T) {
+	f := newFakeKoolCreateWithSetup()
+
+	dir, restore := newCreateTestDir(t)
+	defer restore()
+
+	cmd := NewCreateCommand(f)
+	cmd.SetArgs([]string{"laravel", dir, "--no-setup"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing create command; error: %v", err)
+	}
+
+	if f.out.(*shell.FakeOutputWriter).CalledError {
+		t.Errorf("unexpected error: %v", f.out.(*shell.FakeOutputWriter).Err)
+	}
+
+	if f.setupCommand.(*builder.FakeCommand).CalledStream || f.readiness.id.(*builder.FakeCommand).CalledExec {
+		t.Error("unexpected setup steps running with --no-setup")
+	}
+}
+
+func TestRollbackCreateCommand(t *testing.T) {
+	setupStepInterval = 0
+	f := newFakeKoolCreateWithSetup()
+	f.setupCommand.(*builder.FakeCommand).MockError = errors.New("start error")
+	f.KoolPreset.presetsParser.(*presets.FakeParser).MockFiles = map[string]string{"docker-compose.yml": "services: {}"}
+
+	dir, restore := newCreateTestDir(t)
+	defer restore()
+
+	cmd := NewCreateCommand(f)
+	cmd.SetArgs([]string{"laravel", dir})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing create command; error: %v", err)
+	}
+
+	expected := "setup step 'Starting' failed: start error"
+
+	if err := f.out.(*shell.FakeOutputWriter).Err; err == nil || err.Error() != expected {
+		t.Errorf("expecting error '%s', got '%v'", expected, err)
+	}
+
+	if !f.out.(*shell.FakeOutputWriter).CalledWarning {
+		t.Error("did not warn about removing the project folder")
+	}
+
+	if !f.down.(*builder.FakeCommand).CalledExec {
+		t.Error("did not remove the project containers and volumes")
+	}
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expecting project folder to be removed, got %v", err)
+	}
+}
+
+func TestNoRollbackExistingDirCreateCommand(t *testing.T) {
+	f := newFakeKoolCreate()
+	f.parser.(*presets.FakeParser).MockExists = true
+	f.createCommand.(*builder.FakeCommand).MockError = errors.New("create error")
+
+	dir, restore := newCreateTestDir(t)
+	defer restore()
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "file"), []byte("content"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewCreateCommand(f)
+	cmd.SetArgs([]string{"laravel", dir})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing create command; error: %v", err)
+	}
+
+	if err := f.out.(*shell.FakeOutputWriter).Err; err == nil || err.Error() != "create error" {
+		t.Errorf("expecting error 'create error', got '%v'", err)
+	}
+
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("unexpected removal of the existing folder: %v", err)
+	}
+}
+
+func TestIsTemplateSource(t *testing.T) {
+	tests := []struct {
+		source   string
+		template bool
+		url      string
+	}{
+		{"laravel", false, ""},
+		{"nextjs-static", false, ""},
+		{"./local-template", true, ""},
+		{"/templates/service", true, ""},
+		{"github.com/org/template", true, "https://github.com/org/template"},
+		{"https://gitlab.com/org/template.git", true, "https://gitlab.com/org/template.git"},
+		{"git@github.com:org/template.git", true, "git@github.com:org/template.git"},
+	}
+
+	for _, test := range tests {
+		if isTemplateSource(test.source) != test.template {
+			t.Errorf("expecting isTemplateSource(%s) to be %v", test.source, test.template)
+		}
+
+		if test.url != "" && templateGitURL(test.source) != test.url {
+			t.Errorf("expecting git URL %s for %s, got %s", test.url, test.source, templateGitURL(test.source))
+		}
+	}
+}
+
+func TestLocalTemplateCreateCommand(t *testing.T) {
+	f := newFakeKoolCreate()
+	f.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+	f.KoolPreset.presetsParser.(*presets.FakeParser).MockFiles = map[string]string{
+		presets.PresetManifestFile: "variables:\n  - name: app_name\n    default: demo\n",
+	}
+
+	dir, restore := newCreateTestDir(t)
+	defer restore()
+
+	source := filepath.Join(filepath.Dir(dir), "template")
+	files := map[string]string{
+		".git/HEAD":        "ref: refs/heads/main",
+		"README.md":        "# {{ .app_name }}",
+		"config.env.tmpl":  "APP_NAME={{ .app_name }}",
+		"src/main.go.tmpl": "package main // {{ .app_name }}",
+	}
+
+	for path, content := range files {
+		path = filepath.Join(source, path)
+		_ = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+
+		if err := ioutil.WriteFile(path, []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := NewCreateCommand(f)
+	cmd.SetArgs([]string{source, dir})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing create command; error: %v", err)
+	}
+
+	if f.out.(*shell.FakeOutputWriter).CalledError {
+		t.Errorf("unexpected error: %v", f.out.(*shell.FakeOutputWriter).Err)
+	}
+
+	if f.cloneCommand.(*builder.FakeCommand).CalledInteractive || f.createCommand.(*builder.FakeCommand).CalledInteractive {
+		t.Error("unexpected command running for a local template")
+	}
+
+	expected := map[string]string{
+		"README.md":   "# {{ .app_name }}",
+		"config.env":  "APP_NAME=demo",
+		"src/main.go": "package main // demo",
+	}
+
+	for path, content := range expected {
+		if read, err := ioutil.ReadFile(filepath.Join(dir, path)); err != nil || string(read) != content {
+			t.Errorf("expecting %s with content '%s', got '%s' (err: %v)", path, content, read, err)
+		}
+	}
+
+	for _, path := range []string{".git", "config.env.tmpl", "src/main.go.tmpl"} {
+		if _, err := os.Stat(filepath.Join(dir, path)); !os.IsNotExist(err) {
+			t.Errorf("unexpected %s on the project folder", path)
+		}
+	}
+}
+
+func TestGitTemplateCreateCommand(t *testing.T) {
+	f := newFakeKoolCreate()
+
+	dir, restore := newCreateTestDir(t)
+	defer restore()
+
+	cmd := NewCreateCommand(f)
+	cmd.SetArgs([]string{"github.com/org/template", dir})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing create command; error: %v", err)
+	}
+
+	if f.out.(*shell.FakeOutputWriter).CalledError {
+		t.Errorf("unexpected error: %v", f.out.(*shell.FakeOutputWriter).Err)
+	}
+
+	if args := f.cloneCommand.(*builder.FakeCommand).ArgsInteractive; len(args) != 2 || args[0] != "https://github.com/org/template" || args[1] != dir {
+		t.Errorf("unexpected template clone arguments %v", args)
+	}
+
+	if f.parser.(*presets.FakeParser).CalledGetCreateCommand {
+		t.Error("unexpected preset create command for a template")
+	}
+}
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"kool-dev/kool/cmd/compose"
+	"kool-dev/kool/environment"
+
 	"github.com/spf13/cobra"
 )
 
@@ -21,4 +24,8 @@ func init() {
 	// rootCmd.AddCommand(dbCmd)
 
 	dbCmd.PersistentFlags().StringVarP(&dbFlags.ServiceName, "service", "s", "database", "The service name for the database container.")
+
+	_ = dbCmd.RegisterFlagCompletionFunc("service", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return compListServices(toComplete, nil, environment.NewEnvStorage(), compose.NewParser()), cobra.ShellCompDirectiveNoFileComp
+	})
 }
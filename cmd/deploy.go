@@ -5,70 +5,125 @@ import (
 	"kool-dev/kool/api"
 	"kool-dev/kool/cmd/shell"
 	"kool-dev/kool/environment"
+	"kool-dev/kool/git"
+	"kool-dev/kool/ignore"
 	"kool-dev/kool/tgz"
 	"os"
-	"os/exec"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 )
 
-var deployCmd = &cobra.Command{
-	Use:   "deploy",
-	Short: "Deploys your application using Kool Dev",
-	Run:   runDeploy,
+// KoolDeployFlags holds the flags for the deploy command
+type KoolDeployFlags struct {
+	DryRun       bool
+	Local        bool
+	Reproducible bool
 }
 
+// KoolDeploy holds handlers and functions to implement the deploy command logic
+type KoolDeploy struct {
+	DefaultKoolService
+	Flags *KoolDeployFlags
+
+	envStorage environment.EnvStorage
+	local      KoolService
+}
+
+// koolIgnoreFile is the file holding patterns for files
+// that should be left out of the release tarball.
+const koolIgnoreFile string = ".koolignore"
+
+// defaultKoolIgnore holds the patterns always left out of
+// the release tarball unless negated in the .koolignore file.
+var defaultKoolIgnore = []string{".env", ".git", "node_modules"}
+
 func init() {
+	var (
+		deploy    = NewKoolDeploy()
+		deployCmd = NewDeployCommand(deploy)
+	)
+
+	deployCmd.AddCommand(NewDeployPackCommand(NewKoolDeployPack()))
+
 	rootCmd.AddCommand(deployCmd)
 }
 
-func runDeploy(cmd *cobra.Command, args []string) {
+// NewKoolDeploy creates a new handler for deploy logic with default dependencies
+func NewKoolDeploy() *KoolDeploy {
+	return &KoolDeploy{
+		*newDefaultKoolService(),
+		&KoolDeployFlags{false, false, false},
+		environment.NewEnvStorage(),
+		NewKoolDeployLocal(),
+	}
+}
+
+// Execute runs the deploy logic with incoming arguments.
+func (d *KoolDeploy) Execute(args []string) (err error) {
 	var (
-		filename     string
-		deploy       *api.Deploy
-		err          error
-		outputWriter shell.OutputWriter
+		filename string
+		tarball  *tgz.TarGz
+		deploy   *api.Deploy
 	)
 
-	outputWriter = shell.NewOutputWriter()
+	if d.Flags.Local && d.Flags.DryRun {
+		err = fmt.Errorf("the --dry-run and --local flags can't be used together")
+		return
+	}
+
+	if d.Flags.Local {
+		d.local.SetWriter(d.GetWriter())
+		err = d.local.Execute(args)
+		return
+	}
 
-	if url := environment.NewEnvStorage().Get("KOOL_API_URL"); url != "" {
+	if url := d.envStorage.Get("KOOL_API_URL"); url != "" {
 		api.SetBaseURL(url)
 	}
 
-	fmt.Println("Create release file...")
-	filename, err = createReleaseFile()
+	d.Println("Create release file...")
 
-	if err != nil {
-		outputWriter.Error(err)
-		os.Exit(1)
+	if tarball, err = tgz.NewTemp(); err != nil {
+		return
 	}
 
 	defer func(file string) {
 		var err error
-		if err = os.Remove(file); err != nil {
-			outputWriter.Error(fmt.Errorf("error trying to remove temporary tarball: %v", err))
+		if err = os.Remove(file); err != nil && !os.IsNotExist(err) {
+			d.Error(fmt.Errorf("error trying to remove temporary tarball: %v", err))
 		}
-	}(filename)
+	}(tarball.Name())
+
+	if d.Flags.Reproducible {
+		tarball.SetReproducible(releaseModTime(d.envStorage))
+	}
+
+	if filename, err = createReleaseFile(d, tarball); err != nil {
+		return
+	}
+
+	if d.Flags.DryRun {
+		err = printReleaseSummary(d, filename, tarball.Hash())
+		return
+	}
+
+	d.Println("Release tarball SHA-256:", tarball.Hash())
 
 	deploy = api.NewDeploy(filename)
 
-	fmt.Println("Upload release file...")
-	err = deploy.SendFile()
+	d.Println("Upload release file...")
 
-	if err != nil {
-		outputWriter.Error(err)
-		os.Exit(1)
+	if err = deploy.SendFile(); err != nil {
+		return
 	}
 
-	fmt.Println("Going to deploy...")
+	d.Println("Going to deploy...")
 
 	timeout := 10 * time.Minute
 
-	if min, err := strconv.Atoi(environment.NewEnvStorage().Get("KOOL_API_TIMEOUT")); err == nil {
+	if min, err := strconv.Atoi(d.envStorage.Get("KOOL_API_TIMEOUT")); err == nil {
 		timeout = time.Duration(min) * time.Minute
 	}
 
@@ -77,16 +132,16 @@ func runDeploy(cmd *cobra.Command, args []string) {
 	go func(deploy *api.Deploy, finishes chan bool) {
 		var lastStatus string
 		for {
-			err = deploy.GetStatus()
+			err := deploy.GetStatus()
 
 			if lastStatus != deploy.Status {
 				lastStatus = deploy.Status
-				fmt.Println("  > deploy:", lastStatus)
+				d.Println("  > deploy:", lastStatus)
 			}
 
 			if err != nil {
+				d.Error(err)
 				finishes <- false
-				outputWriter.Error(err)
 				break
 			}
 
@@ -99,79 +154,90 @@ func runDeploy(cmd *cobra.Command, args []string) {
 		}
 	}(deploy, finishes)
 
-	var success bool
 	select {
-	case success = <-finishes:
-		{
-			if success {
-				outputWriter.Success("Deploy finished: ", deploy.GetURL())
-			} else {
-				outputWriter.Error(fmt.Errorf("deploy failed"))
-				os.Exit(1)
-			}
-			break
+	case success := <-finishes:
+		if !success {
+			err = fmt.Errorf("deploy failed")
+			return
 		}
 
+		d.Success("Deploy finished: ", deploy.GetURL())
 	case <-time.After(timeout):
-		{
-			outputWriter.Error(fmt.Errorf("timeout waiting deploy to finish"))
-			os.Exit(2)
-			break
-		}
+		d.Error(fmt.Errorf("timeout waiting deploy to finish"))
+		d.Exit(2)
+	}
+
+	return
+}
+
+// NewDeployCommand initializes new kool deploy command
+func NewDeployCommand(deploy *KoolDeploy) (deployCmd *cobra.Command) {
+	deployCmd = &cobra.Command{
+		Use:   "deploy",
+		Short: "Deploys your application using Kool Dev",
+		Args:  cobra.NoArgs,
+		Run:   DefaultCommandRunFunction(deploy),
 	}
+
+	deployCmd.Flags().BoolVarP(&deploy.Flags.DryRun, "dry-run", "", false, "Build the release tarball and list its contents without deploying it")
+	deployCmd.Flags().BoolVarP(&deploy.Flags.Reproducible, "reproducible", "", false, "Build a reproducible release tarball, with sorted entries and normalized timestamps and ownership")
+	deployCmd.Flags().BoolVarP(&deploy.Flags.Local, "local", "", false, "Build the production image from Dockerfile.build and run it locally instead of deploying")
+
+	if local, ok := deploy.local.(*KoolDeployLocal); ok {
+		deployCmd.Flags().IntVarP(&local.Flags.Port, "port", "", 80, "Container port to be published and health checked when running with --local")
+		deployCmd.Flags().StringVarP(&local.Flags.HealthPath, "health-path", "", "/", "HTTP path polled for health checking when running with --local")
+		deployCmd.Flags().IntVarP(&local.Flags.HealthTimeout, "health-timeout", "", 120, "Seconds to wait for the container to become healthy when running with --local")
+		deployCmd.Flags().StringArrayVarP(&local.Flags.EnvVariables, "env", "e", []string{}, "Environment variables for the container when running with --local")
+	}
+
+	return
 }
 
-func createReleaseFile() (filename string, err error) {
+func createReleaseFile(out shell.OutputWriter, tarball *tgz.TarGz) (filename string, err error) {
 	var (
-		tarball *tgz.TarGz
-		cwd     string
+		cwd   string
+		repo  *git.Repository
+		files []string
 	)
 
-	tarball, err = tgz.NewTemp()
+	matcher := ignore.NewMatcher(defaultKoolIgnore...)
 
-	if err != nil {
+	if err = matcher.AddFile(koolIgnoreFile, ""); err != nil {
 		return
 	}
 
-	var hasGit bool = true
-	if _, err = exec.LookPath("git"); err != nil {
-		hasGit = false
-	}
-
-	if _, err = os.Stat(".git"); hasGit && !os.IsNotExist(err) {
-		// we are in a Git environment
-		var (
-			output []byte
-			files  []string
-		)
-		// Exclude list
-		// git ls-files -d // delete files
-		output, err = exec.Command("git", "ls-files", "-d").CombinedOutput()
-		if err != nil {
-			panic(fmt.Errorf("Failed listing deleted "))
-		}
-		tarball.SetIgnoreList(strings.Split(string(output), "\n"))
+	tarball.SetIgnoreMatcher(matcher)
 
-		// Include list
-		// git ls-files -c
-		output, err = exec.Command("git", "ls-files", "-c").CombinedOutput()
-		if err != nil {
-			panic(fmt.Errorf("Failed list Git cached files"))
-		}
-		files = append(files, strings.Split(string(output), "\n")...)
-		// git ls-files -o --exclude-standard
-		output, err = exec.Command("git", "ls-files", "-o", "--exclude-standard").CombinedOutput()
-		if err != nil {
-			panic(fmt.Errorf("Failed list Git untracked non-ignored files"))
-		}
-		files = append(files, strings.Split(string(output), "\n")...)
+	if cwd, err = os.Getwd(); err != nil {
+		return
+	}
 
-		filename, err = tarball.CompressFiles(files)
-	} else {
-		fmt.Println("Fallback to tarball full current working directory...")
-		cwd, _ = os.Getwd()
+	if repo, err = git.Open(cwd); err == git.ErrNotRepository {
+		out.Warning("Fallback to tarball full current working directory...")
 		filename, err = tarball.CompressFolder(cwd)
+		return
+	} else if err != nil {
+		return
 	}
 
+	// we are in a Git environment; tracked files still present
+	// in the working tree plus the untracked non-ignored ones
+	if files, err = repo.ListFiles(); err != nil {
+		err = fmt.Errorf("failed listing git files: %v", err)
+		return
+	}
+
+	filename, err = tarball.CompressFiles(files)
 	return
 }
+
+// releaseModTime is the modification time set to all files in a
+// reproducible release tarball; it honours the SOURCE_DATE_EPOCH
+// convention for reproducible builds, defaulting to the Unix epoch.
+func releaseModTime(envStorage environment.EnvStorage) time.Time {
+	if epoch, err := strconv.ParseInt(envStorage.Get("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+		return time.Unix(epoch, 0)
+	}
+
+	return time.Unix(0, 0)
+}
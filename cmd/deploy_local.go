@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/cmd/checker"
+	"kool-dev/kool/cmd/network"
+	"kool-dev/kool/cmd/shell"
+	"kool-dev/kool/environment"
+	"kool-dev/kool/tgz"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KoolDeployLocalFlags holds the flags for rehearsing a deploy locally
+type KoolDeployLocalFlags struct {
+	Port          int
+	HealthPath    string
+	HealthTimeout int
+	EnvVariables  []string
+}
+
+// KoolDeployLocal holds handlers and functions to build the production
+// image out of Dockerfile.build and run it locally like a deploy would.
+type KoolDeployLocal struct {
+	DefaultKoolService
+	Flags *KoolDeployLocalFlags
+
+	check      checker.Checker
+	net        network.Handler
+	envStorage environment.EnvStorage
+
+	build  builder.Command
+	remove builder.Command
+	run    builder.Command
+	port   builder.Command
+	logs   builder.Command
+
+	httpGet func(string) (*http.Response, error)
+}
+
+// deployLocalDockerfile is the Dockerfile used for building the production image
+const deployLocalDockerfile string = "Dockerfile.build"
+
+// NewKoolDeployLocal creates a new handler for local deploy logic with default dependencies
+func NewKoolDeployLocal() *KoolDeployLocal {
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	return &KoolDeployLocal{
+		*newDefaultKoolService(),
+		&KoolDeployLocalFlags{80, "/", 120, []string{}},
+		checker.NewChecker(),
+		network.NewHandler(),
+		environment.NewEnvStorage(),
+		builder.NewCommand("docker", "build", "--file", deployLocalDockerfile),
+		builder.NewCommand("docker", "rm", "--force"),
+		builder.NewCommand("docker", "run", "--detach"),
+		builder.NewCommand("docker", "port"),
+		builder.NewCommand("docker", "logs", "--tail", "50"),
+		client.Get,
+	}
+}
+
+// Execute builds the release image and runs it locally, waiting for it to become healthy.
+func (l *KoolDeployLocal) Execute(args []string) (err error) {
+	var (
+		filename, address string
+		tarball           *tgz.TarGz
+		name              = l.containerName()
+		globalNetwork     = l.envStorage.Get("KOOL_GLOBAL_NETWORK")
+	)
+
+	if err = l.check.Check(); err != nil {
+		return
+	}
+
+	if _, err = os.Stat(deployLocalDockerfile); os.IsNotExist(err) {
+		err = fmt.Errorf("%s not found; it's required for building the production image", deployLocalDockerfile)
+		return
+	}
+
+	if err = l.net.HandleGlobalNetwork(globalNetwork); err != nil {
+		return
+	}
+
+	l.Println("Create release file...")
+
+	if tarball, err = tgz.NewTemp(); err != nil {
+		return
+	}
+
+	defer os.Remove(tarball.Name())
+
+	if filename, err = createReleaseFile(l, tarball); err != nil {
+		return
+	}
+
+	l.Println("Build production image...")
+
+	// the release tarball is the build context, so the image gets
+	// exactly the same files a deploy would upload
+	if err = l.build.Interactive("--tag", name, "-", shell.InputRedirect, filename); err != nil {
+		return
+	}
+
+	_, _ = l.remove.Exec(name)
+
+	l.Println("Run production image...")
+
+	l.run.AppendArgs("--name", name, "--network", globalNetwork, "--publish", fmt.Sprintf("127.0.0.1::%d", l.Flags.Port))
+
+	for _, envVar := range l.deployEnv() {
+		l.run.AppendArgs("--env", envVar)
+	}
+
+	if _, err = l.run.Exec(name); err != nil {
+		err = fmt.Errorf("failed to run production image: %v", err)
+		return
+	}
+
+	if address, err = l.port.Exec(name, strconv.Itoa(l.Flags.Port)); err != nil {
+		err = fmt.Errorf("failed to get published port: %v", err)
+		return
+	}
+
+	// docker port might list both IPv4 and IPv6 bindings
+	url := fmt.Sprintf("http://%s%s", strings.Split(strings.TrimSpace(address), "\n")[0], l.Flags.HealthPath)
+
+	l.Println("Waiting for", url, "to become healthy...")
+
+	if err = l.waitHealthy(url); err != nil {
+		if logs, logsErr := l.logs.Exec(name); logsErr == nil {
+			l.Println(logs)
+		}
+
+		err = fmt.Errorf("production image did not become healthy: %v", err)
+		return
+	}
+
+	l.Success("Production image is healthy and running at ", url)
+	l.Println("When done, remove it with: docker rm --force", name)
+	return
+}
+
+func (l *KoolDeployLocal) containerName() string {
+	return strings.ToLower(l.envStorage.Get("KOOL_NAME")) + "_deploy_local"
+}
+
+// deployEnv gathers the KOOL_DEPLOY_* variables from the environment
+// along with the ones given through flags.
+func (l *KoolDeployLocal) deployEnv() (envVars []string) {
+	for _, envVar := range l.envStorage.All() {
+		if strings.HasPrefix(envVar, "KOOL_DEPLOY_") {
+			envVars = append(envVars, envVar)
+		}
+	}
+
+	envVars = append(envVars, l.Flags.EnvVariables...)
+	return
+}
+
+func (l *KoolDeployLocal) waitHealthy(url string) (err error) {
+	var (
+		resp     *http.Response
+		deadline = time.Now().Add(time.Duration(l.Flags.HealthTimeout) * time.Second)
+	)
+
+	for {
+		if resp, err = l.httpGet(url); err == nil {
+			resp.Body.Close()
+
+			if resp.StatusCode < http.StatusBadRequest {
+				return
+			}
+
+			err = fmt.Errorf("unhealthy response status %d", resp.StatusCode)
+		}
+
+		if time.Now().After(deadline) {
+			return
+		}
+
+		time.Sleep(time.Second)
+	}
+}
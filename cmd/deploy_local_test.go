@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/cmd/checker"
+	"kool-dev/kool/cmd/network"
+	"kool-dev/kool/cmd/shell"
+	"kool-dev/kool/environment"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newFakeKoolDeployLocal(statusCode int) *KoolDeployLocal {
+	envStorage := environment.NewFakeEnvStorage()
+	envStorage.Envs["KOOL_NAME"] = "MyApp"
+	envStorage.Envs["KOOL_GLOBAL_NETWORK"] = "kool_global"
+	envStorage.Envs["KOOL_DEPLOY_DOMAIN"] = "app.kool.dev"
+
+	return &KoolDeployLocal{
+		*newFakeKoolService(),
+		&KoolDeployLocalFlags{80, "/health", 0, []string{"APP_ENV=production"}},
+		&checker.FakeChecker{},
+		&network.FakeHandler{},
+		envStorage,
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{MockExecOut: "127.0.0.1:49153\n[::1]:49153"},
+		&builder.FakeCommand{MockExecOut: "container logs"},
+		func(url string) (*http.Response, error) {
+			return &http.Response{StatusCode: statusCode, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+		},
+	}
+}
+
+func chdirDeployLocalProject(t *testing.T) (restore func()) {
+	dir, _ := ioutil.TempDir("", "kool-deploy-local")
+	_ = ioutil.WriteFile(filepath.Join(dir, "Dockerfile.build"), []byte("FROM scratch"), os.ModePerm)
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(dir)
+
+	return func() {
+		_ = os.Chdir(cwd)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestNewKoolDeployLocal(t *testing.T) {
+	k := NewKoolDeployLocal()
+
+	if _, ok := k.check.(*checker.DefaultChecker); !ok {
+		t.Errorf("unexpected checker.Checker on default KoolDeployLocal instance")
+	}
+
+	if _, ok := k.net.(*network.DefaultHandler); !ok {
+		t.Errorf("unexpected network.Handler on default KoolDeployLocal instance")
+	}
+
+	if k.build.(*builder.DefaultCommand).String() != "docker build --file Dockerfile.build" {
+		t.Errorf("unexpected build command on default KoolDeployLocal instance")
+	}
+
+	if k.Flags.Port != 80 || k.Flags.HealthPath != "/" {
+		t.Errorf("bad default flags on default KoolDeployLocal instance")
+	}
+}
+
+func TestDeployLocal(t *testing.T) {
+	defer chdirDeployLocalProject(t)()
+
+	l := newFakeKoolDeployLocal(http.StatusOK)
+
+	if err := l.Execute(nil); err != nil {
+		t.Fatalf("unexpected error running local deploy: %v", err)
+	}
+
+	if !l.check.(*checker.FakeChecker).CalledCheck {
+		t.Error("did not call Check")
+	}
+
+	if l.net.(*network.FakeHandler).NetworkNameArg != "kool_global" {
+		t.Error("did not handle the global network")
+	}
+
+	buildArgs := l.build.(*builder.FakeCommand).ArgsInteractive
+	if len(buildArgs) != 5 || buildArgs[1] != "myapp_deploy_local" || buildArgs[3] != shell.InputRedirect {
+		t.Errorf("unexpected build arguments: %v", buildArgs)
+	}
+
+	runArgs := strings.Join(l.run.(*builder.FakeCommand).ArgsAppend, " ")
+	if !strings.Contains(runArgs, "--network kool_global") || !strings.Contains(runArgs, "--publish 127.0.0.1::80") {
+		t.Errorf("unexpected run arguments: %s", runArgs)
+	}
+
+	if !strings.Contains(runArgs, "--env KOOL_DEPLOY_DOMAIN=app.kool.dev") || !strings.Contains(runArgs, "--env APP_ENV=production") {
+		t.Errorf("expected deploy environment variables on run arguments: %s", runArgs)
+	}
+
+	if !l.out.(*shell.FakeOutputWriter).CalledSuccess {
+		t.Error("expected success message")
+	}
+
+	if url := l.out.(*shell.FakeOutputWriter).SuccessOutput[1]; url != "http://127.0.0.1:49153/health" {
+		t.Errorf("unexpected health URL: %v", url)
+	}
+}
+
+func TestDeployLocalUnhealthy(t *testing.T) {
+	defer chdirDeployLocalProject(t)()
+
+	l := newFakeKoolDeployLocal(http.StatusInternalServerError)
+
+	err := l.Execute(nil)
+
+	if err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Errorf("expected unhealthy error, got %v", err)
+	}
+
+	if !l.logs.(*builder.FakeCommand).CalledExec {
+		t.Error("expected container logs to be shown")
+	}
+}
+
+func TestDeployLocalMissingDockerfile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "kool-deploy-local")
+	defer os.RemoveAll(dir)
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(dir)
+	defer func() { _ = os.Chdir(cwd) }()
+
+	l := newFakeKoolDeployLocal(http.StatusOK)
+
+	if err := l.Execute(nil); err == nil || !strings.Contains(err.Error(), "Dockerfile.build") {
+		t.Errorf("expected missing Dockerfile.build error, got %v", err)
+	}
+}
+
+func TestDeployLocalFailingRun(t *testing.T) {
+	defer chdirDeployLocalProject(t)()
+
+	l := newFakeKoolDeployLocal(http.StatusOK)
+	l.run.(*builder.FakeCommand).MockError = errors.New("run error")
+
+	if err := l.Execute(nil); err == nil || !strings.Contains(err.Error(), "run error") {
+		t.Errorf("expected run error, got %v", err)
+	}
+}
+
+func TestDeployCommandLocal(t *testing.T) {
+	deploy := NewKoolDeploy()
+	local := &FakeKoolService{}
+	deploy.local = local
+	deploy.exiter = &shell.FakeExiter{}
+
+	cmd := NewDeployCommand(deploy)
+	cmd.SetArgs([]string{"--local"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error executing deploy command: %v", err)
+	}
+
+	if !local.CalledExecute {
+		t.Error("expected local deploy to be executed")
+	}
+}
+
+func TestDeployCommandLocalDryRun(t *testing.T) {
+	deploy := NewKoolDeploy()
+	local := &FakeKoolService{}
+	deploy.local = local
+	deploy.exiter = &shell.FakeExiter{}
+	deploy.out = &shell.FakeOutputWriter{}
+
+	cmd := NewDeployCommand(deploy)
+	cmd.SetArgs([]string{"--local", "--dry-run"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error executing deploy command: %v", err)
+	}
+
+	if local.CalledExecute {
+		t.Error("unexpected local deploy when passing --dry-run")
+	}
+
+	if err := deploy.out.(*shell.FakeOutputWriter).Err; err == nil || !strings.Contains(err.Error(), "can't be used together") {
+		t.Errorf("expecting the flags combination to be rejected, got %v", err)
+	}
+}
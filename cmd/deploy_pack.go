@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"kool-dev/kool/cmd/shell"
+	"kool-dev/kool/environment"
+	"kool-dev/kool/tgz"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolDeployPackFlags holds the flags for the deploy pack command
+type KoolDeployPackFlags struct {
+	Output       string
+	Reproducible bool
+}
+
+// KoolDeployPack holds handlers and functions to implement the deploy pack command logic
+type KoolDeployPack struct {
+	DefaultKoolService
+	Flags *KoolDeployPackFlags
+
+	envStorage environment.EnvStorage
+}
+
+// releaseLargestFiles is the amount of files highlighted
+// by size when summarizing a release tarball.
+const releaseLargestFiles int = 10
+
+// releaseSensitivePaths are paths that usually should not be shipped
+// within a release tarball; we warn about them when found.
+var releaseSensitivePaths = []string{".env", "node_modules"}
+
+// NewKoolDeployPack creates a new handler for deploy pack logic with default dependencies
+func NewKoolDeployPack() *KoolDeployPack {
+	return &KoolDeployPack{
+		*newDefaultKoolService(),
+		&KoolDeployPackFlags{"release.tgz", false},
+		environment.NewEnvStorage(),
+	}
+}
+
+// Execute runs the deploy pack logic with incoming arguments.
+func (p *KoolDeployPack) Execute(args []string) (err error) {
+	var (
+		filename string
+		tarball  *tgz.TarGz
+	)
+
+	if tarball, err = tgz.New(p.Flags.Output); err != nil {
+		return
+	}
+
+	if p.Flags.Reproducible {
+		tarball.SetReproducible(releaseModTime(p.envStorage))
+	}
+
+	if filename, err = createReleaseFile(p, tarball); err != nil {
+		return
+	}
+
+	if err = printReleaseSummary(p, filename, tarball.Hash()); err != nil {
+		return
+	}
+
+	p.Success("Release tarball written to ", filename)
+	return
+}
+
+// NewDeployPackCommand initializes new kool deploy pack command
+func NewDeployPackCommand(pack *KoolDeployPack) (packCmd *cobra.Command) {
+	packCmd = &cobra.Command{
+		Use:   "pack",
+		Short: "Build the release tarball locally without deploying it",
+		Args:  cobra.NoArgs,
+		Run:   DefaultCommandRunFunction(pack),
+	}
+
+	packCmd.Flags().StringVarP(&pack.Flags.Output, "output", "o", "release.tgz", "Path for the generated release tarball")
+	packCmd.Flags().BoolVarP(&pack.Flags.Reproducible, "reproducible", "", false, "Build a reproducible release tarball, with sorted entries and normalized timestamps and ownership")
+	return
+}
+
+// printReleaseSummary lists the contents of the given release tarball
+// along with its total size, largest files and checksum.
+func printReleaseSummary(out shell.OutputWriter, filename string, checksum string) (err error) {
+	var (
+		entries   []*tgz.Entry
+		files     []*tgz.Entry
+		totalSize int64
+		sensitive = make(map[string]bool)
+	)
+
+	if entries, err = tgz.List(filename); err != nil {
+		return
+	}
+
+	out.Println("Release contents:")
+
+	for _, entry := range entries {
+		if entry.IsDir {
+			continue
+		}
+
+		out.Println(" ", entry.Name)
+
+		files = append(files, entry)
+		totalSize += entry.Size
+
+		for _, path := range releaseSensitivePaths {
+			if isReleasePathWithin(entry.Name, path) {
+				sensitive[path] = true
+			}
+		}
+	}
+
+	out.Println("")
+	out.Println("Total files:", len(files))
+	out.Println("Total size:", formatReleaseSize(totalSize))
+	out.Println("SHA-256:", checksum)
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Size > files[j].Size
+	})
+
+	if len(files) > releaseLargestFiles {
+		files = files[:releaseLargestFiles]
+	}
+
+	if len(files) > 0 {
+		out.Println("Largest files:")
+
+		for _, file := range files {
+			out.Println(" ", formatReleaseSize(file.Size), file.Name)
+		}
+	}
+
+	for _, path := range releaseSensitivePaths {
+		if sensitive[path] {
+			out.Warning("Attention: the release contains ", path, " - make sure you really want to ship it")
+		}
+	}
+
+	return
+}
+
+func isReleasePathWithin(name string, path string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(name), "/") {
+		if part == path {
+			return true
+		}
+	}
+	return false
+}
+
+func formatReleaseSize(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.2fMB", float64(size)/1024/1024)
+	case size >= 1024:
+		return fmt.Sprintf("%.2fKB", float64(size)/1024)
+	}
+
+	return fmt.Sprintf("%dB", size)
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"kool-dev/kool/cmd/shell"
+	"kool-dev/kool/environment"
+	"kool-dev/kool/tgz"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewKoolDeployPack(t *testing.T) {
+	k := NewKoolDeployPack()
+
+	if _, ok := k.DefaultKoolService.out.(*shell.DefaultOutputWriter); !ok {
+		t.Errorf("unexpected shell.OutputWriter on default KoolDeployPack instance")
+	}
+
+	if k.Flags == nil {
+		t.Errorf("Flags not initialized on default KoolDeployPack instance")
+	} else if k.Flags.Output != "release.tgz" {
+		t.Errorf("bad default value for Output flag on default KoolDeployPack instance")
+	} else if k.Flags.Reproducible {
+		t.Errorf("bad default value for Reproducible flag on default KoolDeployPack instance")
+	}
+
+	if _, ok := k.envStorage.(*environment.DefaultEnvStorage); !ok {
+		t.Errorf("unexpected environment.EnvStorage on default KoolDeployPack instance")
+	}
+}
+
+func TestNewDeployCommand(t *testing.T) {
+	cmd := NewDeployCommand(NewKoolDeploy())
+
+	if flag := cmd.Flags().Lookup("dry-run"); flag == nil {
+		t.Errorf("missing dry-run flag on deploy command")
+	}
+
+	if flag := cmd.Flags().Lookup("reproducible"); flag == nil {
+		t.Errorf("missing reproducible flag on deploy command")
+	}
+}
+
+func TestPrintReleaseSummary(t *testing.T) {
+	var (
+		dir, filename string
+		tarball       *tgz.TarGz
+		err           error
+	)
+
+	dir, _ = ioutil.TempDir("", "kool-release")
+	defer os.RemoveAll(dir)
+
+	_ = os.MkdirAll(filepath.Join(dir, "node_modules", "pkg"), os.ModePerm)
+	_ = ioutil.WriteFile(filepath.Join(dir, "big.txt"), []byte(strings.Repeat("x", 2048)), os.ModePerm)
+	_ = ioutil.WriteFile(filepath.Join(dir, "small.txt"), []byte("x"), os.ModePerm)
+	_ = ioutil.WriteFile(filepath.Join(dir, "node_modules", "pkg", "index.js"), []byte("x"), os.ModePerm)
+
+	if tarball, err = tgz.New(filepath.Join(dir, "release.tgz")); err != nil {
+		t.Fatalf("unexpected error creating tarball: %v", err)
+	}
+
+	if filename, err = tarball.CompressFiles([]string{
+		filepath.Join(dir, "big.txt"),
+		filepath.Join(dir, "small.txt"),
+		filepath.Join(dir, "node_modules", "pkg", "index.js"),
+	}); err != nil {
+		t.Fatalf("unexpected error compressing files: %v", err)
+	}
+
+	out := &shell.FakeOutputWriter{}
+
+	if err = printReleaseSummary(out, filename, tarball.Hash()); err != nil {
+		t.Fatalf("unexpected error printing release summary: %v", err)
+	}
+
+	output := strings.Join(out.OutLines, "\n")
+
+	if !strings.Contains(output, "Total files: 3") {
+		t.Errorf("expected total files on summary, got %s", output)
+	}
+
+	if !strings.Contains(output, "Total size: 2.00KB") {
+		t.Errorf("expected total size on summary, got %s", output)
+	}
+
+	if !strings.Contains(output, "Largest files:\n2.00KB "+strings.TrimPrefix(filepath.Join(dir, "big.txt"), "/")) {
+		t.Errorf("expected big.txt as the largest file, got %s", output)
+	}
+
+	if !out.CalledWarning || !strings.Contains(out.WarningOutput[1].(string), "node_modules") {
+		t.Errorf("expected warning about node_modules being shipped")
+	}
+}
+
+func TestFormatReleaseSize(t *testing.T) {
+	if size := formatReleaseSize(10); size != "10B" {
+		t.Errorf("expected 10B, got %s", size)
+	}
+
+	if size := formatReleaseSize(1536); size != "1.50KB" {
+		t.Errorf("expected 1.50KB, got %s", size)
+	}
+
+	if size := formatReleaseSize(3 * 1024 * 1024); size != "3.00MB" {
+		t.Errorf("expected 3.00MB, got %s", size)
+	}
+}
+
+func TestReleaseModTime(t *testing.T) {
+	envStorage := environment.NewFakeEnvStorage()
+
+	if modTime := releaseModTime(envStorage); modTime.Unix() != 0 {
+		t.Errorf("expected Unix epoch as default release modification time, got %v", modTime)
+	}
+
+	envStorage.Set("SOURCE_DATE_EPOCH", "1600000000")
+
+	if modTime := releaseModTime(envStorage); modTime.Unix() != 1600000000 {
+		t.Errorf("expected SOURCE_DATE_EPOCH as release modification time, got %v", modTime)
+	}
+}
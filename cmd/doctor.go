@@ -0,0 +1,417 @@
+package cmd
+
+import (
+	"fmt"
+	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/cmd/checker"
+	"kool-dev/kool/cmd/parser"
+	"kool-dev/kool/cmd/shell"
+	"kool-dev/kool/environment"
+	"net"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// doctorImage is the image of the containers checking Docker disk space and file sharing
+const doctorImage = "alpine:3.12"
+
+// doctorFileSharingScript writes and reads back files through the project bind mount
+const doctorFileSharingScript = `mkdir -p .kool-doctor && for i in $(seq 500); do echo kool > .kool-doctor/$i && cat .kool-doctor/$i > /dev/null; done; rm -rf .kool-doctor`
+
+var (
+	// doctorDiskWarn and doctorDiskFail are the Docker free disk space thresholds, in KB
+	doctorDiskWarn = 5 * 1024 * 1024
+	doctorDiskFail = 1024 * 1024
+
+	// doctorFileSharingWarn and doctorFileSharingFail are the file sharing check duration thresholds
+	doctorFileSharingWarn = 2 * time.Second
+	doctorFileSharingFail = 5 * time.Second
+
+	doctorPortVariable = regexp.MustCompile(`^(KOOL_\w+_PORT)=(\d+)$`)
+
+	doctorStatusColors = map[string]color.Color{
+		doctorPass: color.FgGreen,
+		doctorWarn: color.FgYellow,
+		doctorFail: color.FgRed,
+	}
+)
+
+// KoolDoctor holds handlers and functions to implement the doctor command logic
+type KoolDoctor struct {
+	DefaultKoolService
+
+	check      checker.Checker
+	envStorage environment.EnvStorage
+
+	docker         builder.Runner
+	dockerCompose  builder.Runner
+	inspectNetwork builder.Runner
+	inspectImage   builder.Runner
+	pullImage      builder.Runner
+	diskFree       builder.Runner
+	fileSharing    builder.Runner
+	publishedPort  builder.Runner
+
+	table  shell.TableWriter
+	listen func(address string) error
+}
+
+// doctorResult holds the outcome of a single doctor check
+type doctorResult struct {
+	check, status, details, hint string
+}
+
+func init() {
+	rootCmd.AddCommand(NewDoctorCommand(NewKoolDoctor()))
+}
+
+// NewKoolDoctor creates a new handler for doctor logic with default dependencies
+func NewKoolDoctor() *KoolDoctor {
+	return &KoolDoctor{
+		*newDefaultKoolService(),
+		checker.NewChecker(),
+		environment.NewEnvStorage(),
+		builder.NewCommand("docker", "info"),
+		builder.NewCommand("docker-compose", "config", "-q"),
+		builder.NewCommand("docker", "network", "inspect", "--format", "{{.Name}}"),
+		builder.NewCommand("docker", "image", "inspect", doctorImage),
+		builder.NewCommand("docker", "pull", "-q", doctorImage),
+		builder.NewCommand("docker", "run", "--rm", doctorImage, "df", "-Pk", "/"),
+		builder.NewCommand("docker", "run", "--rm", "-w", "/kool-doctor"),
+		builder.NewCommand("docker", "ps", "-q", "--filter"),
+		shell.NewTableWriter(),
+		listenTCP,
+	}
+}
+
+// NewDoctorCommand initializes new kool doctor command
+func NewDoctorCommand(doctor *KoolDoctor) *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Checks the environment for issues running kool projects",
+		Long: `Checks the environment for issues running kool projects: Docker and Docker
+Compose binaries, the Docker daemon, the global network, Docker disk space,
+file sharing performance, the KOOL_*_PORT ports, the user mapping and the
+kool.yml and docker-compose.yml files; with hints on fixing the failures.
+
+The Docker disk space and file sharing checks run containers of the
+` + doctorImage + ` image, which is pulled when missing.`,
+		Args: cobra.NoArgs,
+		Run:  DefaultCommandRunFunction(doctor),
+	}
+}
+
+func listenTCP(address string) (err error) {
+	var listener net.Listener
+
+	if listener, err = net.Listen("tcp", address); err == nil {
+		listener.Close()
+	}
+
+	return
+}
+
+// Execute runs the doctor logic with incoming arguments.
+func (d *KoolDoctor) Execute(args []string) (err error) {
+	var (
+		results        []*doctorResult
+		failed, warned int
+	)
+
+	results = append(results, d.checkBinaries()...)
+	daemon := d.checkDaemon()
+	results = append(results, daemon)
+
+	if daemon.status == doctorPass {
+		results = append(results, d.checkNetwork(), d.checkDiskSpace(), d.checkFileSharing())
+	} else {
+		for _, check := range []string{"Global network", "Disk space", "File sharing"} {
+			results = append(results, &doctorResult{check, doctorWarn, "skipped", "fix the Docker daemon check first"})
+		}
+	}
+
+	results = append(results, d.checkPorts()...)
+	results = append(results, d.checkUserMapping(), d.checkKoolYml(), d.checkCompose())
+
+	d.table.SetWriter(d.GetWriter())
+	d.table.AppendHeader("Check", "Status", "Details")
+
+	for _, result := range results {
+		d.table.AppendRow(result.check, doctorStatusColors[result.status].Sprint(result.status), result.details)
+
+		switch result.status {
+		case doctorFail:
+			failed++
+		case doctorWarn:
+			warned++
+		}
+	}
+
+	d.table.Render()
+
+	if failed+warned > 0 {
+		d.Println("Hints:")
+
+		for _, result := range results {
+			if result.status != doctorPass && result.hint != "" {
+				d.Println(fmt.Sprintf("  - %s: %s", result.check, result.hint))
+			}
+		}
+	}
+
+	switch {
+	case failed > 0:
+		err = fmt.Errorf("%d of %d checks failed", failed, len(results))
+	case warned > 0:
+		d.Warning(warned, " of ", len(results), " checks with warnings")
+	default:
+		d.Success("All checks passed")
+	}
+
+	return
+}
+
+func (d *KoolDoctor) checkBinaries() []*doctorResult {
+	var (
+		docker        = &doctorResult{"Docker", doctorPass, "", ""}
+		dockerCompose = &doctorResult{"Docker Compose", doctorPass, "", ""}
+	)
+
+	if err := d.docker.LookPath(); err != nil {
+		docker.status, docker.details = doctorFail, checker.ErrDockerNotFound.Error()
+		docker.hint = "install Docker from https://docs.docker.com/get-docker/"
+	}
+
+	if err := d.dockerCompose.LookPath(); err != nil {
+		dockerCompose.status, dockerCompose.details = doctorFail, checker.ErrDockerComposeNotFound.Error()
+		dockerCompose.hint = "install Docker Compose from https://docs.docker.com/compose/install/"
+	}
+
+	if docker.status == doctorPass && dockerCompose.status == doctorPass {
+		if dockerVersion, dockerComposeVersion, err := d.check.Versions(); err != nil {
+			docker.status, docker.details = doctorWarn, fmt.Sprintf("failed getting the versions: %v", err)
+		} else {
+			docker.details, dockerCompose.details = dockerVersion, dockerComposeVersion
+		}
+	}
+
+	return []*doctorResult{docker, dockerCompose}
+}
+
+func (d *KoolDoctor) checkDaemon() *doctorResult {
+	if _, err := d.docker.Exec(); err != nil {
+		return &doctorResult{"Docker daemon", doctorFail, checker.ErrDockerNotRunning.Error(), "start Docker and make sure your user can access it"}
+	}
+
+	return &doctorResult{"Docker daemon", doctorPass, "running", ""}
+}
+
+// checkNetwork checks the global network exists, without creating it;
+// kool start creates it when missing.
+func (d *KoolDoctor) checkNetwork() *doctorResult {
+	name := d.envStorage.Get("KOOL_GLOBAL_NETWORK")
+
+	if output, err := d.inspectNetwork.Exec(name); err != nil {
+		details := strings.Split(strings.TrimSpace(output+"\n"+err.Error()), "\n")[0]
+		return &doctorResult{"Global network", doctorWarn, fmt.Sprintf("%s: %s", name, details), "kool start creates the network; otherwise check the KOOL_GLOBAL_NETWORK name and the Docker networks with docker network ls"}
+	}
+
+	return &doctorResult{"Global network", doctorPass, name, ""}
+}
+
+// checkDiskSpace checks the free space of the Docker disk, which is
+// the one of the virtual machine when using Docker Desktop.
+func (d *KoolDoctor) checkDiskSpace() *doctorResult {
+	var (
+		output    string
+		available int
+		err       error
+	)
+
+	if err = d.ensureImage(); err == nil {
+		output, err = d.diskFree.Exec()
+	}
+
+	if lines := strings.Split(strings.TrimSpace(output), "\n"); err == nil {
+		if fields := strings.Fields(lines[len(lines)-1]); len(fields) > 3 {
+			available, err = strconv.Atoi(fields[3])
+		} else {
+			err = fmt.Errorf("unexpected df output: %s", output)
+		}
+	}
+
+	details := fmt.Sprintf("%.1f GB free", float64(available)/1024/1024)
+
+	switch {
+	case err != nil:
+		return &doctorResult{"Disk space", doctorWarn, fmt.Sprintf("failed checking: %v", err), ""}
+	case available < doctorDiskFail:
+		return &doctorResult{"Disk space", doctorFail, details, "free up space with docker system prune or increase the Docker disk size"}
+	case available < doctorDiskWarn:
+		return &doctorResult{"Disk space", doctorWarn, details, "free up space with docker system prune or increase the Docker disk size"}
+	}
+
+	return &doctorResult{"Disk space", doctorPass, details, ""}
+}
+
+// checkFileSharing times writing and reading files through a bind
+// mount of the working directory, as the services usually do.
+func (d *KoolDoctor) checkFileSharing() *doctorResult {
+	var (
+		dir, err = os.Getwd()
+		started  = time.Now()
+		elapsed  time.Duration
+		output   string
+	)
+
+	if err == nil {
+		err = d.ensureImage()
+	}
+
+	if err == nil {
+		started = time.Now()
+		output, err = d.fileSharing.Exec("-v", dir+":/kool-doctor", doctorImage, "sh", "-c", doctorFileSharingScript)
+		elapsed = time.Since(started).Round(10 * time.Millisecond)
+	}
+
+	details := fmt.Sprintf("500 files written and read in %v", elapsed)
+	hint := "make sure the project folder is shared with Docker and consider a faster file sharing implementation"
+
+	switch {
+	case err != nil:
+		return &doctorResult{"File sharing", doctorFail, fmt.Sprintf("failed mounting the project folder: %v %s", err, output), hint}
+	case elapsed > doctorFileSharingFail:
+		return &doctorResult{"File sharing", doctorFail, details, hint}
+	case elapsed > doctorFileSharingWarn:
+		return &doctorResult{"File sharing", doctorWarn, details, hint}
+	}
+
+	return &doctorResult{"File sharing", doctorPass, details, ""}
+}
+
+// ensureImage makes sure the image of the checks containers
+// is available, telling about pulling it when missing.
+func (d *KoolDoctor) ensureImage() (err error) {
+	if _, err = d.inspectImage.Exec(); err != nil {
+		d.Println("Pulling", doctorImage, "for checking the Docker disk space and file sharing...")
+		_, err = d.pullImage.Exec()
+	}
+
+	return
+}
+
+// checkPorts checks the KOOL_*_PORT ports are free on the host or
+// already published by running containers, like the project ones;
+// failing to listen for other reasons than the port being in use,
+// like lacking permissions, is reported apart.
+func (d *KoolDoctor) checkPorts() (results []*doctorResult) {
+	var variables []string
+
+	for _, envVar := range d.envStorage.All() {
+		if doctorPortVariable.MatchString(envVar) {
+			variables = append(variables, envVar)
+		}
+	}
+
+	sort.Strings(variables)
+
+	for _, envVar := range variables {
+		match := doctorPortVariable.FindStringSubmatch(envVar)
+		check := fmt.Sprintf("Port %s (%s)", match[2], match[1])
+
+		if err := d.listen(":" + match[2]); err == nil {
+			results = append(results, &doctorResult{check, doctorPass, "free", ""})
+		} else if !isAddrInUse(err) {
+			results = append(results, &doctorResult{check, doctorWarn, fmt.Sprintf("failed checking: %v", err), fmt.Sprintf("make sure your user can listen on port %s or change %s on .env", match[2], match[1])})
+		} else if containers, _ := d.publishedPort.Exec("publish=" + match[2]); strings.TrimSpace(containers) != "" {
+			results = append(results, &doctorResult{check, doctorPass, "published by a running container", ""})
+		} else {
+			results = append(results, &doctorResult{check, doctorFail, "in use by another process", fmt.Sprintf("stop the process using port %s or change %s on .env", match[2], match[1])})
+		}
+	}
+
+	if len(results) == 0 {
+		results = append(results, &doctorResult{"Ports", doctorPass, "no KOOL_*_PORT variables", ""})
+	}
+
+	return
+}
+
+// checkUserMapping checks the containers run commands with the host
+// user, so the files they create on the project belong to it.
+func (d *KoolDoctor) checkUserMapping() *doctorResult {
+	var (
+		asuser = d.envStorage.Get("KOOL_ASUSER")
+		uid    = strconv.Itoa(os.Getuid())
+		hint   = "set KOOL_ASUSER to your user id (id -u) on .env"
+	)
+
+	switch {
+	case runtime.GOOS == "windows":
+		return &doctorResult{"User mapping", doctorPass, "not applicable on Windows", ""}
+	case asuser == "":
+		return &doctorResult{"User mapping", doctorWarn, "KOOL_ASUSER is not set", hint}
+	case asuser != uid:
+		return &doctorResult{"User mapping", doctorWarn, fmt.Sprintf("KOOL_ASUSER is %s, but your user id is %s", asuser, uid), hint}
+	}
+
+	return &doctorResult{"User mapping", doctorPass, "KOOL_ASUSER=" + asuser, ""}
+}
+
+func (d *KoolDoctor) checkKoolYml() *doctorResult {
+	for _, file := range []string{"kool.yml", "kool.yaml"} {
+		koolYaml, err := parser.ParseKoolYaml(file)
+
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return &doctorResult{"kool.yml", doctorFail, err.Error(), "fix the " + file + " syntax"}
+		}
+
+		for script := range koolYaml.Scripts {
+			if _, err = koolYaml.ParseCommands(script); err != nil {
+				return &doctorResult{"kool.yml", doctorFail, err.Error(), "fix the " + script + " script on " + file}
+			}
+		}
+
+		return &doctorResult{"kool.yml", doctorPass, fmt.Sprintf("%d scripts", len(koolYaml.Scripts)), ""}
+	}
+
+	return &doctorResult{"kool.yml", doctorPass, "not found", ""}
+}
+
+func (d *KoolDoctor) checkCompose() *doctorResult {
+	var found bool
+
+	for _, file := range projectComposeFiles(d.envStorage) {
+		if _, err := os.Stat(file); err == nil {
+			found = true
+		}
+	}
+
+	if !found {
+		return &doctorResult{"docker-compose.yml", doctorWarn, "not found", "create the project with kool create or kool preset"}
+	}
+
+	if output, err := d.dockerCompose.Exec(); err != nil {
+		return &doctorResult{"docker-compose.yml", doctorFail, strings.Split(strings.TrimSpace(output+"\n"+err.Error()), "\n")[0], "fix the docker-compose.yml issues shown by docker-compose config"}
+	}
+
+	return &doctorResult{"docker-compose.yml", doctorPass, "valid", ""}
+}
@@ -0,0 +1,13 @@
+// +build !windows
+
+package cmd
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isAddrInUse tells whether listening failed for the address being in use
+func isAddrInUse(err error) bool {
+	return errors.Is(err, syscall.EADDRINUSE)
+}
@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"errors"
+	"syscall"
+)
+
+// wsaeaddrinuse is the Windows Sockets error for an address in use
+const wsaeaddrinuse = syscall.Errno(10048)
+
+// isAddrInUse tells whether listening failed for the address being in use
+func isAddrInUse(err error) bool {
+	return errors.Is(err, wsaeaddrinuse) || errors.Is(err, syscall.EADDRINUSE)
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/cmd/checker"
+	"kool-dev/kool/cmd/shell"
+	"kool-dev/kool/environment"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func newFakeKoolDoctor() *KoolDoctor {
+	envStorage := environment.NewFakeEnvStorage()
+	envStorage.Set("KOOL_ASUSER", strconv.Itoa(os.Getuid()))
+
+	return &KoolDoctor{
+		*newFakeKoolService(),
+		&checker.FakeChecker{MockDockerVersion: "Docker version 20.10.2", MockDockerComposeVersion: "docker-compose version 1.27.4"},
+		envStorage,
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{MockExecOut: "Filesystem 1024-blocks Used Available Capacity Mounted on\noverlay 61255492 20000000 41255492 33% /"},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&shell.FakeTableWriter{},
+		func(string) error { return nil },
+	}
+}
+
+func newDoctorTestDir(t *testing.T, files map[string]string) (restore func()) {
+	wd, _ := os.Getwd()
+	dir := t.TempDir()
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(name, []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return func() { _ = os.Chdir(wd) }
+}
+
+func TestNewKoolDoctor(t *testing.T) {
+	k := NewKoolDoctor()
+
+	if _, ok := k.DefaultKoolService.out.(*shell.DefaultOutputWriter); !ok {
+		t.Errorf("unexpected shell.OutputWriter on default KoolDoctor instance")
+	}
+
+	if _, ok := k.check.(*checker.DefaultChecker); !ok {
+		t.Errorf("unexpected checker.Checker on default KoolDoctor instance")
+	}
+
+	if _, ok := k.table.(*shell.DefaultTableWriter); !ok {
+		t.Errorf("unexpected shell.TableWriter on default KoolDoctor instance")
+	}
+}
+
+func TestDoctorCommandPassing(t *testing.T) {
+	defer newDoctorTestDir(t, map[string]string{
+		"kool.yml":           "scripts:\n  test: echo test\n",
+		"docker-compose.yml": "services: {}",
+	})()
+
+	f := newFakeKoolDoctor()
+	f.envStorage.Set("KOOL_GLOBAL_NETWORK", "kool_global")
+	cmd := NewDoctorCommand(f)
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing doctor command; error: %v", err)
+	}
+
+	if f.out.(*shell.FakeOutputWriter).CalledError {
+		t.Errorf("unexpected error running the checks; error: %v", f.out.(*shell.FakeOutputWriter).Err)
+	}
+
+	if !f.out.(*shell.FakeOutputWriter).CalledSuccess {
+		t.Errorf("expecting all checks to pass, got:\n%s", f.table.(*shell.FakeTableWriter).TableOut)
+	}
+
+	table := f.table.(*shell.FakeTableWriter).TableOut
+
+	for _, expected := range []string{"Docker version 20.10.2", "docker-compose version 1.27.4", "39.3 GB free", "1 scripts"} {
+		if !strings.Contains(table, expected) {
+			t.Errorf("expecting %s on the doctor table, got:\n%s", expected, table)
+		}
+	}
+
+	if args := f.inspectNetwork.(*builder.FakeCommand).ArgsExec; len(args) != 1 || args[0] != "kool_global" {
+		t.Errorf("bad arguments checking the global network: %v", args)
+	}
+
+	if f.pullImage.(*builder.FakeCommand).CalledExec {
+		t.Error("unexpected pulling of the available checks image")
+	}
+
+	if args := f.fileSharing.(*builder.FakeCommand).ArgsExec; len(args) < 3 || args[0] != "-v" || args[2] != doctorImage {
+		t.Errorf("bad arguments checking the file sharing: %v", args)
+	}
+}
+
+func TestDoctorCommandFailingDocker(t *testing.T) {
+	defer newDoctorTestDir(t, nil)()
+
+	f := newFakeKoolDoctor()
+	f.docker.(*builder.FakeCommand).MockLookPathError = errors.New("not found")
+	f.docker.(*builder.FakeCommand).MockError = errors.New("not running")
+	cmd := NewDoctorCommand(f)
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing doctor command; error: %v", err)
+	}
+
+	if err := f.out.(*shell.FakeOutputWriter).Err; err == nil || err.Error() != "2 of 10 checks failed" {
+		t.Errorf("expecting error '2 of 10 checks failed', got %v", err)
+	}
+
+	if f.check.(*checker.FakeChecker).CalledVersions || f.fileSharing.(*builder.FakeCommand).CalledExec {
+		t.Error("unexpected checks depending on Docker")
+	}
+
+	if lines := f.out.(*shell.FakeOutputWriter).OutLines; len(lines) == 0 || lines[0] != "Hints:" {
+		t.Errorf("expecting the failures hints, got %v", lines)
+	}
+}
+
+func TestDoctorCheckPorts(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer listener.Close()
+
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+
+	f := newFakeKoolDoctor()
+	f.envStorage.Set("KOOL_APP_PORT", port)
+	f.envStorage.Set("KOOL_NAME", "app")
+	f.listen = listenTCP
+	f.publishedPort.(*builder.FakeCommand).MockExecOut = ""
+
+	results := f.checkPorts()
+
+	if len(results) != 1 || results[0].check != "Port "+port+" (KOOL_APP_PORT)" || results[0].status != doctorFail {
+		t.Errorf("expecting port conflicts, got %v", results)
+	}
+
+	f.publishedPort.(*builder.FakeCommand).MockExecOut = "a1b2c3"
+
+	if results = f.checkPorts(); results[0].status != doctorPass {
+		t.Errorf("expecting the ports published by containers to pass, got %v", results[0])
+	}
+
+	f.listen = func(address string) error {
+		return &net.OpError{Op: "listen", Net: "tcp", Err: os.NewSyscallError("bind", syscall.EACCES)}
+	}
+
+	if results = f.checkPorts(); results[0].status != doctorWarn || !strings.Contains(results[0].details, "failed checking") {
+		t.Errorf("expecting failing to listen not to be reported as a conflict, got %v", results[0])
+	}
+}
+
+func TestDoctorCheckNetwork(t *testing.T) {
+	f := newFakeKoolDoctor()
+	f.envStorage.Set("KOOL_GLOBAL_NETWORK", "kool_global")
+	f.inspectNetwork.(*builder.FakeCommand).MockExecOut = "Error: No such network: kool_global"
+	f.inspectNetwork.(*builder.FakeCommand).MockError = errors.New("exit status 1")
+
+	if result := f.checkNetwork(); result.status != doctorWarn || result.details != "kool_global: Error: No such network: kool_global" {
+		t.Errorf("expecting a warning for the missing network, got %v", result)
+	}
+}
+
+func TestDoctorCheckDiskSpace(t *testing.T) {
+	f := newFakeKoolDoctor()
+	f.diskFree.(*builder.FakeCommand).MockExecOut = "Filesystem 1024-blocks Used Available Capacity Mounted on\noverlay 61255492 60255492 1000 99% /"
+
+	if result := f.checkDiskSpace(); result.status != doctorFail {
+		t.Errorf("expecting low disk space to fail, got %v", result)
+	}
+
+	f.inspectImage.(*builder.FakeCommand).MockError = errors.New("no such image")
+	f.pullImage.(*builder.FakeCommand).MockError = errors.New("offline")
+
+	if result := f.checkDiskSpace(); result.status != doctorWarn || !strings.Contains(result.details, "offline") {
+		t.Errorf("expecting a warning when failing to check, got %v", result)
+	}
+
+	if lines := f.out.(*shell.FakeOutputWriter).OutLines; len(lines) != 1 || !strings.HasPrefix(lines[0], "Pulling "+doctorImage) {
+		t.Errorf("expecting to tell about pulling the image, got %v", lines)
+	}
+}
+
+func TestDoctorCheckUserMapping(t *testing.T) {
+	f := newFakeKoolDoctor()
+	f.envStorage.Set("KOOL_ASUSER", "")
+
+	if result := f.checkUserMapping(); result.status == doctorFail {
+		t.Errorf("unexpected failure checking unset KOOL_ASUSER, got %v", result)
+	}
+
+	f.envStorage.Set("KOOL_ASUSER", strconv.Itoa(os.Getuid()+1))
+
+	if result := f.checkUserMapping(); result.status == doctorPass && result.details != "not applicable on Windows" {
+		t.Errorf("expecting a warning for a different KOOL_ASUSER, got %v", result)
+	}
+}
+
+func TestDoctorCheckKoolYml(t *testing.T) {
+	defer newDoctorTestDir(t, map[string]string{"kool.yml": "scripts:\n  test:\n    watch: '*.go'\n"})()
+
+	if result := newFakeKoolDoctor().checkKoolYml(); result.status != doctorFail || !strings.Contains(result.details, "missing the run key") {
+		t.Errorf("expecting the invalid script to fail, got %v", result)
+	}
+}
+
+func TestDoctorCheckCompose(t *testing.T) {
+	defer newDoctorTestDir(t, map[string]string{"docker-compose.yml": "services: ["})()
+
+	f := newFakeKoolDoctor()
+	f.dockerCompose.(*builder.FakeCommand).MockExecOut = "ERROR: yaml.scanner.ScannerError"
+	f.dockerCompose.(*builder.FakeCommand).MockError = errors.New("exit status 1")
+
+	if result := f.checkCompose(); result.status != doctorFail || result.details != "ERROR: yaml.scanner.ScannerError" {
+		t.Errorf("expecting the invalid docker-compose.yml to fail, got %v", result)
+	}
+}
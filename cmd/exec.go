@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/cmd/compose"
 	"kool-dev/kool/environment"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -21,6 +23,17 @@ type KoolExec struct {
 
 	envStorage  environment.EnvStorage
 	composeExec builder.Command
+
+	composeParser   compose.Parser
+	runningServices builder.Runner
+	binaries        builder.Runner
+}
+
+// commonBinaries are the binaries completed for the exec command,
+// when found within the service container.
+var commonBinaries = []string{
+	"bash", "sh", "php", "composer", "node", "npm", "npx", "yarn",
+	"python", "pip", "ruby", "go", "mysql", "psql", "redis-cli", "mongo",
 }
 
 func init() {
@@ -39,6 +52,9 @@ func NewKoolExec() *KoolExec {
 		&KoolExecFlags{false, []string{}, false},
 		environment.NewEnvStorage(),
 		builder.NewCommand("docker-compose", "exec"),
+		compose.NewParser(),
+		builder.NewCommand("docker-compose", "ps", "--services", "--filter", "status=running"),
+		builder.NewCommand("docker-compose", "exec", "-T"),
 	}
 }
 
@@ -73,6 +89,16 @@ func NewExecCommand(exec *KoolExec) (execCmd *cobra.Command) {
 		Short: "Execute a command within a running service container",
 		Args:  cobra.MinimumNArgs(2),
 		Run:   DefaultCommandRunFunction(exec),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return compListRunningServices(toComplete, exec), cobra.ShellCompDirectiveNoFileComp
+			case 1:
+				return compListBinaries(args[0], toComplete, exec), cobra.ShellCompDirectiveNoFileComp
+			}
+
+			return nil, cobra.ShellCompDirectiveDefault
+		},
 	}
 
 	execCmd.Flags().BoolVarP(&exec.Flags.DisableTty, "disable-tty", "T", false, "Deprecated - no effect")
@@ -83,3 +109,62 @@ func NewExecCommand(exec *KoolExec) (execCmd *cobra.Command) {
 	execCmd.Flags().SetInterspersed(false)
 	return
 }
+
+// compListRunningServices lists the project services with running
+// containers; in case Docker can't tell, all services are listed.
+func compListRunningServices(toComplete string, exec *KoolExec) (services []string) {
+	var (
+		output  string
+		err     error
+		running []string
+	)
+
+	services = compListServices(toComplete, nil, exec.envStorage, exec.composeParser)
+
+	if output, err = exec.runningServices.Exec(); err != nil {
+		return
+	}
+
+	running = strings.Fields(output)
+
+	for i := 0; i < len(services); {
+		if containsString(running, services[i]) {
+			i++
+			continue
+		}
+
+		services = append(services[:i], services[i+1:]...)
+	}
+
+	return
+}
+
+// compListBinaries lists the common binaries found within the service
+// container; in case it can't be checked, all of them are listed.
+func compListBinaries(service string, toComplete string, exec *KoolExec) (binaries []string) {
+	var (
+		candidates []string
+		output     string
+		err        error
+	)
+
+	for _, binary := range commonBinaries {
+		if strings.HasPrefix(binary, toComplete) {
+			candidates = append(candidates, binary)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return
+	}
+
+	script := "for b in " + strings.Join(candidates, " ") + "; do command -v $b >/dev/null && echo $b; done"
+
+	if output, err = exec.binaries.Exec(service, "sh", "-c", script); err != nil {
+		binaries = candidates
+		return
+	}
+
+	binaries = strings.Fields(output)
+	return
+}
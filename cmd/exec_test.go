@@ -3,9 +3,15 @@ package cmd
 import (
 	"bytes"
 	"errors"
+	"io/ioutil"
 	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/cmd/compose"
 	"kool-dev/kool/cmd/shell"
 	"kool-dev/kool/environment"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -15,6 +21,9 @@ func newFakeKoolExec() *KoolExec {
 		&KoolExecFlags{false, []string{}, false},
 		environment.NewFakeEnvStorage(),
 		&builder.FakeCommand{},
+		&compose.FakeParser{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
 	}
 }
 
@@ -24,6 +33,9 @@ func newFailedFakeKoolExec() *KoolExec {
 		&KoolExecFlags{false, []string{}, false},
 		environment.NewFakeEnvStorage(),
 		&builder.FakeCommand{MockError: errors.New("error exec")},
+		&compose.FakeParser{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
 	}
 }
 
@@ -201,3 +213,48 @@ func TestNonTerminalNewExecCommand(t *testing.T) {
 		t.Errorf("bad arguments to KoolExec.composeExec Command on non terminal environment")
 	}
 }
+
+func TestExecCompletion(t *testing.T) {
+	wd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(wd) }()
+
+	tmp := t.TempDir()
+	_ = ioutil.WriteFile(filepath.Join(tmp, "docker-compose.yml"), []byte("services: {}\n"), os.ModePerm)
+	_ = os.Chdir(tmp)
+
+	f := newFakeKoolExec()
+	f.composeParser.(*compose.FakeParser).MockServices = []string{"app", "database", "cache"}
+	f.runningServices.(*builder.FakeCommand).MockExecOut = "app\ndatabase\n"
+
+	cmd := NewExecCommand(f)
+
+	if services, _ := cmd.ValidArgsFunction(cmd, []string{}, ""); !reflect.DeepEqual(services, []string{"app", "database"}) {
+		t.Errorf("expecting running services completion, got %v", services)
+	}
+
+	f.runningServices.(*builder.FakeCommand).MockError = errors.New("docker unavailable")
+
+	if services, _ := cmd.ValidArgsFunction(cmd, []string{}, "ca"); !reflect.DeepEqual(services, []string{"cache"}) {
+		t.Errorf("expecting all services completion without Docker, got %v", services)
+	}
+
+	f.binaries.(*builder.FakeCommand).MockExecOut = "php\npsql\n"
+
+	if binaries, _ := cmd.ValidArgsFunction(cmd, []string{"app"}, "p"); !reflect.DeepEqual(binaries, []string{"php", "psql"}) {
+		t.Errorf("expecting binaries found in the container, got %v", binaries)
+	}
+
+	if args := f.binaries.(*builder.FakeCommand).ArgsExec; len(args) != 4 || args[0] != "app" || !strings.Contains(args[3], "php python pip psql") {
+		t.Errorf("unexpected binaries lookup arguments %v", args)
+	}
+
+	f.binaries.(*builder.FakeCommand).MockError = errors.New("not running")
+
+	if binaries, _ := cmd.ValidArgsFunction(cmd, []string{"app"}, "no"); !reflect.DeepEqual(binaries, []string{"node"}) {
+		t.Errorf("expecting common binaries when the container can't be checked, got %v", binaries)
+	}
+
+	if completion, _ := cmd.ValidArgsFunction(cmd, []string{"app", "php"}, ""); completion != nil {
+		t.Errorf("unexpected completion for the command arguments: %v", completion)
+	}
+}
@@ -2,16 +2,26 @@ package cmd
 
 import (
 	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/cmd/checker"
+	"kool-dev/kool/cmd/compose"
+	"kool-dev/kool/environment"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 // KoolLogsFlags holds the flags for the logs command
 type KoolLogsFlags struct {
-	Tail   int
-	Follow bool
+	Tail       int
+	Follow     bool
+	Since      string
+	Until      string
+	Timestamps bool
+	Grep       string
+	JSON       bool
+	Save       bool
 }
 
 // KoolLogs holds handlers and functions to implement the logs command logic
@@ -21,6 +31,14 @@ type KoolLogs struct {
 
 	list builder.Command
 	logs builder.Command
+
+	envStorage    environment.EnvStorage
+	composeParser compose.Parser
+
+	check       checker.Checker
+	status      KoolService
+	config      builder.Runner
+	serviceLogs builder.Runner
 }
 
 func init() {
@@ -36,15 +54,35 @@ func init() {
 func NewKoolLogs() *KoolLogs {
 	return &KoolLogs{
 		*newDefaultKoolService(),
-		&KoolLogsFlags{25, false},
+		&KoolLogsFlags{25, false, "", "", false, "", false, false},
 		builder.NewCommand("docker-compose", "ps", "-aq"),
 		builder.NewCommand("docker-compose", "logs"),
+		environment.NewEnvStorage(),
+		compose.NewParser(),
+		checker.NewChecker(),
+		NewKoolStatus(),
+		builder.NewCommand("docker-compose", "config"),
+		builder.NewCommand("docker-compose", "logs", "--no-color", "--timestamps"),
 	}
 }
 
 // Execute runs the logs logic with incoming arguments.
 func (l *KoolLogs) Execute(args []string) (err error) {
-	var services string
+	var (
+		services string
+		filter   *logsFilter
+	)
+
+	if l.Flags.Save {
+		err = l.saveBundle(args)
+		return
+	}
+
+	if l.filtering() {
+		if filter, err = newLogsFilter(l.out, l.Flags, time.Now()); err != nil {
+			return
+		}
+	}
 
 	if services, err = l.list.Exec(args...); err != nil {
 		return
@@ -65,19 +103,54 @@ func (l *KoolLogs) Execute(args []string) (err error) {
 		l.logs.AppendArgs("--follow")
 	}
 
-	err = l.logs.Interactive(args...)
+	if filter == nil {
+		if l.Flags.Timestamps {
+			l.logs.AppendArgs("--timestamps")
+		}
+
+		err = l.logs.Interactive(args...)
+		return
+	}
+
+	// the lines are filtered and colored by kool, so
+	// docker-compose gives them plain and timestamped
+	l.logs.AppendArgs("--no-color", "--timestamps")
+
+	err = l.logs.Stream(filter, args...)
+	filter.Flush()
 	return
 }
 
+// filtering tells whether the logs lines need to be handled by kool
+func (l *KoolLogs) filtering() bool {
+	return l.Flags.Since != "" || l.Flags.Until != "" || l.Flags.Grep != "" || l.Flags.JSON
+}
+
 // NewLogsCommand initializes new kool logs command
 func NewLogsCommand(logs *KoolLogs) (logsCmd *cobra.Command) {
 	logsCmd = &cobra.Command{
 		Use:   "logs [options] [service...]",
 		Short: "Displays log output from services.",
-		Run:   DefaultCommandRunFunction(logs),
+		Long: `Displays log output from services.
+
+The --since, --until and --grep filters are applied by kool over the lines
+docker-compose gives (so after --tail), as does --json, which shows the
+level and message of JSON structured logs, like Laravel and Node ones.
+
+With --save, a support bundle is written instead: a tarball holding the
+status, environment (secrets masked), docker-compose config, Docker versions
+and the last --tail log lines of each service, for sharing when asking for help.`,
+		Run:               DefaultCommandRunFunction(logs),
+		ValidArgsFunction: completeServices(logs.envStorage, logs.composeParser),
 	}
 
 	logsCmd.Flags().IntVarP(&logs.Flags.Tail, "tail", "t", 25, "Number of lines to show from the end of the logs for each container. For value equal to 0, all lines will be shown.")
 	logsCmd.Flags().BoolVarP(&logs.Flags.Follow, "follow", "f", false, "Follow log output.")
+	logsCmd.Flags().StringVarP(&logs.Flags.Since, "since", "", "", "Show logs since a timestamp (e.g. 2021-01-02T15:04:05) or relative time (e.g. 10m).")
+	logsCmd.Flags().StringVarP(&logs.Flags.Until, "until", "", "", "Show logs before a timestamp (e.g. 2021-01-02T15:04:05) or relative time (e.g. 10m).")
+	logsCmd.Flags().BoolVarP(&logs.Flags.Timestamps, "timestamps", "", false, "Show timestamps.")
+	logsCmd.Flags().StringVarP(&logs.Flags.Grep, "grep", "g", "", "Show only the lines matching the regular expression.")
+	logsCmd.Flags().BoolVarP(&logs.Flags.JSON, "json", "", false, "Show the level and message of JSON structured logs.")
+	logsCmd.Flags().BoolVarP(&logs.Flags.Save, "save", "", false, "Save a support bundle tarball with the logs and diagnostics of the project.")
 	return
 }
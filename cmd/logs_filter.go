@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"kool-dev/kool/cmd/shell"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+)
+
+// logsPrefixColors are the colors of the services prefixes, in the order
+// the services show up on the logs
+var logsPrefixColors = []color.Color{
+	color.FgCyan,
+	color.FgYellow,
+	color.FgGreen,
+	color.FgMagenta,
+	color.FgBlue,
+	color.FgLightCyan,
+	color.FgLightYellow,
+	color.FgLightGreen,
+	color.FgLightMagenta,
+	color.FgLightBlue,
+}
+
+// logsLevelColors are the colors of the structured logs levels
+var logsLevelColors = map[string]color.Color{
+	"DEBUG":     color.FgGray,
+	"INFO":      color.FgGreen,
+	"NOTICE":    color.FgCyan,
+	"WARNING":   color.FgYellow,
+	"ERROR":     color.FgRed,
+	"CRITICAL":  color.FgLightRed,
+	"ALERT":     color.FgLightRed,
+	"EMERGENCY": color.FgLightRed,
+}
+
+// logsFilter writes the docker-compose logs lines to the output writer,
+// filtering them by time and pattern, coloring the services prefixes
+// and, in JSON mode, the structured logs levels.
+type logsFilter struct {
+	out        shell.OutputWriter
+	since      time.Time
+	until      time.Time
+	grep       *regexp.Regexp
+	json       bool
+	timestamps bool
+
+	colors  map[string]color.Color
+	pending []byte
+}
+
+// newLogsFilter creates the logs filter for the logs flags; since
+// and until are either timestamps or durations relative to now.
+func newLogsFilter(out shell.OutputWriter, flags *KoolLogsFlags, now time.Time) (filter *logsFilter, err error) {
+	filter = &logsFilter{
+		out:        out,
+		json:       flags.JSON,
+		timestamps: flags.Timestamps,
+		colors:     make(map[string]color.Color),
+	}
+
+	if filter.since, err = parseLogsTime(flags.Since, now); err != nil {
+		err = fmt.Errorf("invalid --since value: %v", err)
+		return
+	}
+
+	if filter.until, err = parseLogsTime(flags.Until, now); err != nil {
+		err = fmt.Errorf("invalid --until value: %v", err)
+		return
+	}
+
+	if flags.Grep != "" {
+		if filter.grep, err = regexp.Compile(flags.Grep); err != nil {
+			err = fmt.Errorf("invalid --grep value: %v", err)
+		}
+	}
+
+	return
+}
+
+func parseLogsTime(value string, now time.Time) (parsed time.Time, err error) {
+	var duration time.Duration
+
+	if value == "" {
+		return
+	}
+
+	if duration, err = time.ParseDuration(value); err == nil {
+		parsed = now.Add(-duration)
+		return
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if parsed, err = time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return
+		}
+	}
+
+	err = fmt.Errorf("expected a duration like 10m or a timestamp like 2006-01-02T15:04:05, got %s", value)
+	return
+}
+
+// Write handles the complete lines written so far
+func (f *logsFilter) Write(p []byte) (n int, err error) {
+	f.pending = append(f.pending, p...)
+
+	for {
+		end := bytes.IndexByte(f.pending, '\n')
+
+		if end == -1 {
+			break
+		}
+
+		f.line(string(f.pending[:end]))
+		f.pending = f.pending[end+1:]
+	}
+
+	n = len(p)
+	return
+}
+
+// Flush handles the last line, when it's not terminated
+func (f *logsFilter) Flush() {
+	if len(f.pending) > 0 {
+		f.line(string(f.pending))
+		f.pending = nil
+	}
+}
+
+// line handles a docker-compose logs line, formatted as "service_1 | [timestamp] message"
+func (f *logsFilter) line(line string) {
+	var (
+		service, message string
+		timestamp        time.Time
+	)
+
+	line = strings.TrimRight(line, "\r")
+
+	if separator := strings.Index(line, "|"); separator != -1 {
+		service = strings.TrimSpace(line[:separator])
+		message = strings.TrimPrefix(line[separator+1:], " ")
+	} else {
+		message = line
+	}
+
+	if fields := strings.SplitN(message, " ", 2); service != "" && len(fields) == 2 {
+		if parsed, err := time.Parse(time.RFC3339Nano, fields[0]); err == nil {
+			timestamp = parsed
+			message = fields[1]
+		}
+	}
+
+	if !timestamp.IsZero() && (!f.since.IsZero() && timestamp.Before(f.since) || !f.until.IsZero() && timestamp.After(f.until)) {
+		return
+	}
+
+	if f.grep != nil && !f.grep.MatchString(message) {
+		return
+	}
+
+	if f.json {
+		message = formatStructuredLog(message)
+	}
+
+	if f.timestamps && !timestamp.IsZero() {
+		message = timestamp.Format(time.RFC3339) + " " + message
+	}
+
+	if service != "" {
+		message = f.prefixColor(service).Sprint(service, " |") + " " + message
+	}
+
+	f.out.Println(message)
+}
+
+func (f *logsFilter) prefixColor(service string) color.Color {
+	if _, ok := f.colors[service]; !ok {
+		f.colors[service] = logsPrefixColors[len(f.colors)%len(logsPrefixColors)]
+	}
+
+	return f.colors[service]
+}
+
+// formatStructuredLog formats a JSON log line from Laravel (Monolog) or
+// Node (pino, bunyan, winston) as its colored level and message,
+// returning other lines untouched.
+func formatStructuredLog(line string) string {
+	var (
+		entry   map[string]interface{}
+		message interface{}
+		level   string
+	)
+
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return line
+	}
+
+	if message = entry["message"]; message == nil {
+		message = entry["msg"]
+	}
+
+	if message == nil {
+		return line
+	}
+
+	switch value := entry["level_name"].(type) {
+	case string:
+		level = value
+	default:
+		level = structuredLogLevel(entry["level"])
+	}
+
+	if level = strings.ToUpper(level); level == "WARN" {
+		level = "WARNING"
+	} else if level == "FATAL" {
+		level = "CRITICAL"
+	}
+
+	if level == "" {
+		return fmt.Sprint(message)
+	}
+
+	if levelColor, ok := logsLevelColors[level]; ok {
+		level = levelColor.Sprint(level)
+	}
+
+	return fmt.Sprintf("%s %v", level, message)
+}
+
+// structuredLogLevel gets the level name of the string levels, like
+// winston ones, or the numeric levels of pino and bunyan.
+func structuredLogLevel(level interface{}) string {
+	switch value := level.(type) {
+	case string:
+		return value
+	case float64:
+		switch {
+		case value >= 60:
+			return "CRITICAL"
+		case value >= 50:
+			return "ERROR"
+		case value >= 40:
+			return "WARNING"
+		case value >= 30:
+			return "INFO"
+		default:
+			return "DEBUG"
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"kool-dev/kool/cmd/shell"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gookit/color"
+)
+
+func TestParseLogsTime(t *testing.T) {
+	now := time.Date(2021, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	for value, expected := range map[string]time.Time{
+		"":                     {},
+		"10m":                  now.Add(-10 * time.Minute),
+		"2021-01-02T14:00:00Z": time.Date(2021, 1, 2, 14, 0, 0, 0, time.UTC),
+		"2021-01-02T14:30:00":  time.Date(2021, 1, 2, 14, 30, 0, 0, time.UTC),
+		"2021-01-01":           time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		if parsed, err := parseLogsTime(value, now); err != nil || !parsed.Equal(expected) {
+			t.Errorf("unexpected time %v parsing %s (error: %v)", parsed, value, err)
+		}
+	}
+
+	if _, err := parseLogsTime("yesterday", now); err == nil {
+		t.Error("expecting an error parsing an invalid time")
+	}
+}
+
+func TestLogsFilterSinceUntil(t *testing.T) {
+	out := &shell.FakeOutputWriter{}
+	now := time.Date(2021, 1, 2, 15, 0, 0, 0, time.UTC)
+	filter, err := newLogsFilter(out, &KoolLogsFlags{Since: "1h", Until: "30m", Timestamps: true}, now)
+
+	if err != nil {
+		t.Fatalf("unexpected error creating the logs filter; error: %v", err)
+	}
+
+	_, _ = filter.Write([]byte("Attaching to app_1\napp_1  | 2021-01-02T13:30:00.000000000Z too old\napp_1  | 2021-01-02T14:15:00.000000000Z in"))
+	_, _ = filter.Write([]byte(" range\napp_1  | 2021-01-02T14:45:00.000000000Z too new\n"))
+	filter.Flush()
+
+	if len(out.OutLines) != 2 || out.OutLines[0] != "Attaching to app_1" || !strings.HasSuffix(out.OutLines[1], "2021-01-02T14:15:00Z in range") {
+		t.Errorf("unexpected filtered lines: %v", out.OutLines)
+	}
+}
+
+func TestLogsFilterPrefixColors(t *testing.T) {
+	filter, _ := newLogsFilter(&shell.FakeOutputWriter{}, &KoolLogsFlags{}, time.Now())
+
+	if filter.prefixColor("app_1") != filter.prefixColor("app_1") || filter.prefixColor("app_1") == filter.prefixColor("db_1") {
+		t.Error("expecting each service to keep its own prefix color")
+	}
+}
+
+func TestFormatStructuredLog(t *testing.T) {
+	for line, expected := range map[string]string{
+		`{"message":"User logged in","context":{},"level":200,"level_name":"INFO","channel":"local"}`: "INFO User logged in",
+		`{"level":50,"time":1609599600000,"msg":"connection refused"}`:                                "ERROR connection refused",
+		`{"level":"warn","message":"slow query"}`:                                                     "WARNING slow query",
+		`{"msg":"no level"}`: "no level",
+		`{"status":200}`:     `{"status":200}`,
+		`plain text line`:    "plain text line",
+	} {
+		if formatted := color.ClearCode(formatStructuredLog(line)); formatted != expected {
+			t.Errorf("unexpected formatted line %q for %s", formatted, line)
+		}
+	}
+}
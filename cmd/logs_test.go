@@ -3,26 +3,45 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/cmd/checker"
+	"kool-dev/kool/cmd/compose"
 	"kool-dev/kool/cmd/shell"
+	"kool-dev/kool/environment"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
 func newFakeKoolLogs() *KoolLogs {
 	return &KoolLogs{
 		*newFakeKoolService(),
-		&KoolLogsFlags{25, false},
+		&KoolLogsFlags{25, false, "", "", false, "", false, false},
 		&builder.FakeCommand{MockExecOut: "app"},
 		&builder.FakeCommand{},
+		environment.NewFakeEnvStorage(),
+		&compose.FakeParser{},
+		&checker.FakeChecker{},
+		&FakeKoolService{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
 	}
 }
 
 func newFakeFailedKoolLogs() *KoolLogs {
 	return &KoolLogs{
 		*newFakeKoolService(),
-		&KoolLogsFlags{25, false},
+		&KoolLogsFlags{25, false, "", "", false, "", false, false},
 		&builder.FakeCommand{MockExecOut: "app"},
 		&builder.FakeCommand{MockError: errors.New("error logs")},
+		environment.NewFakeEnvStorage(),
+		&compose.FakeParser{},
+		&checker.FakeChecker{},
+		&FakeKoolService{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
 	}
 }
 
@@ -144,6 +163,71 @@ func TestNewLogsFollowCommand(t *testing.T) {
 	}
 }
 
+func TestNewLogsTimestampsCommand(t *testing.T) {
+	f := newFakeKoolLogs()
+	cmd := NewLogsCommand(f)
+
+	cmd.SetArgs([]string{"--timestamps"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing logs command; error: %v", err)
+	}
+
+	if argsAppend := f.logs.(*builder.FakeCommand).ArgsAppend; len(argsAppend) != 3 || argsAppend[2] != "--timestamps" {
+		t.Errorf("bad arguments to KoolLogs.logs Command when passing --timestamps flag: %v", argsAppend)
+	}
+
+	if !f.logs.(*builder.FakeCommand).CalledInteractive {
+		t.Error("expecting the logs to be shown by docker-compose when not filtering them")
+	}
+}
+
+func TestNewLogsGrepCommand(t *testing.T) {
+	f := newFakeKoolLogs()
+	f.logs.(*builder.FakeCommand).MockStreamOut = "app_1  | 2021-01-02T15:04:05.000000000Z GET /users 200\n" +
+		"app_1  | 2021-01-02T15:04:06.000000000Z POST /users 500\n" +
+		"db_1   | 2021-01-02T15:04:07.000000000Z POST received"
+	cmd := NewLogsCommand(f)
+
+	cmd.SetArgs([]string{"--grep", "^POST", "app", "db"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing logs command; error: %v", err)
+	}
+
+	if args := f.logs.(*builder.FakeCommand).ArgsStream; len(args) != 2 || args[0] != "app" || args[1] != "db" {
+		t.Errorf("bad arguments to KoolLogs.logs Command when streaming it: %v", args)
+	}
+
+	if argsAppend := f.logs.(*builder.FakeCommand).ArgsAppend; len(argsAppend) != 4 || argsAppend[2] != "--no-color" || argsAppend[3] != "--timestamps" {
+		t.Errorf("bad arguments to KoolLogs.logs Command when filtering: %v", argsAppend)
+	}
+
+	lines := f.out.(*shell.FakeOutputWriter).OutLines
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "POST /users 500") || !strings.HasSuffix(lines[1], "POST received") {
+		t.Errorf("unexpected filtered logs lines: %v", lines)
+	}
+}
+
+func TestNewLogsInvalidGrepCommand(t *testing.T) {
+	f := newFakeKoolLogs()
+	cmd := NewLogsCommand(f)
+
+	cmd.SetArgs([]string{"--grep", "("})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing logs command; error: %v", err)
+	}
+
+	if !f.out.(*shell.FakeOutputWriter).CalledError {
+		t.Error("expecting an error for an invalid --grep expression")
+	}
+
+	if f.logs.(*builder.FakeCommand).CalledStream {
+		t.Error("unexpected logs streaming with an invalid --grep expression")
+	}
+}
+
 func TestNewLogsServiceCommand(t *testing.T) {
 	f := newFakeKoolLogs()
 	cmd := NewLogsCommand(f)
@@ -220,3 +304,25 @@ func TestFailingNoContainersNewLogsCommand(t *testing.T) {
 		t.Errorf("expecting error 'error logs', got '%s'", err.Error())
 	}
 }
+
+func TestLogsCompletion(t *testing.T) {
+	wd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(wd) }()
+
+	tmp := t.TempDir()
+	_ = ioutil.WriteFile(filepath.Join(tmp, "docker-compose.yml"), []byte("services: {}\n"), os.ModePerm)
+	_ = os.Chdir(tmp)
+
+	f := newFakeKoolLogs()
+	f.composeParser.(*compose.FakeParser).MockServices = []string{"app", "database"}
+
+	cmd := NewLogsCommand(f)
+
+	if services, _ := cmd.ValidArgsFunction(cmd, []string{"app"}, ""); len(services) != 1 || services[0] != "database" {
+		t.Errorf("unexpected services completion %v", services)
+	}
+
+	if !f.composeParser.(*compose.FakeParser).CalledLoad["services: {}\n"] {
+		t.Error("did not parse the project docker-compose.yml")
+	}
+}
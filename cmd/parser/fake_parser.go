@@ -15,6 +15,9 @@ type FakeParser struct {
 	MockParseError                 error
 	MockScripts                    []string
 	MockParseAvailableScriptsError error
+	CalledWatch                    bool
+	MockWatch                      []string
+	MockWatchError                 error
 }
 
 // AddLookupPath implements fake AddLookupPath behavior
@@ -49,3 +52,11 @@ func (f *FakeParser) ParseAvailableScripts(filter string) (scripts []string, err
 	err = f.MockParseAvailableScriptsError
 	return
 }
+
+// Watch implements fake Watch behavior
+func (f *FakeParser) Watch(script string) (patterns []string, err error) {
+	f.CalledWatch = true
+	patterns = f.MockWatch
+	err = f.MockWatchError
+	return
+}
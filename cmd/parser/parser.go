@@ -14,6 +14,7 @@ type Parser interface {
 	AddLookupPath(string) error
 	Parse(string) ([]builder.Command, error)
 	ParseAvailableScripts(string) ([]string, error)
+	Watch(string) ([]string, error)
 }
 
 // DefaultParser implements all default behavior for using kool.yml files.
@@ -97,6 +98,25 @@ func (p *DefaultParser) Parse(script string) (commands []builder.Command, err er
 	return
 }
 
+// Watch gets the file patterns watched for re-running the given script,
+// from the first of the kool.yml files defining it.
+func (p *DefaultParser) Watch(script string) (patterns []string, err error) {
+	var parsedFile *KoolYaml
+
+	for _, koolFile := range p.targetFiles {
+		if parsedFile, err = ParseKoolYaml(koolFile); err != nil {
+			return
+		}
+
+		if parsedFile.HasScript(script) {
+			patterns = parsedFile.WatchPatterns(script)
+			return
+		}
+	}
+
+	return
+}
+
 // ParseAvailableScripts parse all available scripts
 func (p *DefaultParser) ParseAvailableScripts(filter string) (scripts []string, err error) {
 	var (
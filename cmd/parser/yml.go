@@ -1,17 +1,26 @@
 package parser
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"kool-dev/kool/cmd/builder"
 	"os"
+	"strings"
 
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
 )
 
 // KoolYaml holds the structure for parsing the custom commands file
 type KoolYaml struct {
 	Scripts map[string]interface{} `yaml:"scripts"`
+	Start   StartOptions           `yaml:"start"`
+}
+
+// StartOptions holds the project defaults for kool start
+type StartOptions struct {
+	Wait    bool `yaml:"wait"`
+	Timeout int  `yaml:"timeout"`
 }
 
 // ParseKoolYaml decodes the target kool.yml onto its
@@ -50,29 +59,265 @@ func (y *KoolYaml) HasScript(script string) (has bool) {
 // of commands parsed.
 func (y *KoolYaml) ParseCommands(script string) (commands []builder.Command, err error) {
 	var (
-		isSingle bool
-		isList   bool
-		line     string
-		lines    []interface{}
-		command  *builder.DefaultCommand
+		lines   []string
+		command *builder.DefaultCommand
 	)
 
-	if line, isSingle = y.Scripts[script].(string); isSingle {
+	if lines, err = scriptLines(y.Scripts[script]); err != nil {
+		err = fmt.Errorf("failed parsing script '%s': %v", script, err)
+		return
+	}
+
+	for _, line := range lines {
 		if command, err = builder.ParseCommand(line); err != nil {
 			return
 		}
 
 		commands = append(commands, command)
-	} else if lines, isList = y.Scripts[script].([]interface{}); isList {
-		for _, i := range lines {
-			if command, err = builder.ParseCommand(i.(string)); err != nil {
+	}
+	return
+}
+
+// WatchPatterns gets the patterns of the files watched for re-running
+// the script, declared by the watch key of scripts in the mapping form.
+func (y *KoolYaml) WatchPatterns(script string) (patterns []string) {
+	mapping, isMapping := y.Scripts[script].(map[string]interface{})
+
+	if !isMapping {
+		return
+	}
+
+	switch watch := mapping["watch"].(type) {
+	case string:
+		patterns = append(patterns, watch)
+	case []interface{}:
+		for _, pattern := range watch {
+			patterns = append(patterns, fmt.Sprint(pattern))
+		}
+	}
+
+	return
+}
+
+// scriptLines gets the lines of a script, either a single line, a list
+// of lines or a mapping holding them under the run key.
+func scriptLines(script interface{}) (lines []string, err error) {
+	switch value := script.(type) {
+	case string:
+		lines = append(lines, value)
+	case []interface{}:
+		for _, line := range value {
+			if _, isString := line.(string); !isString {
+				err = fmt.Errorf("expected string or array of strings")
 				return
 			}
 
-			commands = append(commands, command)
+			lines = append(lines, line.(string))
+		}
+	case map[string]interface{}:
+		if _, hasRun := value["run"]; !hasRun {
+			err = fmt.Errorf("missing the run key")
+			return
+		}
+
+		lines, err = scriptLines(value["run"])
+	default:
+		err = fmt.Errorf("expected string or array of strings")
+	}
+
+	return
+}
+
+// MergeKoolYaml adds the scripts of the other kool.yml content missing
+// from the local one, keeping the existing scripts untouched and in
+// order. The added scripts are appended to the text of the local scripts
+// so its comments and blank lines are kept; only flow styled mappings
+// (e.g "scripts: {}") get the whole content re-encoded. It returns the
+// names of the added scripts; when none is added the local content is
+// returned as is.
+func MergeKoolYaml(local string, other string) (merged string, added []string, err error) {
+	var (
+		localYaml, otherYaml       yaml.Node
+		localRoot, otherRoot       *yaml.Node
+		localScripts, otherScripts *yaml.Node
+		addedScripts               = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		scripts                    string
+	)
+
+	if err = yaml.Unmarshal([]byte(local), &localYaml); err != nil {
+		return
+	}
+
+	if err = yaml.Unmarshal([]byte(other), &otherYaml); err != nil {
+		return
+	}
+
+	if localRoot, err = documentMapping(&localYaml); err != nil {
+		return
+	}
+
+	if otherRoot, err = documentMapping(&otherYaml); err != nil {
+		return
+	}
+
+	if otherScripts = mappingValue(otherRoot, "scripts"); otherScripts == nil || otherScripts.Kind != yaml.MappingNode {
+		merged = local
+		return
+	}
+
+	// an empty scripts key (e.g "scripts:") holds a null scalar
+	if localScripts = mappingValue(localRoot, "scripts"); localScripts != nil && localScripts.Kind != yaml.MappingNode && localScripts.Tag != "!!null" {
+		err = fmt.Errorf("scripts must be a mapping of script names to commands")
+		return
+	}
+
+	for i := 0; i+1 < len(otherScripts.Content); i += 2 {
+		if localScripts == nil || mappingValue(localScripts, otherScripts.Content[i].Value) == nil {
+			addedScripts.Content = append(addedScripts.Content, otherScripts.Content[i], otherScripts.Content[i+1])
+			added = append(added, otherScripts.Content[i].Value)
+		}
+	}
+
+	if len(added) == 0 {
+		merged = local
+		return
+	}
+
+	if !canAppendScripts(localRoot, localScripts) {
+		if localScripts == nil {
+			localScripts = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			localRoot.Content = append(localRoot.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "scripts"}, localScripts)
+		}
+
+		localScripts.Kind, localScripts.Tag, localScripts.Value = yaml.MappingNode, "!!map", ""
+		localScripts.Content = append(localScripts.Content, addedScripts.Content...)
+
+		merged, err = encodeYaml(&localYaml)
+		return
+	}
+
+	if scripts, err = encodeYaml(addedScripts); err != nil {
+		return
+	}
+
+	if local != "" && !strings.HasSuffix(local, "\n") {
+		local += "\n"
+	}
+
+	if localScripts == nil {
+		merged = local + "scripts:\n" + indentLines(scripts, "  ")
+		return
+	}
+
+	var (
+		lines    = strings.SplitAfter(local, "\n")
+		keyIndex = mappingKeyIndex(localRoot, "scripts")
+		keyLine  = localRoot.Content[keyIndex].Line
+		indent   = strings.Repeat(" ", localRoot.Content[keyIndex].Column+1)
+		end      = keyLine
+	)
+
+	if localScripts.Kind == yaml.MappingNode {
+		indent = strings.Repeat(" ", localScripts.Content[0].Column-1)
+
+		// the scripts go up to the next root key, leaving out the
+		// trailing blank lines and the comments heading that key
+		end = len(lines) - 1
+
+		if keyIndex+2 < len(localRoot.Content) {
+			end = localRoot.Content[keyIndex+2].Line - 1
+		}
+
+		for end > keyLine && (strings.TrimSpace(lines[end-1]) == "" || strings.HasPrefix(lines[end-1], "#")) {
+			end--
+		}
+	}
+
+	merged = strings.Join(lines[:end], "") + indentLines(scripts, indent) + strings.Join(lines[end:], "")
+	return
+}
+
+// canAppendScripts tells whether scripts can be added to the text
+// of the local kool.yml, which holds either a block styled mapping
+// of scripts, an empty scripts key or no scripts at all.
+func canAppendScripts(root *yaml.Node, scripts *yaml.Node) bool {
+	if root.Style&yaml.FlowStyle != 0 {
+		return false
+	}
+
+	if scripts == nil {
+		return true
+	}
+
+	if scripts.Kind != yaml.MappingNode {
+		return scripts.Value == ""
+	}
+
+	return scripts.Style&yaml.FlowStyle == 0
+}
+
+func encodeYaml(node *yaml.Node) (content string, err error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err = encoder.Encode(node); err != nil {
+		return
+	}
+
+	if err = encoder.Close(); err != nil {
+		return
+	}
+
+	content = buf.String()
+	return
+}
+
+func indentLines(content string, indent string) string {
+	lines := strings.SplitAfter(content, "\n")
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = indent + line
 		}
-	} else {
-		err = fmt.Errorf("failed parsing script '%s': expected string or array of strings", script)
 	}
+
+	return strings.Join(lines, "")
+}
+
+// documentMapping returns the mapping held by the document node,
+// creating it in case the document is empty.
+func documentMapping(document *yaml.Node) (mapping *yaml.Node, err error) {
+	if document.Kind != yaml.DocumentNode {
+		document.Kind = yaml.DocumentNode
+	}
+
+	if len(document.Content) == 0 {
+		document.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+
+	if mapping = document.Content[0]; mapping.Kind != yaml.MappingNode {
+		err = fmt.Errorf("expected a mapping of kool.yml keys")
+	}
+
 	return
 }
+
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if i := mappingKeyIndex(mapping, key); i >= 0 {
+		return mapping.Content[i+1]
+	}
+
+	return nil
+}
+
+func mappingKeyIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+
+	return -1
+}
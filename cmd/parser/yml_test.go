@@ -68,3 +68,201 @@ func TestParseKoolYaml(t *testing.T) {
 		return
 	}
 }
+
+func TestParseKoolYamlStartOptions(t *testing.T) {
+	tmpPath := path.Join(t.TempDir(), "kool.yml")
+
+	if err := ioutil.WriteFile(tmpPath, []byte(KoolYmlOK+"start:\n  wait: true\n  timeout: 30\n"), os.ModePerm); err != nil {
+		t.Fatal("failed creating temporary file for test", err)
+	}
+
+	parsed, err := ParseKoolYaml(tmpPath)
+
+	if err != nil {
+		t.Fatalf("failed parsing kool.yml file; error: %s", err)
+	}
+
+	if !parsed.Start.Wait || parsed.Start.Timeout != 30 {
+		t.Errorf("unexpected start options %v", parsed.Start)
+	}
+}
+
+func TestParseKoolYamlWatchScript(t *testing.T) {
+	tmpPath := path.Join(t.TempDir(), "kool.yml")
+	content := KoolYmlOK + `  test:
+    run:
+      - line 1
+      - line 2
+    watch: ["**/*.go", go.mod]
+  broken:
+    watch: "*.go"
+`
+
+	if err := ioutil.WriteFile(tmpPath, []byte(content), os.ModePerm); err != nil {
+		t.Fatal("failed creating temporary file for test", err)
+	}
+
+	parsed, err := ParseKoolYaml(tmpPath)
+
+	if err != nil {
+		t.Fatalf("failed parsing kool.yml file; error: %s", err)
+	}
+
+	if cmds, err := parsed.ParseCommands("test"); err != nil || len(cmds) != 2 {
+		t.Errorf("expected test to parse 2 commands; got %d (error: %v)", len(cmds), err)
+	}
+
+	if patterns := parsed.WatchPatterns("test"); len(patterns) != 2 || patterns[0] != "**/*.go" || patterns[1] != "go.mod" {
+		t.Errorf("unexpected watch patterns %v", patterns)
+	}
+
+	if patterns := parsed.WatchPatterns("single-line"); len(patterns) != 0 {
+		t.Errorf("unexpected watch patterns for single-line script %v", patterns)
+	}
+
+	if _, err := parsed.ParseCommands("broken"); err == nil || err.Error() != "failed parsing script 'broken': missing the run key" {
+		t.Errorf("expecting missing run key error, got %v", err)
+	}
+}
+
+func TestMergeKoolYaml(t *testing.T) {
+	local := `scripts:
+  setup: my own setup
+  test: phpunit
+`
+
+	merged, added, err := MergeKoolYaml(local, `scripts:
+  setup: kool start
+  artisan: kool exec app php artisan
+  npm: kool docker kooldev/node:14 npm
+`)
+
+	if err != nil {
+		t.Fatalf("unexpected error merging kool.yml: %v", err)
+	}
+
+	if len(added) != 2 || added[0] != "artisan" || added[1] != "npm" {
+		t.Errorf("unexpected added scripts %v", added)
+	}
+
+	expected := `scripts:
+  setup: my own setup
+  test: phpunit
+  artisan: kool exec app php artisan
+  npm: kool docker kooldev/node:14 npm
+`
+
+	if merged != expected {
+		t.Errorf("expected merged kool.yml:\n%s\ngot:\n%s", expected, merged)
+	}
+
+	if merged, added, _ = MergeKoolYaml("# no changes\n"+local, "scripts:\n  test: kool run phpunit\n"); len(added) != 0 || merged != "# no changes\n"+local {
+		t.Errorf("expected local kool.yml kept as is; got %v added:\n%s", added, merged)
+	}
+
+	if merged, _, _ = MergeKoolYaml("", "scripts:\n  setup: kool start\n"); merged != "scripts:\n  setup: kool start\n" {
+		t.Errorf("unexpected merge into empty kool.yml:\n%s", merged)
+	}
+
+	if _, _, err = MergeKoolYaml("scripts: [", KoolYmlOK); err == nil {
+		t.Error("expected error merging invalid kool.yml")
+	}
+}
+
+func TestMergeKoolYamlKeepsComments(t *testing.T) {
+	local := `# project scripts
+scripts:
+  # runs the tests
+  test: phpunit # possibly change to: pest
+  setup:
+    - kool start
+    - kool run test
+`
+
+	merged, _, err := MergeKoolYaml(local, "scripts:\n  artisan: kool exec app php artisan\n")
+
+	if err != nil {
+		t.Fatalf("unexpected error merging kool.yml: %v", err)
+	}
+
+	expected := local + "  artisan: kool exec app php artisan\n"
+
+	if merged != expected {
+		t.Errorf("expected merged kool.yml:\n%s\ngot:\n%s", expected, merged)
+	}
+
+	if merged, _, _ = MergeKoolYaml("scripts:\n", "scripts:\n  setup: kool start\n"); merged != "scripts:\n  setup: kool start\n" {
+		t.Errorf("unexpected merge into empty scripts:\n%s", merged)
+	}
+
+	if _, _, err = MergeKoolYaml("scripts: [kool start]", "scripts:\n  setup: kool start\n"); err == nil {
+		t.Error("expected error merging into a non-mapping scripts key")
+	}
+}
+
+func TestMergeKoolYamlKeepsBlankLines(t *testing.T) {
+	local := `scripts:
+  composer: kool exec app composer
+  artisan: kool exec app php artisan
+
+  # npm - node package manager
+  npm: kool docker kooldev/node:14 npm
+  npx: kool exec app npx
+
+  setup:
+    - kool start
+    - kool run composer install
+
+# project defaults for kool start
+start:
+  wait: true
+`
+
+	merged, added, err := MergeKoolYaml(local, "scripts:\n  redis-cli: kool exec cache redis-cli\n")
+
+	if err != nil {
+		t.Fatalf("unexpected error merging kool.yml: %v", err)
+	}
+
+	if len(added) != 1 || added[0] != "redis-cli" {
+		t.Errorf("unexpected added scripts %v", added)
+	}
+
+	expected := `scripts:
+  composer: kool exec app composer
+  artisan: kool exec app php artisan
+
+  # npm - node package manager
+  npm: kool docker kooldev/node:14 npm
+  npx: kool exec app npx
+
+  setup:
+    - kool start
+    - kool run composer install
+  redis-cli: kool exec cache redis-cli
+
+# project defaults for kool start
+start:
+  wait: true
+`
+
+	if merged != expected {
+		t.Errorf("expected merged kool.yml:\n%s\ngot:\n%s", expected, merged)
+	}
+
+	if merged, _, _ = MergeKoolYaml("start:\n  wait: true\n\nscripts:\n    test: phpunit\n\n", "scripts:\n  setup:\n    - kool start\n"); merged != "start:\n  wait: true\n\nscripts:\n    test: phpunit\n    setup:\n      - kool start\n\n" {
+		t.Errorf("unexpected merge into scripts at the end of kool.yml:\n%s", merged)
+	}
+
+	if merged, _, _ = MergeKoolYaml("scripts:\n\nstart:\n  wait: true", "scripts:\n  setup: kool start\n"); merged != "scripts:\n  setup: kool start\n\nstart:\n  wait: true\n" {
+		t.Errorf("unexpected merge into empty scripts:\n%s", merged)
+	}
+
+	if merged, _, _ = MergeKoolYaml("start:\n  wait: true\n", "scripts:\n  setup: kool start\n"); merged != "start:\n  wait: true\nscripts:\n  setup: kool start\n" {
+		t.Errorf("unexpected merge into kool.yml without scripts:\n%s", merged)
+	}
+
+	if merged, _, _ = MergeKoolYaml("scripts: {test: phpunit}\n", "scripts:\n  setup: kool start\n"); merged != "scripts: {test: phpunit, setup: kool start}\n" {
+		t.Errorf("unexpected merge into flow styled scripts:\n%s", merged)
+	}
+}
@@ -4,36 +4,61 @@ import (
 	"errors"
 	"fmt"
 	"kool-dev/kool/cmd/compose"
+	"kool-dev/kool/cmd/parser"
 	"kool-dev/kool/cmd/presets"
 	"kool-dev/kool/cmd/shell"
+	"kool-dev/kool/environment"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // KoolPresetFlags holds the flags for the preset command
 type KoolPresetFlags struct {
-	Override bool
+	Override  bool
+	Merge     bool
+	Variables []string
+	Services  map[string]string
+	Answers   string
+}
+
+// presetServiceFlag is the value of the flags picking the option
+// of the preset service questions, e.g --database=postgresql130
+type presetServiceFlag struct {
+	services map[string]string
+	service  string
 }
 
 // KoolPreset holds handlers and functions to implement the preset command logic
 type KoolPreset struct {
 	DefaultKoolService
 	Flags         *KoolPresetFlags
+	envStorage    environment.EnvStorage
 	presetsParser presets.Parser
 	composeParser compose.Parser
 	promptSelect  shell.PromptSelect
+	promptInput   shell.PromptInput
 }
 
 // ErrPresetFilesAlreadyExists error for existing presets files
 var ErrPresetFilesAlreadyExists = errors.New("some preset files already exist")
 
+// ErrPresetLockNotFound error for projects without preset lock file
+var ErrPresetLockNotFound = errors.New("no kool-preset.lock found; it's recorded by initializing the project with kool preset")
+
 func init() {
 	var (
 		preset    = NewKoolPreset()
 		presetCmd = NewPresetCommand(preset)
 	)
 
+	presetCmd.AddCommand(NewPresetAddCommand(NewKoolPresetAdd()))
+	presetCmd.AddCommand(NewPresetDiffCommand(NewKoolPresetDiff()))
+	presetCmd.AddCommand(NewPresetUpgradeCommand(NewKoolPresetUpgrade()))
 	rootCmd.AddCommand(presetCmd)
 }
 
@@ -41,69 +66,107 @@ func init() {
 func NewKoolPreset() *KoolPreset {
 	return &KoolPreset{
 		*newDefaultKoolService(),
-		&KoolPresetFlags{false},
-		&presets.DefaultParser{},
+		&KoolPresetFlags{false, false, []string{}, map[string]string{}, ""},
+		environment.NewEnvStorage(),
+		presets.NewParser(),
 		compose.NewParser(),
 		shell.NewPromptSelect(),
+		shell.NewPromptInput(),
 	}
 }
 
 // Execute runs the preset logic with incoming arguments.
 func (p *KoolPreset) Execute(args []string) (err error) {
+	var manifest *presets.Manifest
+
+	if manifest, err = p.initialize(args); err != nil {
+		return
+	}
+
+	p.printPostInstall(manifest)
+	return
+}
+
+// printPostInstall tells the steps to finish setting up the project
+func (p *KoolPreset) printPostInstall(manifest *presets.Manifest) {
+	if len(manifest.PostInstall) > 0 {
+		p.Println("To finish setting up your project, run:")
+
+		for _, step := range manifest.PostInstall {
+			p.Println(" ", step)
+		}
+	}
+}
+
+// initialize writes the preset files onto the current working
+// directory, returning the manifest of the chosen preset.
+func (p *KoolPreset) initialize(args []string) (manifest *presets.Manifest, err error) {
 	var (
-		fileError, preset, language string
-		useDefaultCompose           bool
-		servicesOptions             map[string]string
+		preset, language string
+		answers          *presets.Answers
+		sets, values     map[string]string
+		files            map[string]string
+		services         map[string]string
 	)
 
-	if len(args) == 0 {
+	if p.Flags.Override && p.Flags.Merge {
+		err = fmt.Errorf("--override and --merge can't be used together")
+		return
+	}
+
+	if err = loadAllPresets(p, p.presetsParser, p.envStorage); err != nil {
+		return
+	}
+
+	if answers, err = p.readAnswers(); err != nil {
+		return
+	}
+
+	if preset = answers.Preset; len(args) > 0 {
+		preset = args[0]
+	}
+
+	if preset == "" {
 		if !p.IsTerminal() {
 			err = fmt.Errorf("the input device is not a TTY; for non-tty environments, please specify a preset argument")
 			return
 		}
 
-		if language, err = p.promptSelect.Ask("What language do you want to use", p.presetsParser.GetLanguages()); err != nil {
-			return
+		if language = answers.Language; language == "" {
+			if language, err = p.promptSelect.Ask("What language do you want to use", p.presetsParser.GetLanguages()); err != nil {
+				return
+			}
 		}
 
 		if preset, err = p.promptSelect.Ask("What preset do you want to use", p.presetsParser.GetPresets(language)); err != nil {
 			return
 		}
-	} else {
-		preset = args[0]
 	}
 
-	p.presetsParser.LoadPresets(presets.GetAll())
-
 	if !p.presetsParser.Exists(preset) {
 		err = fmt.Errorf("Unknown preset %s", preset)
 		return
 	}
 
-	servicesOptions = make(map[string]string)
-	useDefaultCompose = true
-
-	if servicesToAskStr := p.presetsParser.GetPresetKeyContent(preset, "preset_ask_services"); servicesToAskStr != "" && p.IsTerminal() {
-		servicesToAsk := strings.Split(servicesToAskStr, ",")
+	if manifest, err = p.presetsParser.GetManifest(preset); err != nil {
+		return
+	}
 
-		for _, serviceName := range servicesToAsk {
-			optionsKey := fmt.Sprintf("preset_%s_options", serviceName)
-			question := fmt.Sprintf("What %s service do you want to use", serviceName)
+	if services, err = p.presetServices(preset, manifest, answers.Services); err != nil {
+		return
+	}
 
-			if optionsStr := p.presetsParser.GetPresetKeyContent(preset, optionsKey); optionsStr != "" {
-				options := strings.Split(optionsStr, ",")
+	if sets, err = p.presetSets(answers); err != nil {
+		return
+	}
 
-				if servicesOptions[serviceName], err = p.promptSelect.Ask(question, options); err != nil {
-					return
-				}
-				useDefaultCompose = false
-			}
-		}
+	if values, err = p.presetVariables(preset, manifest, sets); err != nil {
+		return
 	}
 
 	p.Println("Preset", preset, "is initializing!")
 
-	if !p.Flags.Override {
+	if !p.Flags.Override && !p.Flags.Merge {
 		existingFiles := p.presetsParser.LookUpFiles(preset)
 		for _, fileName := range existingFiles {
 			p.Warning("Preset file ", fileName, " already exists.")
@@ -115,55 +178,266 @@ func (p *KoolPreset) Execute(args []string) (err error) {
 		}
 	}
 
-	presetKeys := p.presetsParser.GetPresetKeys(preset)
+	if files, err = p.renderPreset(preset, manifest, services, values); err != nil {
+		return
+	}
 
-	templates := p.presetsParser.GetTemplates()
+	for _, fileName := range sortedKeys(files) {
+		var (
+			content = files[fileName]
+			keep    bool
+		)
+
+		if p.Flags.Merge {
+			if content, keep, err = p.mergeFile(fileName, content); err != nil {
+				return
+			}
+		}
 
-	for _, presetKey := range presetKeys {
-		if strings.HasPrefix(presetKey, "preset_") {
+		if keep {
+			continue
+		}
+
+		if err = p.writeFile(fileName, content); err != nil {
+			return
+		}
+	}
+
+	if err = p.writeLock(&presets.Lock{
+		Preset:    preset,
+		Services:  services,
+		Variables: values,
+		Files:     files,
+	}); err != nil {
+		return
+	}
+
+	p.Success("Preset ", preset, " initialized!")
+	return
+}
+
+// renderPreset renders the preset files, with the chosen templates
+// for the docker-compose.yml services and the variables values.
+func (p *KoolPreset) renderPreset(preset string, manifest *presets.Manifest, services map[string]string, values map[string]string) (files map[string]string, err error) {
+	files = make(map[string]string)
+
+	for _, presetKey := range p.presetsParser.GetPresetKeys(preset) {
+		if presetKey == presets.PresetManifestFile {
 			continue
 		}
 
 		var content string
 
-		if presetKey == "docker-compose.yml" && !useDefaultCompose {
-			defaultCompose := p.presetsParser.GetPresetKeyContent(preset, presetKey)
+		if content, err = p.renderFile(preset, presetKey, values); err != nil {
+			err = fmt.Errorf("Failed to render preset file %s: %v", presetKey, err)
+			return
+		}
 
-			if err = p.composeParser.Load(defaultCompose); err != nil {
+		if presetKey == "docker-compose.yml" && len(services) > 0 {
+			if content, err = p.composeServices(manifest, content, services); err != nil {
 				err = fmt.Errorf("Failed to write preset file %s: %v", presetKey, err)
 				return
 			}
+		}
 
-			for serviceKey, serviceOption := range servicesOptions {
-				if serviceOption == "none" {
-					p.composeParser.RemoveService(serviceKey)
-					p.composeParser.RemoveVolume(serviceKey)
-				} else {
-					key := formatTemplateKey(serviceOption)
-					service := templates[serviceKey][key]
+		files[presetKey] = content
+	}
 
-					if err = p.composeParser.SetService(serviceKey, service); err != nil {
-						err = fmt.Errorf("Failed to write preset file %s: %v", presetKey, err)
-						return
-					}
-				}
-			}
+	return
+}
 
-			if content, err = p.composeParser.String(); err != nil {
-				err = fmt.Errorf("Failed to write preset file %s: %v", presetKey, err)
-				return
-			}
-		} else {
-			content = p.presetsParser.GetPresetKeyContent(preset, presetKey)
+// composeServices replaces the docker-compose.yml services
+// by the templates of the chosen options.
+func (p *KoolPreset) composeServices(manifest *presets.Manifest, compose string, services map[string]string) (content string, err error) {
+	templates := p.presetsParser.GetTemplates()
+
+	if err = p.composeParser.Load(compose); err != nil {
+		return
+	}
+
+	for serviceKey, optionName := range services {
+		var (
+			option   *presets.Option
+			template *presets.Template
+			service  string
+			question = manifest.Question(serviceKey)
+		)
+
+		if question != nil {
+			option = question.Option(optionName)
+		}
+
+		if option == nil {
+			err = fmt.Errorf("unknown option %s for %s service", optionName, serviceKey)
+			return
+		}
+
+		if option.Template == "" {
+			p.composeParser.RemoveService(serviceKey)
+			p.composeParser.RemoveVolume(serviceKey)
+			continue
+		}
+
+		if service, err = presets.FindTemplate(templates, option.Template); err != nil {
+			return
 		}
 
-		if fileError, err = p.presetsParser.WriteFile(presetKey, content); err != nil {
-			err = fmt.Errorf("Failed to write preset file %s: %v", fileError, err)
+		if template, err = presets.ParseTemplate(service); err != nil {
+			err = fmt.Errorf("template %s: %v", option.Template, err)
+			return
+		}
+
+		if service, err = template.ServiceYaml(); err != nil {
+			return
+		}
+
+		if err = p.composeParser.SetService(serviceKey, service); err != nil {
 			return
 		}
 	}
 
-	p.Success("Preset ", preset, " initialized!")
+	content, err = p.composeParser.String()
+	return
+}
+
+// mergeFile merges the preset file content into the existing project
+// file; kool.yml gets the missing scripts and docker-compose.yml the
+// missing services, volumes and networks. Other existing files are
+// kept as they are.
+func (p *KoolPreset) mergeFile(fileName string, content string) (merged string, keep bool, err error) {
+	var (
+		local string
+		added []string
+	)
+
+	if local, err = p.presetsParser.ReadFile(fileName); os.IsNotExist(err) {
+		merged, err = content, nil
+		return
+	} else if err != nil {
+		return
+	}
+
+	switch fileName {
+	case "kool.yml":
+		merged, added, err = parser.MergeKoolYaml(local, content)
+	case "docker-compose.yml":
+		if err = p.composeParser.Load(local); err != nil {
+			break
+		}
+
+		if added, err = p.composeParser.Merge(content); err != nil || len(added) == 0 {
+			break
+		}
+
+		merged, err = p.composeParser.String()
+	}
+
+	if err != nil {
+		err = fmt.Errorf("Failed to merge preset file %s: %v", fileName, err)
+		return
+	}
+
+	if keep = len(added) == 0; keep {
+		p.Println("Keeping existing", fileName)
+	} else {
+		p.Println("Merging into", fileName+":", strings.Join(added, ", "))
+	}
+
+	return
+}
+
+func (p *KoolPreset) writeFile(fileName string, content string) (err error) {
+	var fileError string
+
+	if fileError, err = p.presetsParser.WriteFile(fileName, content); err != nil {
+		err = fmt.Errorf("Failed to write preset file %s: %v", fileError, err)
+	}
+
+	return
+}
+
+// readLock reads the project preset lock file, returning
+// nil in case the project has none.
+func (p *KoolPreset) readLock() (lock *presets.Lock, err error) {
+	var content string
+
+	if content, err = p.presetsParser.ReadFile(presets.PresetLockFile); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+
+	lock, err = presets.ParseLock(content)
+	return
+}
+
+// renderFromLock renders the current version of the project preset
+// with the answers recorded in its lock file; for projects without
+// lock file, the preset can be given as argument.
+func (p *KoolPreset) renderFromLock(args []string) (lock *presets.Lock, files map[string]string, err error) {
+	var (
+		manifest *presets.Manifest
+		sets     = make(map[string]string)
+		services = make(map[string]string)
+	)
+
+	if err = loadAllPresets(p, p.presetsParser, p.envStorage); err != nil {
+		return
+	}
+
+	if lock, err = p.readLock(); err != nil {
+		return
+	}
+
+	if len(args) > 0 && (lock == nil || lock.Preset != args[0]) {
+		lock = &presets.Lock{Preset: args[0]}
+	}
+
+	if lock == nil {
+		err = ErrPresetLockNotFound
+		return
+	}
+
+	if !p.presetsParser.Exists(lock.Preset) {
+		err = fmt.Errorf("Unknown preset %s", lock.Preset)
+		return
+	}
+
+	if manifest, err = p.presetsParser.GetManifest(lock.Preset); err != nil {
+		return
+	}
+
+	// answers for questions and variables the preset no longer has are left out
+	for name, value := range lock.Variables {
+		if manifest.Variable(name) != nil {
+			sets[name] = value
+		}
+	}
+
+	for service, option := range lock.Services {
+		if manifest.Question(service) != nil {
+			services[service] = option
+		}
+	}
+
+	if lock.Variables, err = p.presetVariables(lock.Preset, manifest, sets); err != nil {
+		return
+	}
+
+	lock.Services = services
+	files, err = p.renderPreset(lock.Preset, manifest, services, lock.Variables)
+	return
+}
+
+func (p *KoolPreset) writeLock(lock *presets.Lock) (err error) {
+	var content string
+
+	if content, err = lock.String(); err != nil {
+		return
+	}
+
+	err = p.writeFile(presets.PresetLockFile, content)
 	return
 }
 
@@ -192,12 +466,282 @@ func NewPresetCommand(preset *KoolPreset) (presetCmd *cobra.Command) {
 	}
 
 	presetCmd.Flags().BoolVarP(&preset.Flags.Override, "override", "", false, "Force replace local existing files with the preset files")
+	presetCmd.Flags().BoolVarP(&preset.Flags.Merge, "merge", "", false, "Merge the preset into existing files - adding missing kool.yml scripts and docker-compose.yml services, volumes and networks")
+	addPresetAnswersFlags(presetCmd.Flags(), preset.Flags)
+	return
+}
+
+// addPresetAnswersFlags adds the flags answering the preset prompts;
+// a flag is added for every service question of the built-in presets.
+func addPresetAnswersFlags(flags *pflag.FlagSet, presetFlags *KoolPresetFlags) {
+	flags.StringArrayVarP(&presetFlags.Variables, "set", "", []string{}, "Set a preset variable value (key=value), skipping its prompt")
+	flags.StringVarP(&presetFlags.Answers, "answers", "", "", "YAML file answering the preset prompts (language, preset, services and variables)")
+
+	options := builtInServicesOptions()
+
+	for _, service := range sortedServices(options) {
+		if flags.Lookup(service) != nil {
+			continue
+		}
+
+		flags.Var(&presetServiceFlag{presetFlags.Services, service}, service, fmt.Sprintf("Option for the %s service, skipping its prompt (%s)", service, strings.Join(options[service], ", ")))
+	}
+}
+
+// builtInServicesOptions gets the keys of the options of every
+// service question made by the built-in presets.
+func builtInServicesOptions() (options map[string][]string) {
+	options = make(map[string][]string)
+
+	for _, files := range presets.GetAll() {
+		manifest, err := presets.ParseManifest(files[presets.PresetManifestFile])
+
+		if err != nil {
+			continue
+		}
+
+		for _, question := range manifest.Questions {
+			for _, key := range question.OptionsKeys() {
+				if !containsString(options[question.Service], key) {
+					options[question.Service] = append(options[question.Service], key)
+				}
+			}
+		}
+	}
+
+	return
+}
+
+func sortedServices(options map[string][]string) (services []string) {
+	for service := range options {
+		services = append(services, service)
+	}
+
+	sort.Strings(services)
+	return
+}
+
+func (f *presetServiceFlag) String() string {
+	return f.services[f.service]
+}
+
+func (f *presetServiceFlag) Set(value string) error {
+	f.services[f.service] = value
+	return nil
+}
+
+func (f *presetServiceFlag) Type() string {
+	return "string"
+}
+
+// presetServices picks the options of the preset service questions,
+// either given through flags, the answers file or prompted to the user;
+// on non-tty environments unanswered questions keep the preset default.
+func (p *KoolPreset) presetServices(preset string, manifest *presets.Manifest, answered map[string]string) (services map[string]string, err error) {
+	var choices = make(map[string]string)
+
+	services = make(map[string]string)
+
+	for service, choice := range answered {
+		choices[service] = choice
+	}
+
+	for service, choice := range p.Flags.Services {
+		choices[service] = choice
+	}
+
+	for service := range choices {
+		if manifest.Question(service) == nil {
+			err = fmt.Errorf("preset %s has no %s service to choose", preset, service)
+			return
+		}
+	}
+
+	for _, question := range manifest.Questions {
+		choice, isChosen := choices[question.Service]
+
+		switch {
+		case isChosen:
+			option := question.FindOption(choice)
+
+			if option == nil {
+				err = fmt.Errorf("invalid option %s for %s service; options are: %s", choice, question.Service, strings.Join(question.OptionsKeys(), ", "))
+				return
+			}
+
+			services[question.Service] = option.Name
+		case p.IsTerminal():
+			if services[question.Service], err = p.promptSelect.Ask(question.Question, question.OptionsNames()); err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// readAnswers reads the --answers file, if any.
+func (p *KoolPreset) readAnswers() (answers *presets.Answers, err error) {
+	var content string
+
+	if p.Flags.Answers != "" {
+		if content, err = p.presetsParser.ReadFile(p.Flags.Answers); err != nil {
+			err = fmt.Errorf("failed reading answers file: %v", err)
+			return
+		}
+	}
+
+	answers, err = presets.ParseAnswers(content)
+	return
+}
+
+// presetSets gets the variables values given through --set,
+// falling back to the ones of the answers file.
+func (p *KoolPreset) presetSets(answers *presets.Answers) (sets map[string]string, err error) {
+	if sets, err = parsePresetVariables(p.Flags.Variables); err != nil {
+		return
+	}
+
+	for name, value := range answers.Variables {
+		if _, isSet := sets[name]; !isSet {
+			sets[name] = value
+		}
+	}
+
 	return
 }
 
-func formatTemplateKey(key string) (formattedKey string) {
-	formattedKey = strings.ReplaceAll(key, " ", "")
-	formattedKey = strings.ReplaceAll(formattedKey, ".", "")
-	formattedKey = strings.ToLower(formattedKey) + ".yml"
+// presetVariables resolves the values of the variables declared by the
+// preset manifest, either given through --set or prompted to the user.
+// For presets without variables nil is returned.
+func (p *KoolPreset) presetVariables(preset string, manifest *presets.Manifest, sets map[string]string) (values map[string]string, err error) {
+	if len(manifest.Variables) == 0 {
+		if len(sets) > 0 {
+			err = fmt.Errorf("preset %s does not declare any variables", preset)
+		}
+		return
+	}
+
+	for name := range sets {
+		if manifest.Variable(name) == nil {
+			err = fmt.Errorf("unknown variable %s for preset %s", name, preset)
+			return
+		}
+	}
+
+	values = make(map[string]string)
+
+	for _, variable := range manifest.Variables {
+		value, isSet := sets[variable.Name]
+
+		switch {
+		case isSet:
+			if len(variable.Options) > 0 && !containsString(variable.Options, value) {
+				err = fmt.Errorf("invalid value %s for variable %s; options are: %s", value, variable.Name, strings.Join(variable.Options, ", "))
+			}
+		case !p.IsTerminal():
+			if value = variable.Default; value == "" {
+				err = fmt.Errorf("missing value for variable %s; for non-tty environments use --set %s=VALUE", variable.Name, variable.Name)
+			}
+		case len(variable.Options) > 0:
+			value, err = p.promptSelect.Ask(variable.Question, variable.Options)
+		default:
+			value, err = p.promptInput.Input(variable.Question, variable.Default)
+		}
+
+		if err != nil {
+			return
+		}
+
+		values[variable.Name] = value
+	}
+
+	return
+}
+
+// renderFile gets the preset file content rendered with the
+// variables values; files of presets without variables are
+// taken verbatim.
+func (p *KoolPreset) renderFile(preset string, fileName string, values map[string]string) (content string, err error) {
+	content = p.presetsParser.GetPresetKeyContent(preset, fileName)
+
+	if values == nil {
+		return
+	}
+
+	content, err = presets.RenderFile(fileName, content, values)
+	return
+}
+
+func parsePresetVariables(variables []string) (values map[string]string, err error) {
+	values = make(map[string]string)
+
+	for _, variable := range variables {
+		pieces := strings.SplitN(variable, "=", 2)
+
+		if len(pieces) != 2 || pieces[0] == "" {
+			err = fmt.Errorf("invalid variable %s; expected format is key=value", variable)
+			return
+		}
+
+		values[pieces[0]] = pieces[1]
+	}
+
+	return
+}
+
+func sortedKeys(values map[string]string) (keys []string) {
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// presetsFolders lists the folders external presets are loaded from; the
+// project local ones take precedence over the user's and the built-in ones.
+func presetsFolders(envStorage environment.EnvStorage) (folders []string) {
+	folders = []string{filepath.Join(envStorage.Get("HOME"), ".kool", "presets")}
+
+	if wd, err := os.Getwd(); err == nil {
+		folders = append(folders, filepath.Join(wd, ".kool", "presets"))
+	}
+
+	return
+}
+
+// loadAllPresets loads the built-in presets and templates along
+// with the external presets; invalid external presets are skipped
+// with a warning so they don't get in the way of the other ones.
+func loadAllPresets(out shell.OutputWriter, parser presets.Parser, envStorage environment.EnvStorage) (err error) {
+	parser.LoadPresets(presets.GetAll())
+	parser.LoadTemplates(presets.GetTemplates())
+
+	for _, folder := range presetsFolders(envStorage) {
+		if err = parser.LoadPresetsFolder(folder); err != nil {
+			if invalid, isInvalid := err.(presets.InvalidPresetsError); isInvalid {
+				for _, presetErr := range invalid {
+					out.Warning("Skipping invalid ", presetErr, " (from ", folder, ")")
+				}
+
+				err = nil
+				continue
+			}
+
+			err = fmt.Errorf("failed loading presets from %s: %v", folder, err)
+			return
+		}
+	}
+
 	return
 }
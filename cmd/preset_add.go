@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/cmd/presets"
+	"kool-dev/kool/environment"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolPresetAddFlags holds the flags for the preset add command
+type KoolPresetAddFlags struct {
+	Name string
+}
+
+// KoolPresetAdd holds handlers and functions to implement the preset add command logic
+type KoolPresetAdd struct {
+	DefaultKoolService
+	Flags *KoolPresetAddFlags
+
+	envStorage environment.EnvStorage
+	gitClone   builder.Command
+}
+
+// NewKoolPresetAdd creates a new handler for preset add logic with default dependencies
+func NewKoolPresetAdd() *KoolPresetAdd {
+	return &KoolPresetAdd{
+		*newDefaultKoolService(),
+		&KoolPresetAddFlags{""},
+		environment.NewEnvStorage(),
+		builder.NewCommand("git", "clone", "--quiet", "--depth", "1"),
+	}
+}
+
+// Execute runs the preset add logic with incoming arguments.
+func (a *KoolPresetAdd) Execute(args []string) (err error) {
+	var (
+		source     = args[0]
+		sourceDir  string
+		presetDirs map[string]string
+		target     = filepath.Join(a.envStorage.Get("HOME"), ".kool", "presets")
+	)
+
+	if _, statErr := os.Stat(source); statErr == nil {
+		sourceDir = source
+	} else {
+		if sourceDir, err = ioutil.TempDir("", "kool-preset"); err != nil {
+			return
+		}
+
+		defer os.RemoveAll(sourceDir)
+
+		a.Println("Cloning", source, "...")
+
+		if _, err = a.gitClone.Exec(source, sourceDir); err != nil {
+			err = fmt.Errorf("failed cloning presets repository %s: %v", source, err)
+			return
+		}
+	}
+
+	if presetDirs, err = a.findPresets(source, sourceDir); err != nil {
+		return
+	}
+
+	// every preset gets checked before installing any of them, so
+	// a bad one doesn't leave the presets folder half updated
+	for name, dir := range presetDirs {
+		if !isValidPresetName(name) {
+			err = fmt.Errorf("invalid preset name '%s'; names can't be empty nor hold path separators or '..'", name)
+			return
+		}
+
+		if err = validatePresetFolder(dir); err != nil {
+			err = fmt.Errorf("failed adding preset %s: %v", name, err)
+			return
+		}
+	}
+
+	for name, dir := range presetDirs {
+		presetTarget := filepath.Join(target, name)
+
+		if _, statErr := os.Stat(presetTarget); statErr == nil {
+			a.Warning("Replacing existing preset ", name)
+
+			if err = os.RemoveAll(presetTarget); err != nil {
+				return
+			}
+		}
+
+		if err = copyPresetFolder(dir, presetTarget); err != nil {
+			err = fmt.Errorf("failed installing preset %s: %v", name, err)
+			return
+		}
+
+		a.Success("Preset ", name, " added!")
+	}
+
+	return
+}
+
+// findPresets looks for presets within the given folder, which is
+// either a preset itself or holds presets in its sub folders.
+func (a *KoolPresetAdd) findPresets(source string, dir string) (presetDirs map[string]string, err error) {
+	var folders []os.FileInfo
+
+	presetDirs = make(map[string]string)
+
+	if isPresetFolder(dir) {
+		name := a.Flags.Name
+
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(strings.TrimRight(source, `/\`)), ".git")
+		}
+
+		presetDirs[name] = dir
+		return
+	}
+
+	if folders, err = ioutil.ReadDir(dir); err != nil {
+		return
+	}
+
+	for _, folder := range folders {
+		if folder.IsDir() && isPresetFolder(filepath.Join(dir, folder.Name())) {
+			presetDirs[folder.Name()] = filepath.Join(dir, folder.Name())
+		}
+	}
+
+	if len(presetDirs) == 0 {
+		err = fmt.Errorf("no presets found at %s; presets must hold a %s manifest", source, presets.PresetManifestFile)
+	} else if len(presetDirs) > 1 && a.Flags.Name != "" {
+		err = fmt.Errorf("--name can only be used when adding a single preset")
+	}
+
+	return
+}
+
+// isValidPresetName tells whether the name is safe to be used as a
+// folder within the presets folder
+func isValidPresetName(name string) bool {
+	return name != "" && name != "." && !strings.Contains(name, "..") && !strings.ContainsAny(name, `/\`)
+}
+
+// validatePresetFolder parses and validates the manifest of the preset
+// folder against the built-in templates, just like loading it would.
+func validatePresetFolder(dir string) (err error) {
+	var (
+		content  []byte
+		manifest *presets.Manifest
+	)
+
+	if content, err = ioutil.ReadFile(filepath.Join(dir, presets.PresetManifestFile)); err != nil {
+		return
+	}
+
+	if manifest, err = presets.ParseManifest(string(content)); err != nil {
+		return
+	}
+
+	err = manifest.Validate(func(path string) bool {
+		_, templateErr := presets.FindTemplate(presets.GetTemplates(), path)
+		return templateErr == nil
+	})
+	return
+}
+
+func isPresetFolder(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, presets.PresetManifestFile))
+	return err == nil
+}
+
+// copyPresetFolder copies the files of the preset folder, presets
+// don't hold sub folders so they are left out.
+func copyPresetFolder(src string, dst string) (err error) {
+	var files []os.FileInfo
+
+	if files, err = ioutil.ReadDir(src); err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(dst, 0755); err != nil {
+		return
+	}
+
+	for _, file := range files {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		if err = copyPresetFile(filepath.Join(src, file.Name()), filepath.Join(dst, file.Name())); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func copyPresetFile(src string, dst string) (err error) {
+	var in, out *os.File
+
+	if in, err = os.Open(src); err != nil {
+		return
+	}
+
+	defer in.Close()
+
+	if out, err = os.Create(dst); err != nil {
+		return
+	}
+
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return
+}
+
+// NewPresetAddCommand initializes new kool preset add command
+func NewPresetAddCommand(add *KoolPresetAdd) (addCmd *cobra.Command) {
+	addCmd = &cobra.Command{
+		Use:   "add [GIT-URL|PATH]",
+		Short: "Install presets from a git repository or local folder onto $HOME/.kool/presets",
+		Args:  cobra.ExactArgs(1),
+		Run:   DefaultCommandRunFunction(add),
+	}
+
+	addCmd.Flags().StringVarP(&add.Flags.Name, "name", "n", "", "Name for the preset when adding a single one")
+	return
+}
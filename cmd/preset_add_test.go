@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/environment"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newFakeKoolPresetAdd(home string) *KoolPresetAdd {
+	envStorage := environment.NewFakeEnvStorage()
+	envStorage.Envs["HOME"] = home
+
+	return &KoolPresetAdd{
+		*newFakeKoolService(),
+		&KoolPresetAddFlags{""},
+		envStorage,
+		&builder.FakeCommand{},
+	}
+}
+
+func writePresetFolder(t *testing.T, dir string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = ioutil.WriteFile(filepath.Join(dir, "preset.yml"), []byte("language: php"), 0644)
+	_ = ioutil.WriteFile(filepath.Join(dir, "kool.yml"), []byte("scripts:"), 0644)
+}
+
+func TestNewKoolPresetAdd(t *testing.T) {
+	k := NewKoolPresetAdd()
+
+	if _, ok := k.gitClone.(*builder.DefaultCommand); !ok {
+		t.Errorf("unexpected builder.Command on default KoolPresetAdd instance")
+	}
+
+	if k.Flags == nil || k.Flags.Name != "" {
+		t.Errorf("unexpected default flags on KoolPresetAdd instance")
+	}
+}
+
+func TestPresetAddLocalRepository(t *testing.T) {
+	home, _ := ioutil.TempDir("", "kool-home")
+	defer os.RemoveAll(home)
+
+	source, _ := ioutil.TempDir("", "kool-presets")
+	defer os.RemoveAll(source)
+
+	writePresetFolder(t, filepath.Join(source, "company-php"))
+	writePresetFolder(t, filepath.Join(source, "company-node"))
+	_ = os.MkdirAll(filepath.Join(source, "docs"), 0755)
+
+	k := newFakeKoolPresetAdd(home)
+
+	if err := k.Execute([]string{source}); err != nil {
+		t.Fatalf("unexpected error adding presets: %v", err)
+	}
+
+	if k.gitClone.(*builder.FakeCommand).CalledExec {
+		t.Error("should not clone local presets folder")
+	}
+
+	for _, name := range []string{"company-php", "company-node"} {
+		if _, err := os.Stat(filepath.Join(home, ".kool", "presets", name, "kool.yml")); err != nil {
+			t.Errorf("preset %s was not installed: %v", name, err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(home, ".kool", "presets", "docs")); !os.IsNotExist(err) {
+		t.Error("folder without preset metadata should not be installed")
+	}
+}
+
+func TestPresetAddSinglePresetWithName(t *testing.T) {
+	home, _ := ioutil.TempDir("", "kool-home")
+	defer os.RemoveAll(home)
+
+	source, _ := ioutil.TempDir("", "kool-presets")
+	defer os.RemoveAll(source)
+
+	writePresetFolder(t, source)
+
+	k := newFakeKoolPresetAdd(home)
+	k.Flags.Name = "internal"
+
+	if err := k.Execute([]string{source}); err != nil {
+		t.Fatalf("unexpected error adding preset: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(home, ".kool", "presets", "internal", "preset.yml")); err != nil {
+		t.Errorf("preset was not installed under the given name: %v", err)
+	}
+}
+
+func TestPresetAddInvalidName(t *testing.T) {
+	home, _ := ioutil.TempDir("", "kool-home")
+	defer os.RemoveAll(home)
+
+	source, _ := ioutil.TempDir("", "kool-presets")
+	defer os.RemoveAll(source)
+
+	writePresetFolder(t, source)
+
+	victim := filepath.Join(home, "victim")
+	writePresetFolder(t, victim)
+
+	for _, name := range []string{"../../victim", "..", "nested/name", `nested\name`} {
+		k := newFakeKoolPresetAdd(home)
+		k.Flags.Name = name
+
+		if err := k.Execute([]string{source}); err == nil {
+			t.Errorf("expected error adding preset with name %s", name)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(victim, "preset.yml")); err != nil {
+		t.Errorf("folder outside the presets folder was removed: %v", err)
+	}
+}
+
+func TestPresetAddInvalidManifest(t *testing.T) {
+	home, _ := ioutil.TempDir("", "kool-home")
+	defer os.RemoveAll(home)
+
+	source, _ := ioutil.TempDir("", "kool-presets")
+	defer os.RemoveAll(source)
+
+	writePresetFolder(t, filepath.Join(source, "good"))
+	writePresetFolder(t, filepath.Join(source, "bad"))
+	_ = ioutil.WriteFile(filepath.Join(source, "bad", "preset.yml"), []byte("foo: bar"), 0644)
+
+	writePresetFolder(t, filepath.Join(source, "missing"))
+	_ = ioutil.WriteFile(filepath.Join(source, "missing", "preset.yml"), []byte(`language: php
+questions:
+  - service: database
+    options:
+      - name: MySQL 9.0
+        template: database/mysql90.yml
+`), 0644)
+
+	for _, preset := range []string{"bad", "missing"} {
+		k := newFakeKoolPresetAdd(home)
+
+		err := k.Execute([]string{source})
+
+		if err == nil || !strings.Contains(err.Error(), "failed adding preset ") {
+			t.Errorf("expected error adding invalid preset %s; got %v", preset, err)
+		}
+
+		_ = os.RemoveAll(filepath.Join(source, preset))
+	}
+
+	if _, err := os.Stat(filepath.Join(home, ".kool", "presets", "good")); !os.IsNotExist(err) {
+		t.Errorf("no preset should be added when any of them is invalid; got %v", err)
+	}
+}
+
+func TestPresetAddNoPresets(t *testing.T) {
+	source, _ := ioutil.TempDir("", "kool-presets")
+	defer os.RemoveAll(source)
+
+	k := newFakeKoolPresetAdd(source)
+
+	if err := k.Execute([]string{source}); err == nil {
+		t.Error("expected error adding folder without presets")
+	}
+}
+
+func TestPresetAddGitCloneError(t *testing.T) {
+	home, _ := ioutil.TempDir("", "kool-home")
+	defer os.RemoveAll(home)
+
+	k := newFakeKoolPresetAdd(home)
+	k.gitClone.(*builder.FakeCommand).MockError = errors.New("clone error")
+
+	err := k.Execute([]string{"https://example.com/presets.git"})
+
+	if err == nil || !k.gitClone.(*builder.FakeCommand).CalledExec {
+		t.Error("expected cloning the presets repository to fail")
+	}
+}
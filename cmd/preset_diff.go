@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"kool-dev/kool/cmd/presets"
+	"kool-dev/kool/diff"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolPresetDiff holds handlers and functions to implement the preset diff command logic
+type KoolPresetDiff struct {
+	DefaultKoolService
+	preset *KoolPreset
+}
+
+// presetDiffContext is the number of unchanged lines shown around changes
+const presetDiffContext int = 3
+
+// NewKoolPresetDiff creates a new handler for preset diff logic with default dependencies
+func NewKoolPresetDiff() *KoolPresetDiff {
+	return &KoolPresetDiff{
+		*newDefaultKoolService(),
+		NewKoolPreset(),
+	}
+}
+
+// Execute runs the preset diff logic with incoming arguments.
+func (d *KoolPresetDiff) Execute(args []string) (err error) {
+	var (
+		lock      *presets.Lock
+		files     map[string]string
+		different bool
+	)
+
+	if lock, files, err = d.preset.renderFromLock(args); err != nil {
+		return
+	}
+
+	for _, fileName := range sortedKeys(files) {
+		var (
+			local    string
+			fromName = "a/" + fileName
+		)
+
+		if local, err = d.preset.presetsParser.ReadFile(fileName); os.IsNotExist(err) {
+			fromName = "/dev/null"
+		} else if err != nil {
+			return
+		}
+
+		if unified := diff.Unified(fromName, "b/"+fileName, local, files[fileName], presetDiffContext); unified != "" {
+			different = true
+			d.Println(strings.TrimSuffix(unified, "\n"))
+		}
+	}
+
+	err = nil
+
+	if !different {
+		d.Success("Project files are up to date with preset ", lock.Preset)
+	}
+
+	return
+}
+
+// NewPresetDiffCommand initializes new kool preset diff command
+func NewPresetDiffCommand(presetDiff *KoolPresetDiff) (diffCmd *cobra.Command) {
+	diffCmd = &cobra.Command{
+		Use:   "diff [PRESET]",
+		Short: "Show the differences between the project files and the current preset version",
+		Long: `Show unified diffs between the project files and what the current version
+of the preset generates, using the answers recorded in kool-preset.lock. For
+projects without lock file, specify the preset to compare with.`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  DefaultCommandRunFunction(presetDiff),
+	}
+
+	return
+}
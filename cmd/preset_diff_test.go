@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"kool-dev/kool/cmd/presets"
+	"kool-dev/kool/cmd/shell"
+	"strings"
+	"testing"
+)
+
+const presetLock string = `preset: laravel
+files:
+  kool.yml: |
+    scripts:
+      setup: kool start
+`
+
+func newFakeKoolPresetDiff() *KoolPresetDiff {
+	preset := newFakeKoolPreset()
+	preset.presetsParser.(*presets.FakeParser).MockExists = true
+	preset.presetsParser.(*presets.FakeParser).MockPresetKeys = []string{"kool.yml", "preset.yml"}
+	preset.presetsParser.(*presets.FakeParser).MockPresetKeyContent = map[string]map[string]string{
+		"laravel": map[string]string{
+			"kool.yml": "scripts:\n  setup: kool start --foreground\n",
+		},
+	}
+	preset.presetsParser.(*presets.FakeParser).MockFiles = map[string]string{
+		"kool-preset.lock": presetLock,
+		"kool.yml":         "scripts:\n  setup: kool start\n",
+	}
+
+	return &KoolPresetDiff{
+		*newFakeKoolService(),
+		preset,
+	}
+}
+
+func TestNewKoolPresetDiff(t *testing.T) {
+	k := NewKoolPresetDiff()
+
+	if _, ok := k.preset.presetsParser.(*presets.DefaultParser); !ok {
+		t.Errorf("unexpected presets.Parser on default KoolPresetDiff instance")
+	}
+}
+
+func TestPresetDiffCommand(t *testing.T) {
+	f := newFakeKoolPresetDiff()
+
+	if err := f.Execute(nil); err != nil {
+		t.Fatalf("unexpected error executing preset diff: %v", err)
+	}
+
+	output := strings.Join(f.out.(*shell.FakeOutputWriter).OutLines, "\n")
+
+	expected := `--- a/kool.yml
++++ b/kool.yml
+@@ -1,2 +1,2 @@
+ scripts:
+-  setup: kool start
++  setup: kool start --foreground`
+
+	if output != expected {
+		t.Errorf("expected diff:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestPresetDiffUpToDateCommand(t *testing.T) {
+	f := newFakeKoolPresetDiff()
+	f.preset.presetsParser.(*presets.FakeParser).MockFiles["kool.yml"] = "scripts:\n  setup: kool start --foreground\n"
+
+	if err := f.Execute(nil); err != nil {
+		t.Fatalf("unexpected error executing preset diff: %v", err)
+	}
+
+	if !f.out.(*shell.FakeOutputWriter).CalledSuccess || len(f.out.(*shell.FakeOutputWriter).OutLines) > 0 {
+		t.Error("expected only the up to date message")
+	}
+}
+
+func TestPresetDiffMissingFileCommand(t *testing.T) {
+	f := newFakeKoolPresetDiff()
+	delete(f.preset.presetsParser.(*presets.FakeParser).MockFiles, "kool.yml")
+
+	if err := f.Execute(nil); err != nil {
+		t.Fatalf("unexpected error executing preset diff: %v", err)
+	}
+
+	if output := f.out.(*shell.FakeOutputWriter).OutLines; len(output) == 0 || !strings.HasPrefix(output[0], "--- /dev/null") {
+		t.Errorf("expected diff from /dev/null; got %v", output)
+	}
+}
+
+func TestPresetDiffWithoutLockCommand(t *testing.T) {
+	f := newFakeKoolPresetDiff()
+	delete(f.preset.presetsParser.(*presets.FakeParser).MockFiles, "kool-preset.lock")
+
+	if err := f.Execute(nil); err != ErrPresetLockNotFound {
+		t.Errorf("expected error %v; got %v", ErrPresetLockNotFound, err)
+	}
+
+	if err := f.Execute([]string{"laravel"}); err != nil {
+		t.Errorf("unexpected error executing preset diff with preset argument: %v", err)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"kool-dev/kool/cmd/compose"
 	"kool-dev/kool/cmd/presets"
 	"kool-dev/kool/cmd/shell"
+	"kool-dev/kool/environment"
+	"strings"
 	"testing"
 )
 
@@ -57,29 +59,49 @@ networks:
     name: "${KOOL_GLOBAL_NETWORK:-kool_global}"
 `
 
-const mysqlTemplate string = `image: mysql:8.0
+const mysqlTemplate string = `name: MySQL 8.0
+category: database
+env:
+  KOOL_DATABASE_PORT: "3306"
+  DB_DATABASE: database
+volumes:
+  - database
+scripts:
+  mysql: kool exec database mysql -uroot -p$DB_PASSWORD
+service:
+  image: mysql:8.0
+  command: --default-authentication-plugin=mysql_native_password
+  ports:
+   - "${KOOL_DATABASE_PORT:-3306}:3306"
+  environment:
+    MYSQL_DATABASE: "${DB_DATABASE:-database}"
+  volumes:
+   - database:/var/lib/mysql:delegated
+  networks:
+   - kool_local
+`
+
+const mysqlService string = `image: mysql:8.0
 command: --default-authentication-plugin=mysql_native_password
 ports:
   - "${KOOL_DATABASE_PORT:-3306}:3306"
 environment:
-  MYSQL_ROOT_PASSWORD: "${DB_PASSWORD:-rootpass}"
   MYSQL_DATABASE: "${DB_DATABASE:-database}"
-  MYSQL_USER: "${DB_USERNAME:-user}"
-  MYSQL_PASSWORD: "${DB_PASSWORD:-pass}"
-  MYSQL_ALLOW_EMPTY_PASSWORD: "yes"
 volumes:
- - database:/var/lib/mysql:delegated
+  - database:/var/lib/mysql:delegated
 networks:
- - kool_local
- `
+  - kool_local
+`
 
 func newFakeKoolPreset() *KoolPreset {
 	return &KoolPreset{
 		*newFakeKoolService(),
-		&KoolPresetFlags{false},
+		&KoolPresetFlags{false, false, []string{}, map[string]string{}, ""},
+		environment.NewFakeEnvStorage(),
 		&presets.FakeParser{},
 		&compose.FakeParser{},
 		&shell.FakePromptSelect{},
+		&shell.FakePromptInput{},
 	}
 }
 
@@ -116,6 +138,10 @@ func TestNewKoolPreset(t *testing.T) {
 		t.Errorf("unexpected shell.PromptSelect on default KoolPreset instance")
 	}
 
+	if _, ok := k.promptInput.(*shell.DefaultPromptInput); !ok {
+		t.Errorf("unexpected shell.PromptInput on default KoolPreset instance")
+	}
+
 	if _, ok := k.DefaultKoolService.term.(*shell.DefaultTerminalChecker); !ok {
 		t.Errorf("unexpected shell.TerminalChecker on default KoolPreset instance")
 	}
@@ -147,8 +173,8 @@ func TestPresetCommand(t *testing.T) {
 		t.Error("did not call parser.Exists")
 	}
 
-	if !f.presetsParser.(*presets.FakeParser).CalledGetPresetKeyContent["laravel"]["preset_ask_services"] {
-		t.Error("did not call parser.GetPresetKeyContent for preset 'laravel' and meta 'preset_ask_services'")
+	if !f.presetsParser.(*presets.FakeParser).CalledGetManifest {
+		t.Error("did not call parser.GetManifest")
 	}
 
 	if !f.out.(*shell.FakeOutputWriter).CalledPrintln {
@@ -170,8 +196,8 @@ func TestPresetCommand(t *testing.T) {
 		t.Error("did not call parser.GetPresetKeys")
 	}
 
-	if !f.presetsParser.(*presets.FakeParser).CalledGetTemplates {
-		t.Error("did not call parser.GetTemplates")
+	if _, ok := f.presetsParser.(*presets.FakeParser).CalledWriteFile["kool-preset.lock"]; !ok {
+		t.Error("did not write the preset lock file")
 	}
 
 	if val, ok := f.presetsParser.(*presets.FakeParser).CalledGetPresetKeyContent["laravel"]["kool.yml"]; !ok || !val {
@@ -282,6 +308,115 @@ func TestOverrideFilesPresetCommand(t *testing.T) {
 	}
 }
 
+func TestMergeFilesPresetCommand(t *testing.T) {
+	f := newFakeKoolPreset()
+	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockPresetKeys = []string{"kool.yml", "docker-compose.yml", "Dockerfile", "README.md"}
+	f.presetsParser.(*presets.FakeParser).MockPresetKeyContent = map[string]map[string]string{
+		"laravel": map[string]string{
+			"kool.yml":           "scripts:\n  setup: kool start\n  npm: kool docker node npm\n",
+			"docker-compose.yml": "services:\n  app:\n    image: app\n  cache:\n    image: redis\n",
+			"Dockerfile":         "FROM preset",
+			"README.md":          "preset readme",
+		},
+	}
+	f.presetsParser.(*presets.FakeParser).MockFiles = map[string]string{
+		"kool.yml":           "scripts:\n  setup: kool run custom\n",
+		"docker-compose.yml": "services:\n  app:\n    image: custom\n",
+		"Dockerfile":         "FROM custom",
+	}
+	f.composeParser.(*compose.FakeParser).MockMergeAdded = []string{"services.cache"}
+	f.composeParser.(*compose.FakeParser).MockString = "merged compose"
+
+	cmd := NewPresetCommand(f)
+
+	cmd.SetArgs([]string{"--merge", "laravel"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing preset command; error: %v", err)
+	}
+
+	if f.presetsParser.(*presets.FakeParser).CalledLookUpFiles {
+		t.Error("unexpected existing files checking")
+	}
+
+	written := f.presetsParser.(*presets.FakeParser).CalledWriteFile
+
+	if !written["kool.yml"]["scripts:\n  setup: kool run custom\n  npm: kool docker node npm\n"] {
+		t.Errorf("did not merge the missing kool.yml scripts; written: %v", written["kool.yml"])
+	}
+
+	if !f.composeParser.(*compose.FakeParser).CalledLoad["services:\n  app:\n    image: custom\n"] || len(f.composeParser.(*compose.FakeParser).CalledMerge) == 0 {
+		t.Error("did not merge docker-compose.yml with the compose parser")
+	}
+
+	if !written["docker-compose.yml"]["merged compose"] {
+		t.Errorf("did not write the merged docker-compose.yml; written: %v", written["docker-compose.yml"])
+	}
+
+	if _, wroteDockerfile := written["Dockerfile"]; wroteDockerfile {
+		t.Error("unexpected overriding of existing Dockerfile")
+	}
+
+	if !written["README.md"]["preset readme"] {
+		t.Error("did not write the missing README.md")
+	}
+
+	if !f.out.(*shell.FakeOutputWriter).CalledSuccess {
+		t.Error("did not call Success")
+	}
+}
+
+func TestMergeAndOverridePresetCommand(t *testing.T) {
+	f := newFakeKoolPreset()
+	f.presetsParser.(*presets.FakeParser).MockExists = true
+
+	cmd := NewPresetCommand(f)
+
+	cmd.SetArgs([]string{"--merge", "--override", "laravel"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing preset command; error: %v", err)
+	}
+
+	if !f.out.(*shell.FakeOutputWriter).CalledError {
+		t.Error("did not call Error")
+	}
+
+	if _, written := f.presetsParser.(*presets.FakeParser).CalledWriteFile["kool.yml"]; written {
+		t.Error("unexpected preset file writing")
+	}
+}
+
+func TestErrorMergeComposePresetCommand(t *testing.T) {
+	f := newFakeKoolPreset()
+	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockPresetKeys = []string{"docker-compose.yml"}
+	f.presetsParser.(*presets.FakeParser).MockPresetKeyContent = map[string]map[string]string{
+		"laravel": map[string]string{
+			"docker-compose.yml": "services:\n  app:\n    image: app\n",
+		},
+	}
+	f.presetsParser.(*presets.FakeParser).MockFiles = map[string]string{
+		"docker-compose.yml": "services:\n  app:\n    image: custom\n",
+	}
+	f.composeParser.(*compose.FakeParser).MockMergeError = errors.New("merge error")
+
+	cmd := NewPresetCommand(f)
+
+	cmd.SetArgs([]string{"--merge", "laravel"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing preset command; error: %v", err)
+	}
+
+	expected := "Failed to merge preset file docker-compose.yml: merge error"
+
+	if err := f.out.(*shell.FakeOutputWriter).Err; err == nil || err.Error() != expected {
+		t.Errorf("expecting error '%s', got '%v'", expected, err)
+	}
+}
+
 func TestWriteErrorPresetCommand(t *testing.T) {
 	f := newFakeKoolPreset()
 	f.presetsParser.(*presets.FakeParser).MockExists = true
@@ -481,23 +616,38 @@ func TestNonTTYPresetCommand(t *testing.T) {
 	}
 }
 
+func newDatabaseQuestionManifest() map[string]*presets.Manifest {
+	return map[string]*presets.Manifest{
+		"laravel": {
+			Questions: []*presets.Question{{
+				Service:  "database",
+				Question: "What database service do you want to use",
+				Options: []*presets.Option{
+					{Name: "MySQL 8.0", Template: "database/mysql80.yml"},
+					{Name: "PostgreSQL 13.0", Template: "database/postgresql130.yml"},
+					{Name: "none"},
+				},
+			}},
+		},
+	}
+}
+
 func TestCustomDockerComposePresetCommand(t *testing.T) {
 	f := newFakeKoolPreset()
 	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockManifest = newDatabaseQuestionManifest()
 	f.presetsParser.(*presets.FakeParser).MockPresetKeyContent = map[string]map[string]string{
 		"laravel": map[string]string{
-			"preset_ask_services":     "database",
-			"preset_database_options": "mysql,postgresql",
-			"docker-compose.yml":      defaultCompose,
+			"docker-compose.yml": defaultCompose,
 		},
 	}
 	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{
-		"What database service do you want to use": "mysql",
+		"What database service do you want to use": "MySQL 8.0",
 	}
 	f.presetsParser.(*presets.FakeParser).MockPresetKeys = []string{"docker-compose.yml"}
 	f.presetsParser.(*presets.FakeParser).MockTemplates = map[string]map[string]string{
 		"database": map[string]string{
-			"mysql.yml": mysqlTemplate,
+			"mysql80.yml": mysqlTemplate,
 		},
 	}
 
@@ -517,7 +667,7 @@ func TestCustomDockerComposePresetCommand(t *testing.T) {
 		t.Error("failed calling compose.Load")
 	}
 
-	if val, ok := f.composeParser.(*compose.FakeParser).CalledSetService["database"][mysqlTemplate]; !ok || !val {
+	if val, ok := f.composeParser.(*compose.FakeParser).CalledSetService["database"][mysqlService]; !ok || !val {
 		t.Error("failed calling compose.SetService to database mysql service")
 	}
 
@@ -529,11 +679,10 @@ func TestCustomDockerComposePresetCommand(t *testing.T) {
 func TestCustomDockerNoneOptionComposePresetCommand(t *testing.T) {
 	f := newFakeKoolPreset()
 	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockManifest = newDatabaseQuestionManifest()
 	f.presetsParser.(*presets.FakeParser).MockPresetKeyContent = map[string]map[string]string{
 		"laravel": map[string]string{
-			"preset_ask_services":     "database",
-			"preset_database_options": "mysql,postgresql,none",
-			"docker-compose.yml":      defaultCompose,
+			"docker-compose.yml": defaultCompose,
 		},
 	}
 	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{
@@ -542,7 +691,7 @@ func TestCustomDockerNoneOptionComposePresetCommand(t *testing.T) {
 	f.presetsParser.(*presets.FakeParser).MockPresetKeys = []string{"docker-compose.yml"}
 	f.presetsParser.(*presets.FakeParser).MockTemplates = map[string]map[string]string{
 		"database": map[string]string{
-			"mysql.yml": mysqlTemplate,
+			"mysql80.yml": mysqlTemplate,
 		},
 	}
 
@@ -570,7 +719,7 @@ func TestCustomDockerNoneOptionComposePresetCommand(t *testing.T) {
 		t.Error("failed calling compose.RemoveService to database service")
 	}
 
-	if _, ok := f.composeParser.(*compose.FakeParser).CalledSetService["database"][mysqlTemplate]; ok {
+	if _, ok := f.composeParser.(*compose.FakeParser).CalledSetService["database"][mysqlService]; ok {
 		t.Error("should not call compose.SetService to database service")
 	}
 
@@ -582,7 +731,7 @@ func TestCustomDockerNoneOptionComposePresetCommand(t *testing.T) {
 func TestSkipInvalidPresetKeyPresetCommand(t *testing.T) {
 	f := newFakeKoolPreset()
 	f.presetsParser.(*presets.FakeParser).MockExists = true
-	f.presetsParser.(*presets.FakeParser).MockPresetKeys = []string{"preset_key"}
+	f.presetsParser.(*presets.FakeParser).MockPresetKeys = []string{"preset.yml"}
 
 	cmd := NewPresetCommand(f)
 
@@ -592,8 +741,8 @@ func TestSkipInvalidPresetKeyPresetCommand(t *testing.T) {
 		t.Errorf("unexpected error executing preset command; error: %v", err)
 	}
 
-	if val, ok := f.presetsParser.(*presets.FakeParser).CalledGetPresetKeyContent["laravel"]["preset_key"]; ok && val {
-		t.Error("should not CalledAsk presetsParser.GetPresetKeyContent for preset 'laravel' and key 'preset_key'")
+	if _, ok := f.presetsParser.(*presets.FakeParser).CalledWriteFile["preset.yml"]; ok {
+		t.Error("should not write the preset manifest")
 	}
 
 	if val, ok := f.composeParser.(*compose.FakeParser).CalledLoad[defaultCompose]; ok && val {
@@ -609,13 +758,7 @@ func TestErrorAskForServicePresetCommand(t *testing.T) {
 	f := newFakeKoolPreset()
 
 	f.presetsParser.(*presets.FakeParser).MockExists = true
-	f.presetsParser.(*presets.FakeParser).MockPresetKeyContent = map[string]map[string]string{
-		"laravel": map[string]string{
-			"preset_ask_services":     "database",
-			"preset_database_options": "mysql,postgresql",
-		},
-	}
-
+	f.presetsParser.(*presets.FakeParser).MockManifest = newDatabaseQuestionManifest()
 	f.promptSelect.(*shell.FakePromptSelect).MockError = map[string]error{
 		"What database service do you want to use": errors.New("database question error"),
 	}
@@ -645,15 +788,14 @@ func TestErrorLoadComposePresetCommand(t *testing.T) {
 	f := newFakeKoolPreset()
 
 	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockManifest = newDatabaseQuestionManifest()
 	f.presetsParser.(*presets.FakeParser).MockPresetKeyContent = map[string]map[string]string{
 		"laravel": map[string]string{
-			"preset_ask_services":     "database",
-			"preset_database_options": "mysql,postgresql",
-			"docker-compose.yml":      defaultCompose,
+			"docker-compose.yml": defaultCompose,
 		},
 	}
 	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{
-		"What database service do you want to use": "mysql",
+		"What database service do you want to use": "MySQL 8.0",
 	}
 	f.presetsParser.(*presets.FakeParser).MockPresetKeys = []string{"docker-compose.yml"}
 	f.composeParser.(*compose.FakeParser).MockLoadError = errors.New("compose load error")
@@ -683,20 +825,19 @@ func TestErrorSetComposeServicePresetCommand(t *testing.T) {
 	f := newFakeKoolPreset()
 
 	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockManifest = newDatabaseQuestionManifest()
 	f.presetsParser.(*presets.FakeParser).MockPresetKeyContent = map[string]map[string]string{
 		"laravel": map[string]string{
-			"preset_ask_services":     "database",
-			"preset_database_options": "mysql,postgresql",
-			"docker-compose.yml":      defaultCompose,
+			"docker-compose.yml": defaultCompose,
 		},
 	}
 	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{
-		"What database service do you want to use": "mysql",
+		"What database service do you want to use": "MySQL 8.0",
 	}
 	f.presetsParser.(*presets.FakeParser).MockPresetKeys = []string{"docker-compose.yml"}
 	f.presetsParser.(*presets.FakeParser).MockTemplates = map[string]map[string]string{
 		"database": map[string]string{
-			"mysql.yml": mysqlTemplate,
+			"mysql80.yml": mysqlTemplate,
 		},
 	}
 
@@ -727,20 +868,19 @@ func TestErrorComposeStringPresetCommand(t *testing.T) {
 	f := newFakeKoolPreset()
 
 	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockManifest = newDatabaseQuestionManifest()
 	f.presetsParser.(*presets.FakeParser).MockPresetKeyContent = map[string]map[string]string{
 		"laravel": map[string]string{
-			"preset_ask_services":     "database",
-			"preset_database_options": "mysql,postgresql",
-			"docker-compose.yml":      defaultCompose,
+			"docker-compose.yml": defaultCompose,
 		},
 	}
 	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{
-		"What database service do you want to use": "mysql",
+		"What database service do you want to use": "MySQL 8.0",
 	}
 	f.presetsParser.(*presets.FakeParser).MockPresetKeys = []string{"docker-compose.yml"}
 	f.presetsParser.(*presets.FakeParser).MockTemplates = map[string]map[string]string{
 		"database": map[string]string{
-			"mysql.yml": mysqlTemplate,
+			"mysql80.yml": mysqlTemplate,
 		},
 	}
 
@@ -766,3 +906,294 @@ func TestErrorComposeStringPresetCommand(t *testing.T) {
 		t.Errorf("expecting error 'Failed to write preset file docker-compose.yml: compose string error', got %v", err)
 	}
 }
+
+const variablesManifest string = `variables:
+  - name: project_name
+    question: What is the project name
+    default: my-app
+  - name: php_version
+    options: ["7.4", "8.0"]
+    default: "7.4"
+`
+
+func newFakeKoolPresetWithVariables() *KoolPreset {
+	manifest, _ := presets.ParseManifest(variablesManifest)
+
+	f := newFakeKoolPreset()
+	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockManifest = map[string]*presets.Manifest{"custom": manifest}
+	f.presetsParser.(*presets.FakeParser).MockPresetKeys = []string{"kool.yml", "preset.yml"}
+	f.presetsParser.(*presets.FakeParser).MockPresetKeyContent = map[string]map[string]string{
+		"custom": map[string]string{
+			"kool.yml": "# {{ .project_name }} on php {{ .php_version }}",
+		},
+	}
+	return f
+}
+
+func TestVariablesPresetCommand(t *testing.T) {
+	f := newFakeKoolPresetWithVariables()
+	f.promptInput.(*shell.FakePromptInput).MockAnswer = map[string]string{
+		"What is the project name": "blog",
+	}
+	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{
+		"What php_version do you want to use": "8.0",
+	}
+
+	cmd := NewPresetCommand(f)
+	cmd.SetArgs([]string{"custom"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing preset command; error: %v", err)
+	}
+
+	if f.out.(*shell.FakeOutputWriter).CalledError {
+		t.Errorf("unexpected error: %v", f.out.(*shell.FakeOutputWriter).Err)
+	}
+
+	if !f.presetsParser.(*presets.FakeParser).CalledWriteFile["kool.yml"]["# blog on php 8.0"] {
+		t.Error("failed writing kool.yml rendered with the prompted variables")
+	}
+
+	if _, ok := f.presetsParser.(*presets.FakeParser).CalledWriteFile["preset.yml"]; ok {
+		t.Error("should not write the preset manifest")
+	}
+}
+
+func TestSetVariablesPresetCommand(t *testing.T) {
+	f := newFakeKoolPresetWithVariables()
+	f.DefaultKoolService.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+
+	cmd := NewPresetCommand(f)
+	cmd.SetArgs([]string{"custom", "--set", "php_version=8.0"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing preset command; error: %v", err)
+	}
+
+	if f.promptSelect.(*shell.FakePromptSelect).CalledAsk || len(f.promptInput.(*shell.FakePromptInput).CalledInput) > 0 {
+		t.Error("should not prompt for variables on non-tty environments")
+	}
+
+	if !f.presetsParser.(*presets.FakeParser).CalledWriteFile["kool.yml"]["# my-app on php 8.0"] {
+		t.Error("failed writing kool.yml rendered with the set and default variables")
+	}
+}
+
+func TestInvalidSetVariablesPresetCommand(t *testing.T) {
+	cases := map[string]string{
+		"php_version":     "invalid variable php_version; expected format is key=value",
+		"node_version=14": "unknown variable node_version for preset custom",
+		"php_version=5.6": "invalid value 5.6 for variable php_version; options are: 7.4, 8.0",
+	}
+
+	for set, expected := range cases {
+		f := newFakeKoolPresetWithVariables()
+
+		cmd := NewPresetCommand(f)
+		cmd.SetArgs([]string{"custom", "--set", set})
+
+		if err := cmd.Execute(); err != nil {
+			t.Errorf("unexpected error executing preset command; error: %v", err)
+		}
+
+		if err := f.out.(*shell.FakeOutputWriter).Err; err == nil || err.Error() != expected {
+			t.Errorf("expecting error '%s', got %v", expected, err)
+		}
+	}
+}
+
+func TestMissingVariableNonTTYPresetCommand(t *testing.T) {
+	f := newFakeKoolPresetWithVariables()
+	f.DefaultKoolService.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+	f.presetsParser.(*presets.FakeParser).MockManifest["custom"].Variables = []*presets.Variable{{Name: "app_port"}}
+
+	cmd := NewPresetCommand(f)
+	cmd.SetArgs([]string{"custom"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing preset command; error: %v", err)
+	}
+
+	expected := "missing value for variable app_port; for non-tty environments use --set app_port=VALUE"
+
+	if err := f.out.(*shell.FakeOutputWriter).Err; err == nil || err.Error() != expected {
+		t.Errorf("expecting error '%s', got %v", expected, err)
+	}
+}
+
+const postgresTemplate string = `name: PostgreSQL 13.0
+category: database
+service:
+  image: postgres:13-alpine
+`
+
+func newFakeKoolPresetWithDatabaseQuestion() *KoolPreset {
+	f := newFakeKoolPreset()
+	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockManifest = newDatabaseQuestionManifest()
+	f.presetsParser.(*presets.FakeParser).MockPresetKeyContent = map[string]map[string]string{
+		"laravel": map[string]string{
+			"docker-compose.yml": defaultCompose,
+		},
+	}
+	f.presetsParser.(*presets.FakeParser).MockPresetKeys = []string{"docker-compose.yml"}
+	f.presetsParser.(*presets.FakeParser).MockTemplates = map[string]map[string]string{
+		"database": map[string]string{
+			"mysql80.yml":       mysqlTemplate,
+			"postgresql130.yml": postgresTemplate,
+		},
+	}
+	return f
+}
+
+func TestServiceFlagPresetCommand(t *testing.T) {
+	f := newFakeKoolPresetWithDatabaseQuestion()
+	f.DefaultKoolService.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+
+	cmd := NewPresetCommand(f)
+
+	cmd.SetArgs([]string{"laravel", "--database=postgresql13"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing preset command; error: %v", err)
+	}
+
+	if f.out.(*shell.FakeOutputWriter).CalledError {
+		t.Errorf("unexpected error: %v", f.out.(*shell.FakeOutputWriter).Err)
+	}
+
+	if f.promptSelect.(*shell.FakePromptSelect).CalledAsk {
+		t.Error("unexpected prompt for the database service")
+	}
+
+	if !f.composeParser.(*compose.FakeParser).CalledSetService["database"]["image: postgres:13-alpine\n"] {
+		t.Errorf("did not set the database service to the postgresql template; called: %v", f.composeParser.(*compose.FakeParser).CalledSetService)
+	}
+}
+
+func TestAnswersFilePresetCommand(t *testing.T) {
+	f := newFakeKoolPresetWithDatabaseQuestion()
+	f.presetsParser.(*presets.FakeParser).MockFiles = map[string]string{
+		"answers.yml": "preset: laravel\nservices:\n  database: none\n",
+	}
+
+	cmd := NewPresetCommand(f)
+
+	cmd.SetArgs([]string{"--answers", "answers.yml"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing preset command; error: %v", err)
+	}
+
+	if f.out.(*shell.FakeOutputWriter).CalledError {
+		t.Errorf("unexpected error: %v", f.out.(*shell.FakeOutputWriter).Err)
+	}
+
+	if f.promptSelect.(*shell.FakePromptSelect).CalledAsk {
+		t.Error("unexpected prompt with an answers file")
+	}
+
+	if !f.composeParser.(*compose.FakeParser).CalledRemoveService["database"] {
+		t.Error("did not remove the database service answered as none")
+	}
+}
+
+func TestFlagOverridesAnswersFilePresetCommand(t *testing.T) {
+	f := newFakeKoolPresetWithDatabaseQuestion()
+	f.presetsParser.(*presets.FakeParser).MockFiles = map[string]string{
+		"answers.yml": "services:\n  database: none\n",
+	}
+
+	cmd := NewPresetCommand(f)
+
+	cmd.SetArgs([]string{"laravel", "--answers", "answers.yml", "--database", "MySQL 8.0"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing preset command; error: %v", err)
+	}
+
+	if f.composeParser.(*compose.FakeParser).CalledRemoveService["database"] {
+		t.Error("unexpected database service removal; the flag should take precedence")
+	}
+
+	if !f.composeParser.(*compose.FakeParser).CalledSetService["database"][mysqlService] {
+		t.Error("did not set the database service to the mysql template")
+	}
+}
+
+func TestInvalidServiceFlagPresetCommand(t *testing.T) {
+	f := newFakeKoolPresetWithDatabaseQuestion()
+
+	cmd := NewPresetCommand(f)
+
+	cmd.SetArgs([]string{"laravel", "--database", "oracle"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing preset command; error: %v", err)
+	}
+
+	expected := "invalid option oracle for database service; options are: mysql80, postgresql130, none"
+
+	if err := f.out.(*shell.FakeOutputWriter).Err; err == nil || err.Error() != expected {
+		t.Errorf("expecting error '%s', got '%v'", expected, err)
+	}
+}
+
+func TestUnknownServiceAnswerPresetCommand(t *testing.T) {
+	f := newFakeKoolPresetWithDatabaseQuestion()
+
+	cmd := NewPresetCommand(f)
+
+	cmd.SetArgs([]string{"laravel", "--cache", "redis"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing preset command; error: %v", err)
+	}
+
+	expected := "preset laravel has no cache service to choose"
+
+	if err := f.out.(*shell.FakeOutputWriter).Err; err == nil || err.Error() != expected {
+		t.Errorf("expecting error '%s', got '%v'", expected, err)
+	}
+}
+
+func TestMissingAnswersFilePresetCommand(t *testing.T) {
+	f := newFakeKoolPresetWithDatabaseQuestion()
+
+	cmd := NewPresetCommand(f)
+
+	cmd.SetArgs([]string{"laravel", "--answers", "missing.yml"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing preset command; error: %v", err)
+	}
+
+	if err := f.out.(*shell.FakeOutputWriter).Err; err == nil || !strings.HasPrefix(err.Error(), "failed reading answers file") {
+		t.Errorf("expecting error reading the answers file, got '%v'", err)
+	}
+}
+
+func TestLoadAllPresetsSkipsInvalidPresets(t *testing.T) {
+	out := &shell.FakeOutputWriter{}
+	parser := &presets.FakeParser{}
+	parser.MockLoadFolderError = presets.InvalidPresetsError{errors.New("preset bad: invalid preset.yml")}
+
+	if err := loadAllPresets(out, parser, environment.NewFakeEnvStorage()); err != nil {
+		t.Errorf("unexpected error loading presets with an invalid external preset: %v", err)
+	}
+
+	if !parser.CalledLoadPresets || !parser.CalledLoadTemplates {
+		t.Error("built-in presets and templates should be loaded")
+	}
+
+	if !out.CalledWarning || !strings.Contains(fmt.Sprint(out.WarningOutput...), "preset bad") {
+		t.Errorf("expected a warning naming the invalid preset; got %v", out.WarningOutput)
+	}
+
+	parser.MockLoadFolderError = errors.New("read error")
+
+	if err := loadAllPresets(out, parser, environment.NewFakeEnvStorage()); err == nil || !strings.Contains(err.Error(), "read error") {
+		t.Errorf("expected error loading presets folder; got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"kool-dev/kool/cmd/presets"
+	"kool-dev/kool/diff"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolPresetUpgrade holds handlers and functions to implement the preset upgrade command logic
+type KoolPresetUpgrade struct {
+	DefaultKoolService
+	preset *KoolPreset
+}
+
+// NewKoolPresetUpgrade creates a new handler for preset upgrade logic with default dependencies
+func NewKoolPresetUpgrade() *KoolPresetUpgrade {
+	return &KoolPresetUpgrade{
+		*newDefaultKoolService(),
+		NewKoolPreset(),
+	}
+}
+
+// Execute runs the preset upgrade logic with incoming arguments.
+func (u *KoolPresetUpgrade) Execute(args []string) (err error) {
+	var (
+		lock      *presets.Lock
+		files     map[string]string
+		conflicts int
+	)
+
+	if lock, files, err = u.preset.renderFromLock(nil); err != nil {
+		return
+	}
+
+	for _, fileName := range sortedKeys(files) {
+		var (
+			fileConflicts int
+			fileErr       error
+		)
+
+		if fileConflicts, fileErr = u.upgradeFile(lock, fileName, files[fileName]); fileErr != nil {
+			err = fileErr
+			return
+		}
+
+		conflicts += fileConflicts
+	}
+
+	// the current preset output becomes the base for the next upgrade
+	lock.Files = files
+
+	if err = u.preset.writeLock(lock); err != nil {
+		return
+	}
+
+	if conflicts > 0 {
+		err = fmt.Errorf("%d conflict(s) merging preset %s; fix them and remove the conflict markers", conflicts, lock.Preset)
+		return
+	}
+
+	u.Success("Project upgraded to the current version of preset ", lock.Preset)
+	return
+}
+
+// upgradeFile three-way merges the project file with the current
+// preset content, based on the preset content it was generated from.
+func (u *KoolPresetUpgrade) upgradeFile(lock *presets.Lock, fileName string, content string) (conflicts int, err error) {
+	var (
+		local, merged string
+		base, hasBase = lock.Files[fileName]
+	)
+
+	if local, err = u.preset.presetsParser.ReadFile(fileName); os.IsNotExist(err) {
+		err = nil
+
+		if hasBase {
+			u.Warning("Skipping ", fileName, ": it was removed from the project")
+			return
+		}
+
+		if err = u.preset.writeFile(fileName, content); err == nil {
+			u.Println("Added", fileName)
+		}
+		return
+	} else if err != nil {
+		return
+	}
+
+	if local == content {
+		return
+	}
+
+	if merged, conflicts = diff.Merge(base, local, content, "local", "preset "+lock.Preset); merged != local {
+		if err = u.preset.writeFile(fileName, merged); err != nil {
+			return
+		}
+	}
+
+	if conflicts > 0 {
+		u.Warning("Conflicts merging ", fileName, ", marked in the file")
+	} else if merged != local {
+		u.Println("Updated", fileName)
+	}
+
+	return
+}
+
+// NewPresetUpgradeCommand initializes new kool preset upgrade command
+func NewPresetUpgradeCommand(upgrade *KoolPresetUpgrade) (upgradeCmd *cobra.Command) {
+	upgradeCmd = &cobra.Command{
+		Use:   "upgrade",
+		Short: "Merge the current preset version into the project files, keeping local changes",
+		Long: `Three-way merge the project files with what the current version of the
+preset generates, based on the version recorded in kool-preset.lock. Changes
+conflicting with local ones are marked in the files for manual resolution.`,
+		Args: cobra.NoArgs,
+		Run:  DefaultCommandRunFunction(upgrade),
+	}
+
+	return
+}
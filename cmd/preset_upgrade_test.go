@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"kool-dev/kool/cmd/presets"
+	"kool-dev/kool/cmd/shell"
+	"strings"
+	"testing"
+)
+
+func newFakeKoolPresetUpgrade(local string, preset string) *KoolPresetUpgrade {
+	presetCmd := newFakeKoolPreset()
+	presetCmd.presetsParser.(*presets.FakeParser).MockExists = true
+	presetCmd.presetsParser.(*presets.FakeParser).MockPresetKeys = []string{"kool.yml", "Dockerfile.build"}
+	presetCmd.presetsParser.(*presets.FakeParser).MockPresetKeyContent = map[string]map[string]string{
+		"laravel": map[string]string{
+			"kool.yml":         preset,
+			"Dockerfile.build": "FROM kooldev/php:7.4\n",
+		},
+	}
+	presetCmd.presetsParser.(*presets.FakeParser).MockFiles = map[string]string{
+		"kool-preset.lock": presetLock,
+		"kool.yml":         local,
+	}
+
+	return &KoolPresetUpgrade{
+		*newFakeKoolService(),
+		presetCmd,
+	}
+}
+
+func TestNewKoolPresetUpgrade(t *testing.T) {
+	k := NewKoolPresetUpgrade()
+
+	if _, ok := k.preset.presetsParser.(*presets.DefaultParser); !ok {
+		t.Errorf("unexpected presets.Parser on default KoolPresetUpgrade instance")
+	}
+}
+
+func TestPresetUpgradeCommand(t *testing.T) {
+	local := "scripts:\n  setup: kool start\n  npm: kool docker npm\n  artisan: kool exec app php artisan\n"
+	f := newFakeKoolPresetUpgrade(local, "scripts:\n  setup: kool start --foreground\n  npm: kool docker npm\n")
+	f.preset.presetsParser.(*presets.FakeParser).MockFiles["kool-preset.lock"] = `preset: laravel
+files:
+  kool.yml: |
+    scripts:
+      setup: kool start
+      npm: kool docker npm
+`
+
+	if err := f.Execute(nil); err != nil {
+		t.Fatalf("unexpected error executing preset upgrade: %v", err)
+	}
+
+	parser := f.preset.presetsParser.(*presets.FakeParser)
+
+	if !parser.CalledWriteFile["kool.yml"]["scripts:\n  setup: kool start --foreground\n  npm: kool docker npm\n  artisan: kool exec app php artisan\n"] {
+		t.Errorf("failed merging kool.yml; written: %v", parser.CalledWriteFile["kool.yml"])
+	}
+
+	if !parser.CalledWriteFile["Dockerfile.build"]["FROM kooldev/php:7.4\n"] {
+		t.Error("failed adding the new preset file Dockerfile.build")
+	}
+
+	for lock := range parser.CalledWriteFile["kool-preset.lock"] {
+		if !strings.Contains(lock, "setup: kool start --foreground") {
+			t.Errorf("lock file was not updated with the new base: %s", lock)
+		}
+	}
+
+	if !f.out.(*shell.FakeOutputWriter).CalledSuccess {
+		t.Error("did not call Success")
+	}
+}
+
+func TestPresetUpgradeConflictsCommand(t *testing.T) {
+	f := newFakeKoolPresetUpgrade("scripts:\n  setup: kool restart\n", "scripts:\n  setup: kool start --foreground\n")
+
+	err := f.Execute(nil)
+
+	if err == nil || err.Error() != "1 conflict(s) merging preset laravel; fix them and remove the conflict markers" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	expected := "scripts:\n<<<<<<< local\n  setup: kool restart\n=======\n  setup: kool start --foreground\n>>>>>>> preset laravel\n"
+
+	if !f.preset.presetsParser.(*presets.FakeParser).CalledWriteFile["kool.yml"][expected] {
+		t.Errorf("failed writing conflicts; written: %v", f.preset.presetsParser.(*presets.FakeParser).CalledWriteFile["kool.yml"])
+	}
+
+	if _, ok := f.preset.presetsParser.(*presets.FakeParser).CalledWriteFile["kool-preset.lock"]; !ok {
+		t.Error("lock file should be updated even with conflicts")
+	}
+}
+
+func TestPresetUpgradeRemovedFileCommand(t *testing.T) {
+	f := newFakeKoolPresetUpgrade("", "scripts:\n  setup: kool start --foreground\n")
+	delete(f.preset.presetsParser.(*presets.FakeParser).MockFiles, "kool.yml")
+
+	if err := f.Execute(nil); err != nil {
+		t.Fatalf("unexpected error executing preset upgrade: %v", err)
+	}
+
+	if _, ok := f.preset.presetsParser.(*presets.FakeParser).CalledWriteFile["kool.yml"]; ok {
+		t.Error("should not recreate a preset file removed from the project")
+	}
+
+	if !f.out.(*shell.FakeOutputWriter).CalledWarning {
+		t.Error("did not warn about the removed file")
+	}
+}
+
+func TestPresetUpgradeWithoutLockCommand(t *testing.T) {
+	f := newFakeKoolPresetUpgrade("", "")
+	delete(f.preset.presetsParser.(*presets.FakeParser).MockFiles, "kool-preset.lock")
+
+	if err := f.Execute(nil); err != ErrPresetLockNotFound {
+		t.Errorf("expected error %v; got %v", ErrPresetLockNotFound, err)
+	}
+}
@@ -0,0 +1,44 @@
+package presets
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Answers holds the answers to the preset prompts, so a
+// preset can be initialized without any interaction.
+type Answers struct {
+	Language  string            `yaml:"language"`
+	Preset    string            `yaml:"preset"`
+	Services  map[string]string `yaml:"services"`
+	Variables map[string]string `yaml:"variables"`
+}
+
+// ParseAnswers parses the content of an answers file
+func ParseAnswers(content string) (answers *Answers, err error) {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	decoder.KnownFields(true)
+
+	answers = new(Answers)
+
+	// an empty content has no document to decode
+	if err = decoder.Decode(answers); err == io.EOF {
+		err = nil
+	} else if err != nil {
+		err = fmt.Errorf("invalid answers file: %v", err)
+		return
+	}
+
+	if answers.Services == nil {
+		answers.Services = make(map[string]string)
+	}
+
+	if answers.Variables == nil {
+		answers.Variables = make(map[string]string)
+	}
+
+	return
+}
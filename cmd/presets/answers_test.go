@@ -0,0 +1,50 @@
+package presets
+
+import (
+	"testing"
+)
+
+func TestParseAnswers(t *testing.T) {
+	answers, err := ParseAnswers(`language: php
+preset: laravel
+services:
+  database: postgresql13
+  cache: none
+variables:
+  app_name: my-app
+`)
+
+	if err != nil {
+		t.Fatalf("unexpected error parsing answers: %v", err)
+	}
+
+	if answers.Language != "php" || answers.Preset != "laravel" {
+		t.Errorf("unexpected language %s and preset %s", answers.Language, answers.Preset)
+	}
+
+	if answers.Services["database"] != "postgresql13" || answers.Services["cache"] != "none" {
+		t.Errorf("unexpected services answers: %v", answers.Services)
+	}
+
+	if answers.Variables["app_name"] != "my-app" {
+		t.Errorf("unexpected variables answers: %v", answers.Variables)
+	}
+}
+
+func TestParseEmptyAnswers(t *testing.T) {
+	answers, err := ParseAnswers("")
+
+	if err != nil {
+		t.Fatalf("unexpected error parsing empty answers: %v", err)
+	}
+
+	if answers.Services == nil || answers.Variables == nil {
+		t.Error("expecting empty services and variables answers")
+	}
+}
+
+func TestParseInvalidAnswers(t *testing.T) {
+	if _, err := ParseAnswers("database: mysql80\n"); err == nil {
+		t.Error("expecting error parsing answers with unknown keys")
+	}
+}
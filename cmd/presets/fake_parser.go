@@ -1,24 +1,36 @@
 package presets
 
+import "os"
+
 // FakeParser implements all fake behaviors for using parser in tests.
 type FakeParser struct {
 	CalledExists              bool
 	CalledLookUpFiles         bool
 	CalledWriteFile           map[string]map[string]bool
+	CalledReadFile            map[string]bool
+	CalledRemoveFile          map[string]bool
 	CalledGetPresets          bool
 	CalledGetLanguages        bool
 	CalledGetPresetKeys       bool
 	CalledGetPresetKeyContent map[string]map[string]bool
 	CalledGetTemplates        bool
 	CalledGetCreateCommand    bool
+	CalledGetManifest         bool
 	CalledLoadPresets         bool
+	CalledLoadPresetsFolder   map[string]bool
 	CalledLoadTemplates       bool
 
 	MockExists           bool
 	MockFoundFiles       []string
 	MockFileError        string
+	MockFiles            map[string]string
 	MockError            error
+	MockWriteErrors      map[string]error
+	MockRemoveError      error
+	MockLoadFolderError  error
 	MockCreateCommand    string
+	MockManifest         map[string]*Manifest
+	MockManifestError    error
 	MockLanguages        []string
 	MockPresets          []string
 	MockPresetKeys       []string
@@ -42,6 +54,18 @@ func (f *FakeParser) GetCreateCommand(preset string) (cmd string, err error) {
 	return
 }
 
+// GetManifest gets the preset manifest
+func (f *FakeParser) GetManifest(preset string) (manifest *Manifest, err error) {
+	f.CalledGetManifest = true
+
+	if manifest = f.MockManifest[preset]; manifest == nil {
+		manifest = new(Manifest)
+	}
+
+	err = f.MockManifestError
+	return
+}
+
 // GetLanguages get all presets languages
 func (f *FakeParser) GetLanguages() (languages []string) {
 	f.CalledGetLanguages = true
@@ -76,6 +100,40 @@ func (f *FakeParser) WriteFile(fileName string, fileContent string) (fileError s
 	f.CalledWriteFile[fileName][fileContent] = true
 	fileError = f.MockFileError
 	err = f.MockError
+
+	if writeErr, hasError := f.MockWriteErrors[fileName]; hasError {
+		fileError = fileName
+		err = writeErr
+	}
+
+	return
+}
+
+// ReadFile reads the content of a project file
+func (f *FakeParser) ReadFile(fileName string) (content string, err error) {
+	var found bool
+
+	if f.CalledReadFile == nil {
+		f.CalledReadFile = make(map[string]bool)
+	}
+
+	f.CalledReadFile[fileName] = true
+
+	if content, found = f.MockFiles[fileName]; !found {
+		err = &os.PathError{Op: "open", Path: fileName, Err: os.ErrNotExist}
+	}
+
+	return
+}
+
+// RemoveFile removes the project file
+func (f *FakeParser) RemoveFile(fileName string) (err error) {
+	if f.CalledRemoveFile == nil {
+		f.CalledRemoveFile = make(map[string]bool)
+	}
+
+	f.CalledRemoveFile[fileName] = true
+	err = f.MockRemoveError
 	return
 }
 
@@ -117,6 +175,17 @@ func (f *FakeParser) LoadPresets(presets map[string]map[string]string) {
 	f.MockAllPresets = presets
 }
 
+// LoadPresetsFolder loads presets from folder
+func (f *FakeParser) LoadPresetsFolder(folder string) (err error) {
+	if f.CalledLoadPresetsFolder == nil {
+		f.CalledLoadPresetsFolder = make(map[string]bool)
+	}
+
+	f.CalledLoadPresetsFolder[folder] = true
+	err = f.MockLoadFolderError
+	return
+}
+
 //LoadTemplates loads all templates
 func (f *FakeParser) LoadTemplates(templates map[string]map[string]string) {
 	f.CalledLoadTemplates = true
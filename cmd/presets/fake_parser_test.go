@@ -2,6 +2,7 @@ package presets
 
 import (
 	"errors"
+	"os"
 	"reflect"
 	"testing"
 )
@@ -99,4 +100,43 @@ func TestFakeParser(t *testing.T) {
 	if !f.CalledLoadTemplates || !reflect.DeepEqual(allTemplates, f.MockAllTemplates) {
 		t.Error("failed to use mocked LoadTemplates function on FakeParser")
 	}
+
+	f.MockFiles = map[string]string{"kool.yml": "scripts:"}
+
+	if content, err := f.ReadFile("kool.yml"); !f.CalledReadFile["kool.yml"] || err != nil || content != "scripts:" {
+		t.Error("failed to use mocked ReadFile function on FakeParser")
+	}
+
+	if _, err := f.ReadFile("missing.yml"); !os.IsNotExist(err) {
+		t.Error("failed to use mocked ReadFile function on FakeParser for missing file")
+	}
+
+	f.MockWriteErrors = map[string]error{".env": errors.New("write error")}
+
+	if fileError, err := f.WriteFile(".env", "KEY=value"); fileError != ".env" || err != f.MockWriteErrors[".env"] {
+		t.Error("failed to use mocked WriteFile errors on FakeParser")
+	}
+
+	f.MockRemoveError = errors.New("remove error")
+
+	if err := f.RemoveFile("kool.yml"); !f.CalledRemoveFile["kool.yml"] || err != f.MockRemoveError {
+		t.Error("failed to use mocked RemoveFile function on FakeParser")
+	}
+
+	f.MockManifest = map[string]*Manifest{"preset": {Language: "php"}}
+	manifest, _ := f.GetManifest("preset")
+
+	if !f.CalledGetManifest || manifest.Language != "php" {
+		t.Error("failed to use mocked GetManifest function on FakeParser")
+	}
+
+	if manifest, _ = f.GetManifest("other"); manifest == nil {
+		t.Error("failed to use mocked GetManifest function on FakeParser for not mocked preset")
+	}
+
+	f.MockLoadFolderError = errors.New("load error")
+
+	if err := f.LoadPresetsFolder("/presets"); !f.CalledLoadPresetsFolder["/presets"] || err != f.MockLoadFolderError {
+		t.Error("failed to use mocked LoadPresetsFolder function on FakeParser")
+	}
 }
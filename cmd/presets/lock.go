@@ -0,0 +1,60 @@
+package presets
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PresetLockFile is the file recording how the project preset was
+// initialized, so it can later be diffed and upgraded.
+const PresetLockFile string = "kool-preset.lock"
+
+const lockHeader string = `# Generated by kool preset - keep it under version control, it's
+# the base for merging preset updates with "kool preset upgrade".
+`
+
+// Lock holds the preset answers along with the files contents
+// as generated, which are the base for three-way merges.
+type Lock struct {
+	Preset    string            `yaml:"preset"`
+	Services  map[string]string `yaml:"services,omitempty"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+	Files     map[string]string `yaml:"files"`
+}
+
+// ParseLock parses the preset lock file content
+func ParseLock(content string) (lock *Lock, err error) {
+	lock = new(Lock)
+
+	if err = yaml.Unmarshal([]byte(content), lock); err != nil {
+		err = fmt.Errorf("invalid %s: %v", PresetLockFile, err)
+		return
+	}
+
+	if lock.Preset == "" {
+		err = fmt.Errorf("invalid %s: missing preset", PresetLockFile)
+	}
+
+	return
+}
+
+// String renders the preset lock file content
+func (l *Lock) String() (content string, err error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err = encoder.Encode(l); err != nil {
+		return
+	}
+
+	if err = encoder.Close(); err != nil {
+		return
+	}
+
+	content = lockHeader + buf.String()
+	return
+}
@@ -0,0 +1,42 @@
+package presets
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLock(t *testing.T) {
+	lock := &Lock{
+		Preset:    "laravel",
+		Services:  map[string]string{"database": "MySQL 8.0"},
+		Variables: map[string]string{"app_port": "80"},
+		Files: map[string]string{
+			"kool.yml":           "scripts:\n  setup: kool start\n",
+			"docker-compose.yml": "version: \"3.7\"",
+		},
+	}
+
+	content, err := lock.String()
+
+	if err != nil {
+		t.Fatalf("unexpected error rendering lock: %v", err)
+	}
+
+	parsed, err := ParseLock(content)
+
+	if err != nil {
+		t.Fatalf("unexpected error parsing lock: %v", err)
+	}
+
+	if !reflect.DeepEqual(parsed, lock) {
+		t.Errorf("expected lock %v; got %v", lock, parsed)
+	}
+}
+
+func TestParseLockInvalid(t *testing.T) {
+	for _, content := range []string{"preset: [", "files: {}"} {
+		if _, err := ParseLock(content); err == nil {
+			t.Errorf("expected error parsing lock %q", content)
+		}
+	}
+}
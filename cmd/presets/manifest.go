@@ -0,0 +1,290 @@
+package presets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PresetManifestFile is the file within a preset holding its manifest;
+// it's never written to the project.
+const PresetManifestFile string = "preset.yml"
+
+// Manifest holds the preset.yml declarations
+type Manifest struct {
+	Language    string       `yaml:"language"`
+	Description string       `yaml:"description"`
+	Create      string       `yaml:"create"`
+	Questions   []*Question  `yaml:"questions"`
+	Variables   []*Variable  `yaml:"variables"`
+	PostInstall []string     `yaml:"post_install"`
+	Setup       []*SetupStep `yaml:"setup"`
+}
+
+// SetupStep is run by kool create to set up the new project; it
+// either runs a command or waits for a service to be ready, being
+// retried up to Retries times until it succeeds.
+type SetupStep struct {
+	Name    string `yaml:"name"`
+	Run     string `yaml:"run"`
+	Wait    string `yaml:"wait"`
+	Retries int    `yaml:"retries"`
+}
+
+// Question asks the user which template to use for a
+// service of the preset docker-compose.yml.
+type Question struct {
+	Service  string    `yaml:"service"`
+	Question string    `yaml:"question"`
+	Options  []*Option `yaml:"options"`
+}
+
+// Option is an answer to a service question; an option
+// without template removes the service from the preset.
+type Option struct {
+	Name     string `yaml:"name"`
+	Template string `yaml:"template"`
+}
+
+// Variable is a value asked to the user and made
+// available to the preset files templates.
+type Variable struct {
+	Name     string   `yaml:"name"`
+	Question string   `yaml:"question"`
+	Default  string   `yaml:"default"`
+	Options  []string `yaml:"options"`
+}
+
+var variableNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseManifest parses the preset.yml content; an empty
+// content results in an empty manifest.
+func ParseManifest(content string) (manifest *Manifest, err error) {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	decoder.KnownFields(true)
+
+	manifest = new(Manifest)
+
+	// an empty content has no document to decode
+	if err = decoder.Decode(manifest); err == io.EOF {
+		err = nil
+	} else if err != nil {
+		err = fmt.Errorf("invalid %s: %v", PresetManifestFile, err)
+		return
+	}
+
+	for _, question := range manifest.Questions {
+		if question.Question == "" {
+			question.Question = fmt.Sprintf("What %s service do you want to use", question.Service)
+		}
+	}
+
+	for _, step := range manifest.Setup {
+		if (step.Run == "") == (step.Wait == "") {
+			err = fmt.Errorf("invalid %s: setup steps must either run a command or wait for a service", PresetManifestFile)
+			return
+		}
+
+		if step.Name == "" && step.Run != "" {
+			step.Name = fmt.Sprintf("Running %s", step.Run)
+		} else if step.Name == "" {
+			step.Name = fmt.Sprintf("Waiting for %s service", step.Wait)
+		}
+	}
+
+	for _, variable := range manifest.Variables {
+		if !variableNameRegex.MatchString(variable.Name) {
+			err = fmt.Errorf("invalid %s: bad variable name '%s'", PresetManifestFile, variable.Name)
+			return
+		}
+
+		if variable.Question == "" {
+			variable.Question = fmt.Sprintf("What %s do you want to use", variable.Name)
+		}
+	}
+
+	return
+}
+
+// Validate checks the manifest is complete and consistent; hasTemplate
+// tells whether a template path (like database/mysql80.yml) exists.
+func (m *Manifest) Validate(hasTemplate func(string) bool) (err error) {
+	var problems []string
+
+	if m.Language == "" {
+		problems = append(problems, "missing language")
+	}
+
+	for _, question := range m.Questions {
+		if question.Service == "" {
+			problems = append(problems, "question without service")
+		}
+
+		if len(question.Options) == 0 {
+			problems = append(problems, fmt.Sprintf("no options for %s service question", question.Service))
+		}
+
+		for _, option := range question.Options {
+			if option.Name == "" {
+				problems = append(problems, fmt.Sprintf("option without name for %s service question", question.Service))
+			}
+
+			if option.Template != "" && !hasTemplate(option.Template) {
+				problems = append(problems, fmt.Sprintf("unknown template %s for %s service option %s", option.Template, question.Service, option.Name))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		err = fmt.Errorf("invalid %s: %s", PresetManifestFile, strings.Join(problems, "; "))
+	}
+
+	return
+}
+
+// OptionsNames lists the names of the question options
+func (q *Question) OptionsNames() (names []string) {
+	for _, option := range q.Options {
+		names = append(names, option.Name)
+	}
+	return
+}
+
+// Option looks up the question option by its name
+func (q *Question) Option(name string) *Option {
+	for _, option := range q.Options {
+		if option.Name == name {
+			return option
+		}
+	}
+	return nil
+}
+
+// FindOption looks up the question option by its name or key,
+// the key also matching by an unambiguous prefix.
+func (q *Question) FindOption(value string) *Option {
+	var (
+		matched []*Option
+		key     = optionKey(value)
+	)
+
+	if option := q.Option(value); option != nil {
+		return option
+	}
+
+	for _, option := range q.Options {
+		if option.Key() == key {
+			return option
+		}
+
+		if key != "" && strings.HasPrefix(option.Key(), key) {
+			matched = append(matched, option)
+		}
+	}
+
+	if len(matched) == 1 {
+		return matched[0]
+	}
+
+	return nil
+}
+
+// OptionsKeys lists the keys of the question options
+func (q *Question) OptionsKeys() (keys []string) {
+	for _, option := range q.Options {
+		keys = append(keys, option.Key())
+	}
+	return
+}
+
+// Key identifies the option on the command line; it's the
+// template file name, or the option name for no template.
+func (o *Option) Key() string {
+	if o.Template != "" {
+		return optionKey(strings.TrimSuffix(path.Base(o.Template), path.Ext(o.Template)))
+	}
+
+	return optionKey(o.Name)
+}
+
+func optionKey(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, value)
+}
+
+// Question looks up the question by its service
+func (m *Manifest) Question(service string) *Question {
+	for _, question := range m.Questions {
+		if question.Service == service {
+			return question
+		}
+	}
+
+	return nil
+}
+
+// Variable looks up the variable by its name
+func (m *Manifest) Variable(name string) *Variable {
+	for _, variable := range m.Variables {
+		if variable.Name == name {
+			return variable
+		}
+	}
+
+	return nil
+}
+
+// ErrTemplateNotFound error throwed when a template path is not found
+var ErrTemplateNotFound = errors.New("template not found")
+
+// FindTemplate gets the content of the template at the given
+// path, made of the service type and the template file name.
+func FindTemplate(templates map[string]map[string]string, path string) (content string, err error) {
+	var (
+		pieces = strings.SplitN(path, "/", 2)
+		found  bool
+	)
+
+	if len(pieces) == 2 {
+		content, found = templates[pieces[0]][pieces[1]]
+	}
+
+	if !found {
+		err = fmt.Errorf("%w: %s", ErrTemplateNotFound, path)
+	}
+
+	return
+}
+
+// RenderFile renders the preset file content as a text/template
+// with the given variables values; referencing a variable with
+// no value is an error.
+func RenderFile(fileName string, content string, values map[string]string) (rendered string, err error) {
+	var (
+		tmpl *template.Template
+		buf  bytes.Buffer
+	)
+
+	if tmpl, err = template.New(fileName).Option("missingkey=error").Parse(content); err != nil {
+		return
+	}
+
+	if err = tmpl.Execute(&buf, values); err != nil {
+		return
+	}
+
+	rendered = buf.String()
+	return
+}
@@ -0,0 +1,213 @@
+package presets
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseManifest(t *testing.T) {
+	manifest, err := ParseManifest(`variables:
+  - name: app_port
+    default: "80"
+  - name: php_version
+    question: Which PHP version
+    options: ["7.4", "8.0"]
+`)
+
+	if err != nil {
+		t.Fatalf("unexpected error parsing manifest: %v", err)
+	}
+
+	if len(manifest.Variables) != 2 {
+		t.Fatalf("expected 2 variables; got %d", len(manifest.Variables))
+	}
+
+	if port := manifest.Variable("app_port"); port == nil || port.Default != "80" || port.Question != "What app_port do you want to use" {
+		t.Errorf("unexpected app_port variable: %v", port)
+	}
+
+	if php := manifest.Variable("php_version"); php == nil || php.Question != "Which PHP version" || len(php.Options) != 2 {
+		t.Errorf("unexpected php_version variable: %v", php)
+	}
+
+	if manifest.Variable("missing") != nil {
+		t.Error("unexpected variable found")
+	}
+
+	if manifest, err = ParseManifest(""); err != nil || len(manifest.Variables) != 0 {
+		t.Errorf("expected empty manifest; got %v (err: %v)", manifest, err)
+	}
+}
+
+func TestParseManifestSetup(t *testing.T) {
+	manifest, err := ParseManifest(`setup:
+  - run: kool start
+  - wait: database
+  - name: Installing dependencies
+    run: kool run composer install
+    retries: 2
+`)
+
+	if err != nil {
+		t.Fatalf("unexpected error parsing manifest: %v", err)
+	}
+
+	expected := []SetupStep{
+		{Name: "Running kool start", Run: "kool start"},
+		{Name: "Waiting for database service", Wait: "database"},
+		{Name: "Installing dependencies", Run: "kool run composer install", Retries: 2},
+	}
+
+	if len(manifest.Setup) != len(expected) {
+		t.Fatalf("expected %d setup steps; got %d", len(expected), len(manifest.Setup))
+	}
+
+	for i, step := range manifest.Setup {
+		if *step != expected[i] {
+			t.Errorf("expected setup step %v; got %v", expected[i], *step)
+		}
+	}
+}
+
+func TestParseManifestInvalid(t *testing.T) {
+	for _, content := range []string{
+		"variables:\n  - name: app-port\n",
+		"variables:\n  - name: port\n    unknown: key\n",
+		"variables: [",
+		"setup:\n  - name: nothing\n",
+		"setup:\n  - run: kool start\n    wait: app\n",
+	} {
+		if _, err := ParseManifest(content); err == nil {
+			t.Errorf("expected error parsing manifest %q", content)
+		}
+	}
+}
+
+func TestRenderFile(t *testing.T) {
+	rendered, err := RenderFile("kool.yml", "port: {{ .app_port }}", map[string]string{"app_port": "8080"})
+
+	if err != nil || rendered != "port: 8080" {
+		t.Errorf("unexpected rendering %q (err: %v)", rendered, err)
+	}
+
+	if _, err = RenderFile("kool.yml", "port: {{ .missing }}", map[string]string{}); err == nil {
+		t.Error("expected error rendering missing variable")
+	}
+
+	if _, err = RenderFile("kool.yml", "port: {{ .app_port", map[string]string{}); err == nil {
+		t.Error("expected error rendering invalid template")
+	}
+}
+
+func TestValidateManifest(t *testing.T) {
+	hasTemplate := func(path string) bool {
+		return path == "database/mysql80.yml"
+	}
+
+	manifest := &Manifest{
+		Language: "php",
+		Questions: []*Question{{
+			Service: "database",
+			Options: []*Option{{Name: "MySQL 8.0", Template: "database/mysql80.yml"}, {Name: "none"}},
+		}},
+	}
+
+	if err := manifest.Validate(hasTemplate); err != nil {
+		t.Errorf("unexpected error validating manifest: %v", err)
+	}
+
+	manifest = &Manifest{
+		Questions: []*Question{
+			{Service: "cache"},
+			{Options: []*Option{{Template: "database/mysql57.yml"}}},
+		},
+	}
+
+	expected := "invalid preset.yml: missing language; no options for cache service question; question without service; option without name for  service question; unknown template database/mysql57.yml for  service option "
+
+	if err := manifest.Validate(hasTemplate); err == nil || err.Error() != expected {
+		t.Errorf("expected error '%s'; got %v", expected, err)
+	}
+}
+
+func TestQuestionOptions(t *testing.T) {
+	manifest, _ := ParseManifest(`questions:
+  - service: cache
+    options:
+      - name: Redis 6.0
+        template: cache/redis60.yml
+      - name: none
+`)
+
+	question := manifest.Questions[0]
+
+	if question.Question != "What cache service do you want to use" {
+		t.Errorf("unexpected default question: %s", question.Question)
+	}
+
+	if names := question.OptionsNames(); len(names) != 2 || names[0] != "Redis 6.0" || names[1] != "none" {
+		t.Errorf("unexpected options names: %v", names)
+	}
+
+	if option := question.Option("Redis 6.0"); option == nil || option.Template != "cache/redis60.yml" {
+		t.Errorf("unexpected option: %v", option)
+	}
+
+	if question.Option("Memcached 1.6") != nil {
+		t.Error("unexpected option found")
+	}
+}
+
+func TestQuestionFindOption(t *testing.T) {
+	question := &Question{
+		Service: "database",
+		Options: []*Option{
+			{Name: "MySQL 8.0", Template: "database/mysql80.yml"},
+			{Name: "MySQL 5.7", Template: "database/mysql57.yml"},
+			{Name: "PostgreSQL 13.0", Template: "database/postgresql130.yml"},
+			{Name: "none"},
+		},
+	}
+
+	found := map[string]string{
+		"MySQL 5.7":     "MySQL 5.7",
+		"mysql80":       "MySQL 8.0",
+		"postgresql13":  "PostgreSQL 13.0",
+		"PostgreSQL":    "PostgreSQL 13.0",
+		"none":          "none",
+		"postgresql130": "PostgreSQL 13.0",
+	}
+
+	for value, expected := range found {
+		if option := question.FindOption(value); option == nil || option.Name != expected {
+			t.Errorf("expecting option %s for %s, got %v", expected, value, option)
+		}
+	}
+
+	for _, value := range []string{"mysql", "oracle", ""} {
+		if option := question.FindOption(value); option != nil {
+			t.Errorf("unexpected option %s found for %s", option.Name, value)
+		}
+	}
+
+	if keys := question.OptionsKeys(); strings.Join(keys, ",") != "mysql80,mysql57,postgresql130,none" {
+		t.Errorf("unexpected options keys: %v", keys)
+	}
+}
+
+func TestFindTemplate(t *testing.T) {
+	templates := map[string]map[string]string{
+		"cache": {"redis60.yml": "image: redis:6-alpine"},
+	}
+
+	if content, err := FindTemplate(templates, "cache/redis60.yml"); err != nil || content != "image: redis:6-alpine" {
+		t.Errorf("unexpected template %q (err: %v)", content, err)
+	}
+
+	for _, path := range []string{"cache/memcached16.yml", "redis60.yml"} {
+		if _, err := FindTemplate(templates, path); !errors.Is(err, ErrTemplateNotFound) {
+			t.Errorf("expected ErrTemplateNotFound for %s; got %v", path, err)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package presets
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -20,17 +22,36 @@ type DefaultParser struct {
 type Parser interface {
 	Exists(string) bool
 	GetCreateCommand(string) (string, error)
+	GetManifest(string) (*Manifest, error)
 	GetLanguages() []string
 	GetPresets(string) []string
 	LookUpFiles(string) []string
 	LoadPresets(map[string]map[string]string)
+	LoadPresetsFolder(string) error
 	LoadTemplates(map[string]map[string]string)
 	WriteFile(string, string) (string, error)
+	ReadFile(string) (string, error)
+	RemoveFile(string) error
 	GetPresetKeys(string) []string
 	GetPresetKeyContent(string, string) string
 	GetTemplates() map[string]map[string]string
 }
 
+// InvalidPresetsError aggregates the errors of all the presets
+// that failed being loaded from a presets folder.
+type InvalidPresetsError []error
+
+// Error implements the error interface
+func (e InvalidPresetsError) Error() string {
+	messages := make([]string, len(e))
+
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return fmt.Sprintf("failed loading %d preset(s): %s", len(e), strings.Join(messages, "; "))
+}
+
 // NewParser creates a new preset default parser
 func NewParser() Parser {
 	return &DefaultParser{
@@ -56,15 +77,34 @@ var ErrCreateCommandtNotFoundOrEmpty = errors.New("create command not found or e
 
 // GetCreateCommand gets the command to create a new project
 func (p *DefaultParser) GetCreateCommand(preset string) (cmd string, err error) {
-	cmd = p.Presets[preset]["preset_create"]
+	var manifest *Manifest
 
-	if cmd == "" {
+	if manifest, err = p.GetManifest(preset); err != nil {
+		return
+	}
+
+	if cmd = manifest.Create; cmd == "" {
 		err = ErrCreateCommandtNotFoundOrEmpty
 	}
 
 	return
 }
 
+// GetManifest gets the preset manifest; presets
+// without one get an empty manifest.
+func (p *DefaultParser) GetManifest(preset string) (*Manifest, error) {
+	return ParseManifest(p.Presets[preset][PresetManifestFile])
+}
+
+// presetLanguage gets the preset language, which is
+// empty in case its manifest is invalid.
+func (p *DefaultParser) presetLanguage(preset string) string {
+	if manifest, err := p.GetManifest(preset); err == nil {
+		return manifest.Language
+	}
+	return ""
+}
+
 // GetLanguages get all presets languages
 func (p *DefaultParser) GetLanguages() (languages []string) {
 	if len(p.Presets) == 0 {
@@ -72,8 +112,8 @@ func (p *DefaultParser) GetLanguages() (languages []string) {
 	}
 
 	var lookedLangs map[string]bool = make(map[string]bool)
-	for _, content := range p.Presets {
-		if presetLang, ok := content["preset_language"]; ok && !lookedLangs[presetLang] {
+	for preset := range p.Presets {
+		if presetLang := p.presetLanguage(preset); presetLang != "" && !lookedLangs[presetLang] {
 			languages = append(languages, presetLang)
 			lookedLangs[presetLang] = true
 		}
@@ -88,10 +128,10 @@ func (p *DefaultParser) GetPresets(language string) (presets []string) {
 		return
 	}
 
-	for key, content := range p.Presets {
+	for key := range p.Presets {
 		if language == "" {
 			presets = append(presets, key)
-		} else if presetLang, ok := content["preset_language"]; ok && presetLang == language {
+		} else if p.presetLanguage(key) == language {
 			presets = append(presets, key)
 		}
 	}
@@ -107,7 +147,7 @@ func (p *DefaultParser) LookUpFiles(preset string) (foundFiles []string) {
 	presetFiles := p.Presets[preset]
 
 	for fileName := range presetFiles {
-		if strings.HasPrefix(fileName, "preset_") {
+		if fileName == PresetManifestFile {
 			continue
 		}
 
@@ -153,6 +193,23 @@ func (p *DefaultParser) WriteFile(fileName string, fileContent string) (fileErro
 	return
 }
 
+// ReadFile reads the content of a project file
+func (p *DefaultParser) ReadFile(fileName string) (content string, err error) {
+	var fileBytes []byte
+
+	if fileBytes, err = afero.ReadFile(p.fs, fileName); err != nil {
+		return
+	}
+
+	content = string(fileBytes)
+	return
+}
+
+// RemoveFile removes the project file
+func (p *DefaultParser) RemoveFile(fileName string) error {
+	return p.fs.Remove(fileName)
+}
+
 // GetPresetKeys get preset file contents
 func (p *DefaultParser) GetPresetKeys(preset string) (keys []string) {
 	presetData := p.Presets[preset]
@@ -194,3 +251,99 @@ func (p *DefaultParser) LoadPresets(allPresets map[string]map[string]string) {
 func (p *DefaultParser) LoadTemplates(allTemplates map[string]map[string]string) {
 	p.Templates = allTemplates
 }
+
+// LoadPresetsFolder loads the presets from the given folder, where
+// each sub folder holding a preset.yml manifest is a preset. The
+// manifest is validated against the loaded templates. Presets already
+// loaded with the same name get replaced. A missing folder is simply
+// ignored. Invalid presets are skipped and the valid ones still get
+// loaded, in which case an InvalidPresetsError is returned.
+func (p *DefaultParser) LoadPresetsFolder(folder string) (err error) {
+	var (
+		folders []os.FileInfo
+		preset  map[string]string
+		invalid InvalidPresetsError
+	)
+
+	if folders, err = afero.ReadDir(p.fs, folder); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+
+	if p.Presets == nil {
+		p.Presets = make(map[string]map[string]string)
+	}
+
+	for _, presetFolder := range folders {
+		if !presetFolder.IsDir() {
+			continue
+		}
+
+		if preset, err = p.readPresetFolder(filepath.Join(folder, presetFolder.Name())); err != nil {
+			invalid = append(invalid, fmt.Errorf("preset %s: %v", presetFolder.Name(), err))
+			continue
+		}
+
+		if _, isPreset := preset[PresetManifestFile]; !isPreset {
+			continue
+		}
+
+		if err = p.validatePreset(preset); err != nil {
+			invalid = append(invalid, fmt.Errorf("preset %s: %v", presetFolder.Name(), err))
+			continue
+		}
+
+		p.Presets[presetFolder.Name()] = preset
+	}
+
+	err = nil
+
+	if len(invalid) > 0 {
+		err = invalid
+	}
+
+	return
+}
+
+func (p *DefaultParser) readPresetFolder(folder string) (preset map[string]string, err error) {
+	var (
+		files   []os.FileInfo
+		content []byte
+	)
+
+	if files, err = afero.ReadDir(p.fs, folder); err != nil {
+		return
+	}
+
+	preset = make(map[string]string)
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		if content, err = afero.ReadFile(p.fs, filepath.Join(folder, file.Name())); err != nil {
+			return
+		}
+
+		preset[file.Name()] = string(content)
+	}
+
+	return
+}
+
+func (p *DefaultParser) validatePreset(preset map[string]string) (err error) {
+	var manifest *Manifest
+
+	if manifest, err = ParseManifest(preset[PresetManifestFile]); err != nil {
+		return
+	}
+
+	err = manifest.Validate(func(path string) bool {
+		_, templateErr := FindTemplate(p.Templates, path)
+		return templateErr == nil
+	})
+	return
+}
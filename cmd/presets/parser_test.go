@@ -3,6 +3,7 @@ package presets
 import (
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -75,9 +76,9 @@ func TestGetLanguagesParser(t *testing.T) {
 	laravelPreset := make(map[string]string)
 	symfonyPreset := make(map[string]string)
 
-	laravelPreset["preset_language"] = "php"
+	laravelPreset["preset.yml"] = "language: php"
 	laravelPreset["kool.yml"] = ""
-	symfonyPreset["preset_language"] = "php"
+	symfonyPreset["preset.yml"] = "language: php"
 	symfonyPreset["kool.yml"] = ""
 
 	presets["laravel"] = laravelPreset
@@ -98,9 +99,9 @@ func TestGetPresetByLanguageParser(t *testing.T) {
 	phpPreset := make(map[string]string)
 	jsPreset := make(map[string]string)
 
-	phpPreset["preset_language"] = "php"
+	phpPreset["preset.yml"] = "language: php"
 	phpPreset["kool.yml"] = ""
-	jsPreset["preset_language"] = "javascript"
+	jsPreset["preset.yml"] = "language: javascript"
 	jsPreset["kool.yml"] = ""
 
 	presets["php_language"] = phpPreset
@@ -121,7 +122,7 @@ func TestGetCreateCommandParser(t *testing.T) {
 
 	laravelPreset := make(map[string]string)
 
-	laravelPreset["preset_create"] = "command"
+	laravelPreset["preset.yml"] = "create: command"
 	laravelPreset["kool.yml"] = ""
 
 	presets["laravel"] = laravelPreset
@@ -131,7 +132,7 @@ func TestGetCreateCommandParser(t *testing.T) {
 
 	laravelCmd, _ := p.GetCreateCommand("laravel")
 
-	if laravelCmd != laravelPreset["preset_create"] {
+	if laravelCmd != "command" {
 		t.Error("failed to get command")
 	}
 }
@@ -141,7 +142,7 @@ func TestFailGetCreateCommandParser(t *testing.T) {
 
 	laravelPreset := make(map[string]string)
 
-	laravelPreset["preset_create"] = ""
+	laravelPreset["preset.yml"] = "language: php"
 	laravelPreset["kool.yml"] = ""
 
 	presets["laravel"] = laravelPreset
@@ -161,13 +162,14 @@ func TestIgnorePresetMetaKeysParser(t *testing.T) {
 
 	testingPreset := make(map[string]string)
 
-	testingPreset["preset_language"] = "php"
+	testingPreset["preset.yml"] = "language: php"
 	testingPreset["kool.yml"] = ""
 
 	presets["preset"] = testingPreset
 
 	fs := afero.NewMemMapFs()
 	_ = afero.WriteFile(fs, "kool.yml", []byte("scripts"), os.ModePerm)
+	_ = afero.WriteFile(fs, "preset.yml", []byte("language: php"), os.ModePerm)
 
 	p := NewParserFS(fs)
 	p.LoadPresets(presets)
@@ -247,11 +249,27 @@ func TestWriteFileParser(t *testing.T) {
 	if _, err := fs.Stat("kool.yml"); os.IsNotExist(err) {
 		t.Error("could not write the file 'kool.yml'")
 	}
+
+	if content, err := p.ReadFile("kool.yml"); err != nil || content != "scripts" {
+		t.Errorf("unexpected content %q reading file 'kool.yml' (err: %v)", content, err)
+	}
+
+	if _, err := p.ReadFile("missing.yml"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error reading missing file; got %v", err)
+	}
+
+	if err := p.RemoveFile("kool.yml"); err != nil {
+		t.Errorf("unexpected error removing file, err: %v", err)
+	}
+
+	if _, err := fs.Stat("kool.yml"); !os.IsNotExist(err) {
+		t.Error("could not remove the file 'kool.yml'")
+	}
 }
 
 func TestLoadPresetsParser(t *testing.T) {
 	presets := map[string]map[string]string{
-		"laravel": {"preset_create": "command"},
+		"laravel": {"preset.yml": "create: command"},
 	}
 
 	p := &DefaultParser{}
@@ -274,3 +292,117 @@ func TestLoadTemplatesParser(t *testing.T) {
 		t.Error("did not load the templates correctly")
 	}
 }
+
+func TestLoadPresetsFolderParser(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	_ = afero.WriteFile(fs, "/presets/custom/kool.yml", []byte("scripts:"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/presets/custom/preset.yml", []byte("language: php\ncreate: composer create-project\n"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/presets/README.md", []byte("not a preset"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/presets/docs/index.md", []byte("not a preset"), os.ModePerm)
+
+	p := NewParserFS(fs)
+	p.LoadPresets(map[string]map[string]string{
+		"custom":  {"kool.yml": "old"},
+		"laravel": {"kool.yml": ""},
+	})
+
+	if err := p.LoadPresetsFolder("/presets"); err != nil {
+		t.Fatalf("unexpected error loading presets folder: %v", err)
+	}
+
+	expected := map[string]string{
+		"kool.yml":   "scripts:",
+		"preset.yml": "language: php\ncreate: composer create-project\n",
+	}
+
+	if content := p.(*DefaultParser).Presets["custom"]; !reflect.DeepEqual(content, expected) {
+		t.Errorf("expected preset %v; got %v", expected, content)
+	}
+
+	if !p.Exists("laravel") {
+		t.Error("built-in preset should still be loaded")
+	}
+
+	if p.Exists("README.md") || p.Exists("docs") {
+		t.Error("folders without manifest and files within the presets folder should not be loaded as presets")
+	}
+
+	if err := p.LoadPresetsFolder("/missing"); err != nil {
+		t.Errorf("unexpected error loading missing presets folder: %v", err)
+	}
+}
+
+func TestLoadPresetsFolderInvalidManifestParser(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	_ = afero.WriteFile(fs, "/presets/custom/preset.yml", []byte(`language: php
+questions:
+  - service: database
+    options:
+      - name: MySQL 9.0
+        template: database/mysql90.yml
+`), os.ModePerm)
+
+	_ = afero.WriteFile(fs, "/presets/unknown/preset.yml", []byte("foo: bar\n"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/presets/valid/preset.yml", []byte("language: php\n"), os.ModePerm)
+
+	p := NewParserFS(fs)
+	p.LoadPresets(map[string]map[string]string{
+		"laravel": {"preset.yml": "language: php\n"},
+	})
+	p.LoadTemplates(map[string]map[string]string{
+		"database": {"mysql80.yml": "image: mysql:8.0"},
+	})
+
+	err := p.LoadPresetsFolder("/presets")
+
+	invalid, ok := err.(InvalidPresetsError)
+
+	if !ok || len(invalid) != 2 {
+		t.Fatalf("expected InvalidPresetsError with 2 errors; got %v", err)
+	}
+
+	expected := "preset custom: invalid preset.yml: unknown template database/mysql90.yml for database service option MySQL 9.0"
+
+	if invalid[0].Error() != expected {
+		t.Errorf("expected error '%s'; got %v", expected, invalid[0])
+	}
+
+	if !strings.HasPrefix(invalid[1].Error(), "preset unknown: ") {
+		t.Errorf("expected error naming the unknown preset; got %v", invalid[1])
+	}
+
+	if p.Exists("custom") || p.Exists("unknown") {
+		t.Error("invalid presets should not be loaded")
+	}
+
+	if !p.Exists("valid") || !p.Exists("laravel") {
+		t.Error("valid and built-in presets should still be loaded")
+	}
+}
+
+func TestGetManifestParser(t *testing.T) {
+	p := NewParser()
+	p.LoadPresets(map[string]map[string]string{
+		"laravel": {"preset.yml": "language: php\npost_install:\n  - kool run setup\n"},
+		"invalid": {"preset.yml": "language: ["},
+		"empty":   {"kool.yml": ""},
+	})
+
+	if manifest, err := p.GetManifest("laravel"); err != nil || manifest.Language != "php" || len(manifest.PostInstall) != 1 {
+		t.Errorf("unexpected manifest %v (err: %v)", manifest, err)
+	}
+
+	if _, err := p.GetManifest("invalid"); err == nil {
+		t.Error("expected error getting invalid manifest")
+	}
+
+	if manifest, err := p.GetManifest("empty"); err != nil || manifest.Language != "" {
+		t.Errorf("expected empty manifest; got %v (err: %v)", manifest, err)
+	}
+
+	if languages := p.GetLanguages(); len(languages) != 1 || languages[0] != "php" {
+		t.Errorf("expected only php language; got %v", languages)
+	}
+}
@@ -8,8 +8,6 @@ func GetAll() map[string]map[string]string {
 	presets["adonis"] = map[string]string{
 		".dockerignore": `/node_modules
 `,
-		"preset_language": "javascript",
-		"preset_create":   "kool docker kooldev/node:14-adonis adonis new",
 		"Dockerfile.build": `FROM kooldev/node:14-adonis AS build
 
 COPY . /app
@@ -78,10 +76,19 @@ networks:
   setup:
     - kool docker kooldev/node:14 npm install # can change to: yarn,pnpm
     - kool start
+`,
+		"preset.yml": `language: javascript
+description: AdonisJs Node.js web framework
+create: kool docker kooldev/node:14-adonis adonis new
+post_install:
+  - kool run setup
+setup:
+  - name: Installing dependencies
+    run: kool docker kooldev/node:14 npm install
+  - run: kool start
 `,
 	}
 	presets["golang-cli"] = map[string]string{
-		"preset_language": "golang",
 		"kool.yml": `scripts:
   # Helper for local development - compiling and installing locally
   dev:
@@ -98,37 +105,37 @@ networks:
     - mv my-cli /usr/local/bin/my-cli
   fmt: kool run go fmt
   lint: kool docker --volume=gopath:/go golangci/golangci-lint:v1.31.0 golangci-lint run -v
+`,
+		"preset.yml": `language: golang
+description: Command line tool written in Go
+post_install:
+  - kool run compile
 `,
 	}
 	presets["laravel"] = map[string]string{
 		".dockerignore": `/node_modules
 /vendor
 `,
-		"preset_language":         "php",
-		"preset_create":           "kool docker kooldev/php:7.4 composer create-project --prefer-dist laravel/laravel",
-		"preset_ask_services":     "database,cache",
-		"preset_database_options": "MySQL 8.0,MySQL 5.7,ProstgreSQL 13.0,none",
-		"preset_cache_options":    "Redis 6.0,Memcached 1.6,none",
-		"Dockerfile.build": `FROM kooldev/php:7.4 AS composer
+		"Dockerfile.build": `FROM kooldev/php:{{ .php_version }} AS composer
 
 COPY . /app
 RUN composer install --no-interaction --prefer-dist --optimize-autoloader --quiet
 
-FROM kooldev/node:14 AS node
+FROM kooldev/node:{{ .node_version }} AS node
 
 COPY --from=composer /app /app
 RUN yarn install && yarn prod
 
-FROM kooldev/php:7.4-nginx
+FROM kooldev/php:{{ .php_version }}-nginx
 
 COPY --from=node --chown=kool:kool /app /app
 `,
 		"docker-compose.yml": `version: "3.7"
 services:
   app:
-    image: kooldev/php:7.4-nginx
+    image: kooldev/php:{{ .php_version }}-nginx
     ports:
-     - "${KOOL_APP_PORT:-80}:80"
+     - "${KOOL_APP_PORT:-{{ .app_port }}}:80"
     environment:
       ASUSER: "${KOOL_ASUSER:-0}"
       UID: "${UID:-0}"
@@ -174,14 +181,14 @@ networks:
   artisan: kool exec app php artisan
   composer: kool exec app composer
 
-  node: kool docker kooldev/node:14 node
-  npm: kool docker kooldev/node:14 npm # can change to: yarn,pnpm
+  node: kool docker kooldev/node:{{ .node_version }} node
+  npm: kool docker kooldev/node:{{ .node_version }} npm # can change to: yarn,pnpm
 
   mysql: kool exec database mysql -uroot -p$DB_PASSWORD
 
   setup:
     - cp .env.example .env
-    - kool start
+    - kool start --wait
     - kool run composer install
     - kool run artisan key:generate
     - kool run npm install
@@ -192,13 +199,54 @@ networks:
     - kool run artisan migrate:fresh --seed
     - kool run npm install
     - kool run npm run dev
+`,
+		"preset.yml": `language: php
+description: Laravel PHP web framework
+create: kool docker kooldev/php:7.4 composer create-project --prefer-dist laravel/laravel
+questions:
+  - service: database
+    options:
+      - name: MySQL 8.0
+        template: database/mysql80.yml
+      - name: MySQL 5.7
+        template: database/mysql57.yml
+      - name: PostgreSQL 13.0
+        template: database/postgresql130.yml
+      - name: none
+  - service: cache
+    options:
+      - name: Redis 6.0
+        template: cache/redis60.yml
+      - name: Memcached 1.6
+        template: cache/memcached16.yml
+      - name: none
+variables:
+  - name: php_version
+    question: What PHP version do you want to use
+    default: "7.4"
+    options: ["7.4", "8.0"]
+  - name: node_version
+    question: What Node.js version do you want to use for building assets
+    default: "14"
+    options: ["14", "12"]
+  - name: app_port
+    question: What port do you want the app to be served on
+    default: "80"
+post_install:
+  - kool run setup
+setup:
+  - run: cp .env.example .env
+  - run: kool start
+  - wait: database
+  - run: kool run composer install
+  - run: kool run artisan key:generate
+  - run: kool run npm install
+  - run: kool run npm run dev
 `,
 	}
 	presets["nestjs"] = map[string]string{
 		".dockerignore": `/node_modules
 `,
-		"preset_language": "javascript",
-		"preset_create":   "kool docker kooldev/node:14-nest nest new",
 		"Dockerfile.build": `FROM kooldev/node:14-nest AS build
 
 COPY . /app
@@ -283,6 +331,16 @@ networks:
   setup:
     - kool docker kooldev/node:14 npm install # can change to: yarn,pnpm
     - kool start
+`,
+		"preset.yml": `language: javascript
+description: NestJS Node.js framework
+create: kool docker kooldev/node:14-nest nest new
+post_install:
+  - kool run setup
+setup:
+  - name: Installing dependencies
+    run: kool docker kooldev/node:14 npm install
+  - run: kool start
 `,
 	}
 	presets["nextjs"] = map[string]string{
@@ -291,15 +349,13 @@ networks:
 /build
 /node_modules
 `,
-		"preset_language": "javascript",
-		"preset_create":   "kool docker kooldev/node:14 yarn create next-app",
-		"Dockerfile.build": `FROM kooldev/node:14 AS build
+		"Dockerfile.build": `FROM kooldev/node:{{ .node_version }} AS build
 
 COPY . /app
 
 RUN npm install && npm run build
 
-FROM kooldev/node:14
+FROM kooldev/node:{{ .node_version }}
 
 COPY --from=build --chown=kool:kool /app /app
 
@@ -310,10 +366,10 @@ CMD [ "npm", "start" ]
 		"docker-compose.yml": `version: "3.7"
 services:
   app:
-    image: kooldev/node:14
+    image: kooldev/node:{{ .node_version }}
     command: ["npm", "run", "dev"]
     ports:
-     - "${KOOL_APP_PORT:-3000}:3000"
+     - "${KOOL_APP_PORT:-{{ .app_port }}}:3000"
     environment:
       ASUSER: "${KOOL_ASUSER:-0}"
       UID: "${UID:-0}"
@@ -335,8 +391,25 @@ networks:
   npm: kool exec app npm # can change to: yarn,pnpm
 
   setup:
-    - kool docker kooldev/node:14 npm install # can change to: yarn,pnpm
+    - kool docker kooldev/node:{{ .node_version }} npm install # can change to: yarn,pnpm
     - kool start
+`,
+		"preset.yml": `language: javascript
+description: Next.js React framework
+create: kool docker kooldev/node:14 yarn create next-app
+variables:
+  - name: node_version
+    question: What Node.js version do you want to use
+    default: "14"
+    options: ["14", "12"]
+  - name: app_port
+    question: What port do you want the app to be served on
+    default: "3000"
+post_install:
+  - kool run setup
+setup:
+  - name: Installing dependencies and starting the app
+    run: kool run setup
 `,
 	}
 	presets["nextjs-static"] = map[string]string{
@@ -345,8 +418,6 @@ networks:
 /build
 /node_modules
 `,
-		"preset_language": "javascript",
-		"preset_create":   "kool docker kooldev/node:14 yarn create next-app",
 		"Dockerfile.build": `FROM kooldev/node:14 AS node
 
 COPY . /app
@@ -389,6 +460,16 @@ networks:
   setup:
     - kool docker kooldev/node:14 npm install # can change to: yarn,pnpm
     - kool start
+`,
+		"preset.yml": `language: javascript
+description: Next.js React framework exported as a static site
+create: kool docker kooldev/node:14 yarn create next-app
+post_install:
+  - kool run setup
+setup:
+  - name: Installing dependencies
+    run: kool docker kooldev/node:14 npm install
+  - run: kool start
 `,
 	}
 	presets["nuxtjs"] = map[string]string{
@@ -396,8 +477,6 @@ networks:
 /dist
 /node_modules
 `,
-		"preset_language": "javascript",
-		"preset_create":   "kool docker kooldev/node:14 yarn create nuxt-app",
 		"Dockerfile.build": `FROM kooldev/node:14 AS build
 
 COPY . /app
@@ -448,6 +527,16 @@ networks:
   setup:
     - kool docker kooldev/node:14 npm install # can change to: yarn,pnpm
     - kool start
+`,
+		"preset.yml": `language: javascript
+description: Nuxt.js Vue.js framework
+create: kool docker kooldev/node:14 yarn create nuxt-app
+post_install:
+  - kool run setup
+setup:
+  - name: Installing dependencies
+    run: kool docker kooldev/node:14 npm install
+  - run: kool start
 `,
 	}
 	presets["nuxtjs-static"] = map[string]string{
@@ -455,8 +544,6 @@ networks:
 /dist
 /node_modules
 `,
-		"preset_language": "javascript",
-		"preset_create":   "kool docker kooldev/node:14 yarn create nuxt-app",
 		"Dockerfile.build": `FROM kooldev/node:14 AS node
 
 COPY . /app
@@ -505,17 +592,22 @@ networks:
   setup:
     - kool docker kooldev/node:14 npm install # can change to: yarn,pnpm
     - kool start
+`,
+		"preset.yml": `language: javascript
+description: Nuxt.js Vue.js framework generated as a static site
+create: kool docker kooldev/node:14 yarn create nuxt-app
+post_install:
+  - kool run setup
+setup:
+  - name: Installing dependencies
+    run: kool docker kooldev/node:14 npm install
+  - run: kool start
 `,
 	}
 	presets["symfony"] = map[string]string{
 		".dockerignore": `/node_modules
 /vendor
 `,
-		"preset_language":         "php",
-		"preset_create":           "kool docker kooldev/php:7.4 composer create-project --prefer-dist symfony/website-skeleton",
-		"preset_ask_services":     "database,cache",
-		"preset_database_options": "MySQL 8.0,MySQL 5.7,ProstgreSQL 13.0,none",
-		"preset_cache_options":    "Redis 6.0,Memcached 1.6,none",
 		"Dockerfile.build": `FROM kooldev/php:7.4 AS composer
 
 COPY . /app
@@ -588,16 +680,39 @@ networks:
   mysql: kool exec database mysql -uroot -prootpass
 
   setup:
-    - kool start
+    - kool start --wait
     - cp .env.example .env
     - kool run composer install
+`,
+		"preset.yml": `language: php
+description: Symfony PHP web framework
+create: kool docker kooldev/php:7.4 composer create-project --prefer-dist symfony/website-skeleton
+questions:
+  - service: database
+    options:
+      - name: MySQL 8.0
+        template: database/mysql80.yml
+      - name: MySQL 5.7
+        template: database/mysql57.yml
+      - name: PostgreSQL 13.0
+        template: database/postgresql130.yml
+      - name: none
+  - service: cache
+    options:
+      - name: Redis 6.0
+        template: cache/redis60.yml
+      - name: Memcached 1.6
+        template: cache/memcached16.yml
+      - name: none
+post_install:
+  - kool run setup
+setup:
+  - run: kool start
+  - wait: database
+  - run: kool run composer install
 `,
 	}
 	presets["wordpress"] = map[string]string{
-		"preset_language":         "php",
-		"preset_ask_services":     "database,cache",
-		"preset_database_options": "MySQL 8.0,MySQL 5.7,ProstgreSQL 13.0,none",
-		"preset_cache_options":    "Redis 6.0,Memcached 1.6,none",
 		"docker-compose.yml": `version: "3.7"
 services:
   app:
@@ -649,6 +764,31 @@ networks:
   wp: kool exec app wp
 
   mysql: kool exec database mysql -uroot -p$DB_PASSWORD
+`,
+		"preset.yml": `language: php
+description: WordPress CMS
+questions:
+  - service: database
+    options:
+      - name: MySQL 8.0
+        template: database/mysql80.yml
+      - name: MySQL 5.7
+        template: database/mysql57.yml
+      - name: PostgreSQL 13.0
+        template: database/postgresql130.yml
+      - name: none
+  - service: cache
+    options:
+      - name: Redis 6.0
+        template: cache/redis60.yml
+      - name: Memcached 1.6
+        template: cache/memcached16.yml
+      - name: none
+post_install:
+  - kool start
+setup:
+  - run: kool start
+  - wait: database
 `,
 	}
 	return presets
@@ -658,61 +798,278 @@ networks:
 func GetTemplates() map[string]map[string]string {
 	var templates = make(map[string]map[string]string)
 	templates["cache"] = map[string]string{
-		"memcached16.yml": `image: memcached:1.6-alpine
+		"memcached16.yml": `name: Memcached 1.6
+category: cache
+ports:
+  - 11211
 volumes:
-  - cache:/data:delegated
-networks:
-  - kool_local
+  - cache
+service:
+  image: memcached:1.6-alpine
+  volumes:
+    - cache:/data:delegated
+  networks:
+    - kool_local
 `,
-		"redis60.yml": `image: redis:6-alpine
+		"redis60.yml": `name: Redis 6.0
+category: cache
+ports:
+  - 6379
 volumes:
-  - cache:/data:delegated
-networks:
-  - kool_local
+  - cache
+healthcheck:
+  test: ["CMD", "redis-cli", "ping"]
+  interval: 10s
+  timeout: 5s
+  retries: 5
+scripts:
+  redis-cli: kool exec cache redis-cli
+service:
+  image: redis:6-alpine
+  volumes:
+    - cache:/data:delegated
+  networks:
+    - kool_local
 `,
 	}
 	templates["database"] = map[string]string{
-		"mysql57.yml": `image: mysql:5.7
+		"mysql57.yml": `name: MySQL 5.7
+category: database
+env:
+  KOOL_DATABASE_PORT: "3306"
+  DB_DATABASE: database
+  DB_USERNAME: user
+  DB_PASSWORD: pass
 ports:
-  - "${KOOL_DATABASE_PORT:-3306}:3306"
-environment:
-  MYSQL_ROOT_PASSWORD: "${DB_PASSWORD:-rootpass}"
-  MYSQL_DATABASE: "${DB_DATABASE:-database}"
-  MYSQL_USER: "${DB_USERNAME:-user}"
-  MYSQL_PASSWORD: "${DB_PASSWORD:-pass}"
-  MYSQL_ALLOW_EMPTY_PASSWORD: "yes"
+  - 3306
 volumes:
- - database:/var/lib/mysql:delegated
-networks:
- - kool_local
+  - database
+healthcheck:
+  test: ["CMD", "mysqladmin", "ping", "-h", "localhost"]
+  interval: 10s
+  timeout: 5s
+  retries: 5
+scripts:
+  mysql: kool exec database mysql -uroot -p$DB_PASSWORD
+service:
+  image: mysql:5.7
+  ports:
+    - "${KOOL_DATABASE_PORT:-3306}:3306"
+  environment:
+    MYSQL_ROOT_PASSWORD: "${DB_PASSWORD:-rootpass}"
+    MYSQL_DATABASE: "${DB_DATABASE:-database}"
+    MYSQL_USER: "${DB_USERNAME:-user}"
+    MYSQL_PASSWORD: "${DB_PASSWORD:-pass}"
+    MYSQL_ALLOW_EMPTY_PASSWORD: "yes"
+  volumes:
+    - database:/var/lib/mysql:delegated
+  networks:
+    - kool_local
 `,
-		"mysql80.yml": `image: mysql:8.0
-command: --default-authentication-plugin=mysql_native_password
+		"mysql80.yml": `name: MySQL 8.0
+category: database
+env:
+  KOOL_DATABASE_PORT: "3306"
+  DB_DATABASE: database
+  DB_USERNAME: user
+  DB_PASSWORD: pass
 ports:
-  - "${KOOL_DATABASE_PORT:-3306}:3306"
-environment:
-  MYSQL_ROOT_PASSWORD: "${DB_PASSWORD:-rootpass}"
-  MYSQL_DATABASE: "${DB_DATABASE:-database}"
-  MYSQL_USER: "${DB_USERNAME:-user}"
-  MYSQL_PASSWORD: "${DB_PASSWORD:-pass}"
-  MYSQL_ALLOW_EMPTY_PASSWORD: "yes"
+  - 3306
 volumes:
- - database:/var/lib/mysql:delegated
-networks:
- - kool_local
+  - database
+healthcheck:
+  test: ["CMD", "mysqladmin", "ping", "-h", "localhost"]
+  interval: 10s
+  timeout: 5s
+  retries: 5
+scripts:
+  mysql: kool exec database mysql -uroot -p$DB_PASSWORD
+service:
+  image: mysql:8.0
+  command: --default-authentication-plugin=mysql_native_password
+  ports:
+    - "${KOOL_DATABASE_PORT:-3306}:3306"
+  environment:
+    MYSQL_ROOT_PASSWORD: "${DB_PASSWORD:-rootpass}"
+    MYSQL_DATABASE: "${DB_DATABASE:-database}"
+    MYSQL_USER: "${DB_USERNAME:-user}"
+    MYSQL_PASSWORD: "${DB_PASSWORD:-pass}"
+    MYSQL_ALLOW_EMPTY_PASSWORD: "yes"
+  volumes:
+    - database:/var/lib/mysql:delegated
+  networks:
+    - kool_local
 `,
-		"prostgresql130.yml": `image: postgres:13-alpine
+		"postgresql130.yml": `name: PostgreSQL 13.0
+category: database
+env:
+  KOOL_DATABASE_PORT: "5432"
+  DB_DATABASE: database
+  DB_USERNAME: user
+  DB_PASSWORD: pass
 ports:
-  - "${KOOL_DATABASE_PORT:-3306}:3306"
-environment:
-  POSTGRES_DB: "${DB_DATABASE:-database}"
-  POSTGRES_USER: "${DB_USERNAME:-user}"
-  POSTGRES_PASSWORD: "${DB_PASSWORD:-pass}"
-  POSTGRES_HOST_AUTH_METHOD: "trust"
+  - 5432
 volumes:
- - database:/var/lib/postgresql/data:delegated
-networks:
- - kool_local
+  - database
+healthcheck:
+  test: ["CMD-SHELL", "pg_isready -U $$POSTGRES_USER"]
+  interval: 10s
+  timeout: 5s
+  retries: 5
+scripts:
+  psql: kool exec -e PGPASSWORD=$DB_PASSWORD database psql -U $DB_USERNAME $DB_DATABASE
+service:
+  image: postgres:13-alpine
+  ports:
+    - "${KOOL_DATABASE_PORT:-5432}:5432"
+  environment:
+    POSTGRES_DB: "${DB_DATABASE:-database}"
+    POSTGRES_USER: "${DB_USERNAME:-user}"
+    POSTGRES_PASSWORD: "${DB_PASSWORD:-pass}"
+    POSTGRES_HOST_AUTH_METHOD: "trust"
+  volumes:
+    - database:/var/lib/postgresql/data:delegated
+  networks:
+    - kool_local
+`,
+	}
+	templates["mail"] = map[string]string{
+		"mailhog10.yml": `name: MailHog 1.0
+category: mail
+env:
+  KOOL_MAIL_PORT: "1025"
+  KOOL_MAIL_DASHBOARD_PORT: "8025"
+ports:
+  - 1025
+  - 8025
+healthcheck:
+  test: ["CMD", "wget", "--no-verbose", "--spider", "http://localhost:8025"]
+  interval: 10s
+  timeout: 5s
+  retries: 5
+service:
+  image: mailhog/mailhog:v1.0.1
+  ports:
+    - "${KOOL_MAIL_PORT:-1025}:1025"
+    - "${KOOL_MAIL_DASHBOARD_PORT:-8025}:8025"
+  networks:
+    - kool_local
+`,
+	}
+	templates["queue"] = map[string]string{
+		"rabbitmq38.yml": `name: RabbitMQ 3.8
+category: queue
+env:
+  KOOL_QUEUE_PORT: "5672"
+  KOOL_QUEUE_DASHBOARD_PORT: "15672"
+  RABBITMQ_USER: user
+  RABBITMQ_PASSWORD: pass
+ports:
+  - 5672
+  - 15672
+volumes:
+  - queue
+healthcheck:
+  test: ["CMD", "rabbitmq-diagnostics", "-q", "ping"]
+  interval: 10s
+  timeout: 5s
+  retries: 5
+scripts:
+  rabbitmqctl: kool exec queue rabbitmqctl
+service:
+  image: rabbitmq:3.8-management-alpine
+  ports:
+    - "${KOOL_QUEUE_PORT:-5672}:5672"
+    - "${KOOL_QUEUE_DASHBOARD_PORT:-15672}:15672"
+  environment:
+    RABBITMQ_DEFAULT_USER: "${RABBITMQ_USER:-user}"
+    RABBITMQ_DEFAULT_PASS: "${RABBITMQ_PASSWORD:-pass}"
+  volumes:
+    - queue:/var/lib/rabbitmq:delegated
+  networks:
+    - kool_local
+`,
+	}
+	templates["search"] = map[string]string{
+		"elasticsearch710.yml": `name: Elasticsearch 7.10
+category: search
+env:
+  KOOL_SEARCH_PORT: "9200"
+ports:
+  - 9200
+volumes:
+  - search
+healthcheck:
+  test: ["CMD-SHELL", "curl -fs 'http://localhost:9200/_cluster/health?wait_for_status=yellow&timeout=1s'"]
+  interval: 10s
+  timeout: 5s
+  retries: 10
+service:
+  image: elasticsearch:7.10.1
+  ports:
+    - "${KOOL_SEARCH_PORT:-9200}:9200"
+  environment:
+    discovery.type: single-node
+    ES_JAVA_OPTS: "-Xms512m -Xmx512m"
+  volumes:
+    - search:/usr/share/elasticsearch/data:delegated
+  networks:
+    - kool_local
+`,
+		"meilisearch020.yml": `name: MeiliSearch 0.20
+category: search
+env:
+  KOOL_SEARCH_PORT: "7700"
+  MEILISEARCH_KEY: masterkey
+ports:
+  - 7700
+volumes:
+  - search
+healthcheck:
+  test: ["CMD", "wget", "--no-verbose", "--spider", "http://localhost:7700/health"]
+  interval: 10s
+  timeout: 5s
+  retries: 5
+service:
+  image: getmeili/meilisearch:v0.20.0
+  ports:
+    - "${KOOL_SEARCH_PORT:-7700}:7700"
+  environment:
+    MEILI_MASTER_KEY: "${MEILISEARCH_KEY:-masterkey}"
+  volumes:
+    - search:/data.ms:delegated
+  networks:
+    - kool_local
+`,
+	}
+	templates["storage"] = map[string]string{
+		"minio2021.yml": `name: MinIO
+category: storage
+env:
+  KOOL_STORAGE_PORT: "9000"
+  MINIO_USER: minio
+  MINIO_PASSWORD: minio123
+ports:
+  - 9000
+volumes:
+  - storage
+healthcheck:
+  test: ["CMD", "curl", "-f", "http://localhost:9000/minio/health/live"]
+  interval: 10s
+  timeout: 5s
+  retries: 5
+service:
+  image: minio/minio:RELEASE.2021-03-17T02-33-02Z
+  command: server /data
+  ports:
+    - "${KOOL_STORAGE_PORT:-9000}:9000"
+  environment:
+    MINIO_ROOT_USER: "${MINIO_USER:-minio}"
+    MINIO_ROOT_PASSWORD: "${MINIO_PASSWORD:-minio123}"
+  volumes:
+    - storage:/data:delegated
+  networks:
+    - kool_local
 `,
 	}
 	return templates
@@ -5,7 +5,7 @@ import (
 	"kool-dev/kool/cmd/parser"
 	"testing"
 
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
 )
 
 func TestPresetsKoolFile(t *testing.T) {
@@ -53,36 +53,78 @@ func TestPresetsKoolFile(t *testing.T) {
 	}
 }
 
-func TestLanguageMetadataFile(t *testing.T) {
+func TestPresetsManifest(t *testing.T) {
 	for preset, files := range GetAll() {
-		var (
-			language        string
-			hasLanguageData bool
-		)
-		if language, hasLanguageData = files["preset_language"]; !hasLanguageData {
-			t.Errorf("preset_language is missing from %s preset", preset)
+		manifest, err := ParseManifest(files[PresetManifestFile])
+
+		if err == nil {
+			err = manifest.Validate(func(path string) bool {
+				_, templateErr := FindTemplate(GetTemplates(), path)
+				return templateErr == nil
+			})
 		}
 
-		if len(language) == 0 {
-			t.Errorf("preset_language is does not have value on %s preset", preset)
+		if err != nil {
+			t.Errorf("failed on validating the manifest of %s preset: %v", preset, err)
 		}
 	}
 }
 
 func TestPresetsTemplates(t *testing.T) {
-	for serviceType, services := range GetTemplates() {
-		for _, service := range services {
+	for category, templates := range GetTemplates() {
+		for fileName, content := range templates {
 			var (
-				parsed        yaml.MapSlice
-				templateBytes []byte
+				template *Template
+				service  string
+				parsed   yaml.Node
+				err      error
 			)
 
-			if templateBytes = []byte(service); len(templateBytes) == 0 {
-				t.Errorf("failed on reading template %s from %s service", service, serviceType)
+			if template, err = ParseTemplate(content); err != nil {
+				t.Errorf("failed on parsing template %s from %s category: %v", fileName, category, err)
+				continue
+			}
+
+			if template.Category != category {
+				t.Errorf("template %s declares category %s but is in %s folder", fileName, template.Category, category)
+			}
+
+			if service, err = template.ServiceYaml(); err != nil {
+				t.Errorf("failed on rendering template %s from %s category: %v", fileName, category, err)
+			}
+
+			if err = yaml.Unmarshal([]byte(service), &parsed); err != nil {
+				t.Errorf("failed on parsing service of template %s from %s category", fileName, category)
+			}
+		}
+	}
+}
+
+func TestPresetsRenderDefaults(t *testing.T) {
+	for preset, files := range GetAll() {
+		manifest, err := ParseManifest(files[PresetManifestFile])
+
+		if err != nil || len(manifest.Variables) == 0 {
+			continue
+		}
+
+		values := make(map[string]string)
+
+		for _, variable := range manifest.Variables {
+			if variable.Default == "" {
+				t.Errorf("variable %s of %s preset has no default value", variable.Name, preset)
+			}
+
+			values[variable.Name] = variable.Default
+		}
+
+		for fileName, content := range files {
+			if fileName == PresetManifestFile {
+				continue
 			}
 
-			if err := yaml.Unmarshal(templateBytes, &parsed); err != nil {
-				t.Errorf("failed on parsing %s from %s service", service, serviceType)
+			if _, err := RenderFile(fileName, content, values); err != nil {
+				t.Errorf("failed on rendering %s from %s preset: %v", fileName, preset, err)
 			}
 		}
 	}
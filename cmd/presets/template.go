@@ -0,0 +1,105 @@
+package presets
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Template is a service of the templates catalog; it holds the
+// docker-compose service along with the metadata describing it.
+type Template struct {
+	Name        string            `yaml:"name"`
+	Category    string            `yaml:"category"`
+	Env         map[string]string `yaml:"env"`
+	Ports       []int             `yaml:"ports"`
+	Volumes     []string          `yaml:"volumes"`
+	Healthcheck yaml.Node         `yaml:"healthcheck"`
+	Scripts     map[string]string `yaml:"scripts"`
+	Service     yaml.Node         `yaml:"service"`
+}
+
+// ParseTemplate parses the template content, which must
+// declare its name, category and service.
+func ParseTemplate(content string) (template *Template, err error) {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	decoder.KnownFields(true)
+
+	template = new(Template)
+
+	if err = decoder.Decode(template); err != nil {
+		err = fmt.Errorf("invalid template: %v", err)
+		return
+	}
+
+	switch {
+	case template.Name == "":
+		err = fmt.Errorf("invalid template: missing name")
+	case template.Category == "":
+		err = fmt.Errorf("invalid template: missing category")
+	case template.Service.Kind != yaml.MappingNode:
+		err = fmt.Errorf("invalid template: service must be a docker-compose service definition")
+	}
+
+	return
+}
+
+// ServiceYaml renders the docker-compose service of the template,
+// with the template healthcheck unless the service declares one.
+func (t *Template) ServiceYaml() (content string, err error) {
+	var (
+		buf     bytes.Buffer
+		service = t.Service
+	)
+
+	if t.Healthcheck.Kind != 0 && !hasNodeKey(&service, "healthcheck") {
+		service.Content = append(append([]*yaml.Node{}, service.Content...),
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "healthcheck"},
+			&t.Healthcheck,
+		)
+	}
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err = encoder.Encode(&service); err != nil {
+		return
+	}
+
+	if err = encoder.Close(); err != nil {
+		return
+	}
+
+	content = buf.String()
+	return
+}
+
+// Networks gets the networks the template service is attached to.
+func (t *Template) Networks() (networks []string) {
+	for i := 0; i+1 < len(t.Service.Content); i += 2 {
+		if t.Service.Content[i].Value != "networks" {
+			continue
+		}
+
+		// networks are either a list or a mapping of names
+		for j, network := range t.Service.Content[i+1].Content {
+			if t.Service.Content[i+1].Kind == yaml.SequenceNode || j%2 == 0 {
+				networks = append(networks, network.Value)
+			}
+		}
+	}
+
+	return
+}
+
+func hasNodeKey(mapping *yaml.Node, key string) bool {
+	for i := 0; i < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return true
+		}
+	}
+
+	return false
+}
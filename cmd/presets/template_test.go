@@ -0,0 +1,119 @@
+package presets
+
+import (
+	"reflect"
+	"testing"
+)
+
+const redisTemplate = `name: Redis 6.0
+category: cache
+env:
+  KOOL_CACHE_PORT: "6379"
+ports:
+  - 6379
+volumes:
+  - cache
+healthcheck:
+  test: ["CMD", "redis-cli", "ping"]
+  interval: 10s
+scripts:
+  redis-cli: kool exec cache redis-cli
+service:
+  image: redis:6-alpine # can change to: redis:5-alpine
+  ports:
+   - "${KOOL_CACHE_PORT:-6379}:6379"
+  networks:
+   - kool_local
+   - kool_global
+`
+
+func TestParseTemplate(t *testing.T) {
+	template, err := ParseTemplate(redisTemplate)
+
+	if err != nil {
+		t.Fatalf("unexpected error parsing template: %v", err)
+	}
+
+	if template.Name != "Redis 6.0" || template.Category != "cache" {
+		t.Errorf("unexpected template name %s and category %s", template.Name, template.Category)
+	}
+
+	if !reflect.DeepEqual(template.Env, map[string]string{"KOOL_CACHE_PORT": "6379"}) {
+		t.Errorf("unexpected template env %v", template.Env)
+	}
+
+	if !reflect.DeepEqual(template.Ports, []int{6379}) || !reflect.DeepEqual(template.Volumes, []string{"cache"}) {
+		t.Errorf("unexpected template ports %v and volumes %v", template.Ports, template.Volumes)
+	}
+
+	if template.Scripts["redis-cli"] != "kool exec cache redis-cli" {
+		t.Errorf("unexpected template scripts %v", template.Scripts)
+	}
+
+	if networks := template.Networks(); !reflect.DeepEqual(networks, []string{"kool_local", "kool_global"}) {
+		t.Errorf("unexpected template networks %v", networks)
+	}
+}
+
+func TestInvalidParseTemplate(t *testing.T) {
+	invalid := map[string]string{
+		"category: cache\nservice:\n  image: redis\n":        "invalid template: missing name",
+		"name: Redis\nservice:\n  image: redis\n":            "invalid template: missing category",
+		"name: Redis\ncategory: cache\n":                     "invalid template: service must be a docker-compose service definition",
+		"name: Redis\ncategory: cache\nimage: redis\n":       "invalid template: yaml: unmarshal errors:\n  line 3: field image not found in type presets.Template",
+		"name: Redis\ncategory: cache\nservice: redis:6.0\n": "invalid template: service must be a docker-compose service definition",
+	}
+
+	for content, expected := range invalid {
+		if _, err := ParseTemplate(content); err == nil || err.Error() != expected {
+			t.Errorf("expecting error '%s', got '%v'", expected, err)
+		}
+	}
+}
+
+func TestServiceYamlTemplate(t *testing.T) {
+	template, _ := ParseTemplate(redisTemplate)
+
+	service, err := template.ServiceYaml()
+
+	if err != nil {
+		t.Fatalf("unexpected error rendering template service: %v", err)
+	}
+
+	expected := `image: redis:6-alpine # can change to: redis:5-alpine
+ports:
+  - "${KOOL_CACHE_PORT:-6379}:6379"
+networks:
+  - kool_local
+  - kool_global
+healthcheck:
+  test: ["CMD", "redis-cli", "ping"]
+  interval: 10s
+`
+
+	if service != expected {
+		t.Errorf("expecting service:\n%s\ngot:\n%s", expected, service)
+	}
+
+	if again, _ := template.ServiceYaml(); again != expected {
+		t.Errorf("rendering the service twice should not change it; got:\n%s", again)
+	}
+}
+
+func TestServiceYamlOwnHealthcheckTemplate(t *testing.T) {
+	template, _ := ParseTemplate(`name: Redis 6.0
+category: cache
+healthcheck:
+  test: ["CMD", "redis-cli", "ping"]
+service:
+  image: redis:6-alpine
+  healthcheck:
+    disable: true
+`)
+
+	service, _ := template.ServiceYaml()
+
+	if expected := "image: redis:6-alpine\nhealthcheck:\n  disable: true\n"; service != expected {
+		t.Errorf("expecting service:\n%s\ngot:\n%s", expected, service)
+	}
+}
@@ -1,15 +1,22 @@
 package cmd
 
 import (
+	"kool-dev/kool/cmd/compose"
+	"kool-dev/kool/environment"
+
 	"github.com/spf13/cobra"
 )
 
 // NewRestartCommand initializes new kool start command
 func NewRestartCommand(stop KoolService, start KoolService) *cobra.Command {
 	return &cobra.Command{
-		Use:                   "restart",
-		Short:                 "Restart containers - the same as stop followed by start.",
+		Use:   "restart [SERVICE...]",
+		Short: "Restart containers - the same as stop followed by start.",
+		Long: `Restart containers - the same as stop followed by start. When services are
+given, only their containers are recreated, leaving the rest of the project
+and its network running.`,
 		Run:                   DefaultCommandRunFunction(stop, start),
+		ValidArgsFunction:     completeServices(environment.NewEnvStorage(), compose.NewParser()),
 		DisableFlagsInUseLine: true,
 	}
 }
@@ -25,6 +25,26 @@ func TestRestartCommand(t *testing.T) {
 	}
 }
 
+func TestRestartServicesCommand(t *testing.T) {
+	fakeStop := &FakeKoolService{}
+	fakeStart := &FakeKoolService{}
+
+	cmd := NewRestartCommand(fakeStop, fakeStart)
+	cmd.SetArgs([]string{"worker"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing restart command; error: %v", err)
+	}
+
+	if len(fakeStop.ArgsExecute) != 1 || fakeStop.ArgsExecute[0] != "worker" {
+		t.Errorf("expecting to stop the worker service, got %v", fakeStop.ArgsExecute)
+	}
+
+	if len(fakeStart.ArgsExecute) != 1 || fakeStart.ArgsExecute[0] != "worker" {
+		t.Errorf("expecting to start the worker service, got %v", fakeStart.ArgsExecute)
+	}
+}
+
 func TestFailingStartRestartCommand(t *testing.T) {
 	fakeStop := &FakeKoolService{}
 	fakeStart := &FakeKoolService{}
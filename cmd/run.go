@@ -5,15 +5,24 @@ import (
 	"kool-dev/kool/cmd/builder"
 	"kool-dev/kool/cmd/parser"
 	"kool-dev/kool/environment"
+	"os"
+	"os/signal"
 	"path"
 	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
 )
 
+// KoolRunFlags holds the flags for the run command
+type KoolRunFlags struct {
+	Watch []string
+}
+
 // KoolRun holds handlers and functions to implement the run command logic
 type KoolRun struct {
 	DefaultKoolService
+	Flags      *KoolRunFlags
 	parser     parser.Parser
 	envStorage environment.EnvStorage
 	commands   []builder.Command
@@ -40,6 +49,7 @@ func init() {
 func NewKoolRun() *KoolRun {
 	return &KoolRun{
 		*newDefaultKoolService(),
+		&KoolRunFlags{[]string{}},
 		parser.NewParser(),
 		environment.NewEnvStorage(),
 		[]builder.Command{},
@@ -49,8 +59,9 @@ func NewKoolRun() *KoolRun {
 // Execute runs the run logic with incoming arguments.
 func (r *KoolRun) Execute(originalArgs []string) (err error) {
 	var (
-		script string
-		args   []string
+		script   string
+		args     []string
+		patterns []string
 	)
 
 	// look for kool.yml on current working directory
@@ -84,7 +95,18 @@ func (r *KoolRun) Execute(originalArgs []string) (err error) {
 		if len(args) > 0 {
 			command.AppendArgs(args...)
 		}
+	}
+
+	if patterns, err = r.watchPatterns(script); err != nil {
+		return
+	}
 
+	if len(patterns) > 0 {
+		err = r.watch(script, patterns)
+		return
+	}
+
+	for _, command := range r.commands {
 		if err = command.Interactive(); err != nil {
 			return
 		}
@@ -92,13 +114,59 @@ func (r *KoolRun) Execute(originalArgs []string) (err error) {
 	return
 }
 
+// watch runs the script again whenever the files of the working
+// directory matching the patterns change, until kool is interrupted
+// or terminated.
+func (r *KoolRun) watch(script string, patterns []string) (err error) {
+	var (
+		root    string
+		signals = make(chan os.Signal, 1)
+		done    = make(chan struct{})
+	)
+
+	if root, err = os.Getwd(); err != nil {
+		return
+	}
+
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	defer close(done)
+
+	err = newScriptWatcher(r.DefaultKoolService, script, r.commands, patterns, root).Watch(stopOnSignal(signals, done))
+	return
+}
+
+// watchPatterns gets the patterns of the files watched for running
+// the script again; the script watch key on kool.yml only applies
+// when running on a terminal, unlike the --watch flag.
+func (r *KoolRun) watchPatterns(script string) (patterns []string, err error) {
+	if len(r.Flags.Watch) > 0 {
+		patterns = r.Flags.Watch
+		return
+	}
+
+	if !r.IsTerminal() {
+		return
+	}
+
+	patterns, err = r.parser.Watch(script)
+	return
+}
+
 // NewRunCommand initializes new kool stop command
 func NewRunCommand(run *KoolRun) (runCmd *cobra.Command) {
 	runCmd = &cobra.Command{
 		Use:   "run [SCRIPT]",
 		Short: "Runs a custom command defined at kool.yaml in the working directory or in the kool folder of the user's home directory",
-		Args:  cobra.MinimumNArgs(1),
-		Run:   DefaultCommandRunFunction(run),
+		Long: `Runs a custom command defined at kool.yaml in the working directory or in the kool folder of the user's home directory.
+
+With --watch, or a script declared as a mapping with the run and watch keys
+on kool.yml, the script runs again whenever a file matching the given globs
+changes, stopping the previous run if it's still running. The globs follow
+the .gitignore syntax, so "!" excludes files. Files ignored by .gitignore and
+.koolignore files are not watched.`,
+		Args: cobra.MinimumNArgs(1),
+		Run:  DefaultCommandRunFunction(run),
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			if len(args) != 0 {
 				return nil, cobra.ShellCompDirectiveNoFileComp
@@ -108,6 +176,8 @@ func NewRunCommand(run *KoolRun) (runCmd *cobra.Command) {
 		},
 	}
 
+	runCmd.Flags().StringSliceVarP(&run.Flags.Watch, "watch", "w", []string{}, "Run the script again whenever files matching the globs change")
+
 	// after a non-flag arg, stop parsing flags
 	runCmd.Flags().SetInterspersed(false)
 
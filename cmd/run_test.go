@@ -14,6 +14,7 @@ import (
 func newFakeKoolRun(mockParsedCommands []builder.Command, mockParseError error) *KoolRun {
 	return &KoolRun{
 		*newFakeKoolService(),
+		&KoolRunFlags{[]string{}},
 		&parser.FakeParser{MockParsedCommands: mockParsedCommands, MockParseError: mockParseError},
 		environment.NewFakeEnvStorage(),
 		[]builder.Command{},
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/ignore"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long the file changes must settle before re-running
+var watchDebounce = 300 * time.Millisecond
+
+// watchStopGrace is how long a running command has to stop before being killed
+var watchStopGrace = 5 * time.Second
+
+// watchIgnoreFiles hold the patterns of the project files left out of
+// watching; just like .gitignore files they can be within any folder.
+var watchIgnoreFiles = []string{".gitignore", ".koolignore"}
+
+// scriptWatcher re-runs the script commands whenever
+// the project files matching the patterns change.
+type scriptWatcher struct {
+	DefaultKoolService
+	script   string
+	commands []builder.Command
+	patterns []string
+	watched  *ignore.Matcher
+	ignored  *ignore.Matcher
+	root     string
+}
+
+// scriptRun holds a background run of the script commands
+type scriptRun struct {
+	signals  chan os.Signal
+	canceled chan struct{}
+	done     chan error
+}
+
+func newScriptWatcher(service DefaultKoolService, script string, commands []builder.Command, patterns []string, root string) *scriptWatcher {
+	return &scriptWatcher{
+		service,
+		script,
+		commands,
+		patterns,
+		ignore.NewMatcher(patterns...),
+		ignore.NewMatcher(".git"),
+		root,
+	}
+}
+
+// stopOnSignal gets a channel closed when a signal is received,
+// unless done is closed first.
+func stopOnSignal(signals <-chan os.Signal, done <-chan struct{}) <-chan struct{} {
+	stop := make(chan struct{})
+
+	go func() {
+		select {
+		case <-signals:
+			close(stop)
+		case <-done:
+		}
+	}()
+
+	return stop
+}
+
+// Watch runs the script and then runs it again on every change,
+// stopping the previous run if still running, until the watching
+// fails or the stop channel is closed.
+func (w *scriptWatcher) Watch(stop <-chan struct{}) (err error) {
+	var (
+		watcher *fsnotify.Watcher
+		changes = make(chan string, 1)
+		run     *scriptRun
+		done    <-chan error
+	)
+
+	if watcher, err = fsnotify.NewWatcher(); err != nil {
+		return
+	}
+
+	defer watcher.Close()
+
+	if err = w.addFolders(watcher, w.root); err != nil {
+		return
+	}
+
+	go w.listen(watcher, changes)
+
+	stopRun := func() {
+		if done != nil {
+			run.stop()
+		}
+	}
+
+	w.Println("[watch]", "watching", strings.Join(w.patterns, ", "), "to run", w.script)
+	run = w.run()
+	done = run.done
+
+	for {
+		select {
+		case runErr := <-done:
+			done = nil
+
+			if runErr != nil {
+				w.Warning("[watch] ", w.script, " failed (", runErr, "), waiting for changes...")
+			} else {
+				w.Success("[watch] ", w.script, " finished, waiting for changes...")
+			}
+		case changed := <-changes:
+			w.Warning("[watch] ", changed, " changed, running ", w.script, " again")
+			stopRun()
+			run = w.run()
+			done = run.done
+		case err = <-watcher.Errors:
+			stopRun()
+			return
+		case <-stop:
+			stopRun()
+			return
+		}
+	}
+}
+
+// addFolders watches the folder and its subfolders, but the ignored
+// ones, reading the ignore files found along the way.
+func (w *scriptWatcher) addFolders(watcher *fsnotify.Watcher, folder string) error {
+	return filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return err
+		}
+
+		relPath, _ := filepath.Rel(w.root, path)
+
+		if relPath == "." {
+			relPath = ""
+		} else if w.ignored.Match(relPath, true) {
+			return filepath.SkipDir
+		}
+
+		for _, ignoreFile := range watchIgnoreFiles {
+			if err = w.ignored.AddFile(filepath.Join(path, ignoreFile), relPath); err != nil {
+				return err
+			}
+		}
+
+		return watcher.Add(path)
+	})
+}
+
+// listen sends the changed files matching the patterns once
+// the changes settle, watching the folders created meanwhile.
+func (w *scriptWatcher) listen(watcher *fsnotify.Watcher, changes chan<- string) {
+	var (
+		changed  string
+		debounce <-chan time.Time
+	)
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			relPath, err := filepath.Rel(w.root, event.Name)
+			info, statErr := os.Stat(event.Name)
+			isDir := statErr == nil && info.IsDir()
+
+			if err != nil || event.Op == fsnotify.Chmod || w.ignored.Match(relPath, isDir) {
+				continue
+			}
+
+			if isDir && event.Op&fsnotify.Create != 0 {
+				_ = w.addFolders(watcher, event.Name)
+			}
+
+			if w.watched.Match(relPath, isDir) {
+				changed = relPath
+				debounce = time.After(watchDebounce)
+			}
+		case <-debounce:
+			debounce = nil
+
+			select {
+			case changes <- changed:
+			default:
+			}
+		}
+	}
+}
+
+// run runs the script commands on background, one after the other
+func (w *scriptWatcher) run() (r *scriptRun) {
+	r = &scriptRun{make(chan os.Signal, 1), make(chan struct{}), make(chan error, 1)}
+
+	go func() {
+		var err error
+
+		for _, command := range w.commands {
+			select {
+			case <-r.canceled:
+				r.done <- err
+				return
+			default:
+			}
+
+			if runner, ok := command.(builder.SignalRunner); ok {
+				err = runner.InteractiveSignals(r.signals)
+			} else {
+				err = command.Interactive()
+			}
+
+			if err != nil {
+				break
+			}
+		}
+
+		r.done <- err
+	}()
+
+	return
+}
+
+// stop terminates the running command, killing it in case
+// it doesn't stop within the grace period, and waits the run.
+func (r *scriptRun) stop() {
+	close(r.canceled)
+	r.signals <- syscall.SIGTERM
+
+	select {
+	case <-r.done:
+		return
+	case <-time.After(watchStopGrace):
+	}
+
+	select {
+	case r.signals <- os.Kill:
+	case <-r.done:
+		return
+	}
+
+	<-r.done
+}
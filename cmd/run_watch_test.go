@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/cmd/parser"
+	"kool-dev/kool/cmd/shell"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestScriptWatcherPatterns(t *testing.T) {
+	w := newScriptWatcher(*newFakeKoolService(), "test", nil, []string{"*.go", "!*_test.go", "/docs/*.md", "src/**/*.php", "vendor/"}, t.TempDir())
+
+	for relPath, expected := range map[string]bool{
+		"main.go":                true,
+		"cmd/run.go":             true,
+		"cmd/run_test.go":        false,
+		"docs/index.md":          true,
+		"docs/nested/index.md":   false,
+		"src/App.php":            true,
+		"src/Http/Kernel.php":    true,
+		"app/Http/Kernel.php":    false,
+		"vendor/autoload.js":     true,
+		"lib/vendor/autoload.js": true,
+		"go.mod":                 false,
+	} {
+		if matched := w.watched.Match(relPath, false); matched != expected {
+			t.Errorf("unexpected match %v for %s", matched, relPath)
+		}
+	}
+}
+
+func TestScriptWatcherIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+
+	for _, folder := range []string{"sub/generated", "generated", "node_modules/pkg"} {
+		if err := os.MkdirAll(filepath.Join(root, folder), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(root, ".gitignore"), []byte("# deps\nnode_modules\n*.log\n!keep.log\n\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(root, ".koolignore"), []byte("*.tmp\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(root, "sub", ".gitignore"), []byte("generated/\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer watcher.Close()
+
+	w := newScriptWatcher(*newFakeKoolService(), "test", nil, []string{"*.go"}, root)
+
+	if err = w.addFolders(watcher, root); err != nil {
+		t.Fatalf("unexpected error watching folders: %v", err)
+	}
+
+	for relPath, expected := range map[string]bool{
+		".git/HEAD":                 true,
+		"app.log":                   true,
+		"keep.log":                  false,
+		"cache.tmp":                 true,
+		"node_modules/pkg/index.js": true,
+		"sub/generated/main.go":     true,
+		"generated/main.go":         false,
+		"sub/main.go":               false,
+	} {
+		if ignored := w.ignored.Match(relPath, false); ignored != expected {
+			t.Errorf("unexpected ignored %v for %s", ignored, relPath)
+		}
+	}
+}
+
+func TestRunWatchPatterns(t *testing.T) {
+	f := newFakeKoolRun(nil, nil)
+	f.parser.(*parser.FakeParser).MockWatch = []string{"*.go"}
+
+	if patterns, _ := f.watchPatterns("test"); !reflect.DeepEqual(patterns, []string{"*.go"}) {
+		t.Errorf("expecting the kool.yml watch patterns, got %v", patterns)
+	}
+
+	f.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+
+	if patterns, _ := f.watchPatterns("test"); len(patterns) != 0 {
+		t.Errorf("unexpected kool.yml watch patterns out of a terminal, got %v", patterns)
+	}
+
+	f.Flags.Watch = []string{"*.php"}
+
+	if patterns, _ := f.watchPatterns("test"); !reflect.DeepEqual(patterns, []string{"*.php"}) {
+		t.Errorf("expecting the --watch patterns, got %v", patterns)
+	}
+}
+
+func TestStopOnSignal(t *testing.T) {
+	var (
+		signals = make(chan os.Signal, 1)
+		stop    = stopOnSignal(signals, make(chan struct{}))
+	)
+
+	signals <- os.Interrupt
+
+	select {
+	case <-stop:
+	case <-time.After(5 * time.Second):
+		t.Fatal("did not stop watching on the signal")
+	}
+
+	done := make(chan struct{})
+	stop = stopOnSignal(make(chan os.Signal), done)
+	close(done)
+
+	select {
+	case <-stop:
+		t.Error("unexpected stop without signals")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestScriptWatcherRestartsOnChanges(t *testing.T) {
+	originalDebounce := watchDebounce
+	defer func() { watchDebounce = originalDebounce }()
+	watchDebounce = 10 * time.Millisecond
+
+	root := t.TempDir()
+
+	if err := os.Mkdir(filepath.Join(root, "ignored"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(root, ".koolignore"), []byte("ignored\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		started  = make(chan bool, 10)
+		received = make(chan os.Signal, 10)
+		command  = &builder.FakeCommand{MockSignalsFn: func(signals <-chan os.Signal) error {
+			started <- true
+			sig := <-signals
+			received <- sig
+			return errors.New("signal: terminated")
+		}}
+		stop     = make(chan struct{})
+		finished = make(chan error)
+		w        = newScriptWatcher(*newFakeKoolService(), "test", []builder.Command{command}, []string{"*.go"}, root)
+	)
+
+	go func() { finished <- w.Watch(stop) }()
+
+	waitFor := func(ch <-chan bool, description string) {
+		select {
+		case <-ch:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s", description)
+		}
+	}
+
+	waitFor(started, "the first run")
+
+	_ = ioutil.WriteFile(filepath.Join(root, "ignored", "main.go"), []byte("package main"), os.ModePerm)
+	_ = ioutil.WriteFile(filepath.Join(root, "notes.txt"), []byte("notes"), os.ModePerm)
+	_ = ioutil.WriteFile(filepath.Join(root, "main.go"), []byte("package main"), os.ModePerm)
+
+	waitFor(started, "running again after the change")
+
+	if sig := <-received; sig != syscall.SIGTERM {
+		t.Errorf("expecting the previous run to be terminated, got %v", sig)
+	}
+
+	close(stop)
+
+	if err := <-finished; err != nil {
+		t.Errorf("unexpected error watching; error: %v", err)
+	}
+
+	select {
+	case <-started:
+		t.Error("unexpected runs for ignored or not matching files")
+	default:
+	}
+
+	if !w.out.(*shell.FakeOutputWriter).CalledWarning {
+		t.Error("did not warn about the changed file")
+	}
+}
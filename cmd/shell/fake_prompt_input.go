@@ -0,0 +1,24 @@
+package shell
+
+// FakePromptInput holds data for fake prompt input behavior
+type FakePromptInput struct {
+	CalledInput map[string]bool
+	MockAnswer  map[string]string
+	MockError   map[string]error
+}
+
+// Input fake behavior for prompting a text input question
+func (f *FakePromptInput) Input(question string, defaultAnswer string) (answer string, err error) {
+	if f.CalledInput == nil {
+		f.CalledInput = make(map[string]bool)
+	}
+
+	f.CalledInput[question] = true
+
+	if answer = f.MockAnswer[question]; answer == "" {
+		answer = defaultAnswer
+	}
+
+	err = f.MockError[question]
+	return
+}
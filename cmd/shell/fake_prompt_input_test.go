@@ -0,0 +1,31 @@
+package shell
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFakePromptInput(t *testing.T) {
+	f := &FakePromptInput{}
+	f.MockAnswer = map[string]string{"question": "answer"}
+
+	answer, err := f.Input("question", "default")
+
+	if err != nil {
+		t.Errorf("unexpected error on Input: %v", err)
+	}
+
+	if !f.CalledInput["question"] || answer != "answer" {
+		t.Errorf("expecting answer 'answer', got %s", answer)
+	}
+
+	if answer, _ = f.Input("other question", "default"); answer != "default" {
+		t.Errorf("expecting default answer 'default', got %s", answer)
+	}
+
+	f.MockError = map[string]error{"question": errors.New("error")}
+
+	if _, err = f.Input("question", "default"); err == nil {
+		t.Errorf("should throw an error on Input")
+	}
+}
@@ -0,0 +1,28 @@
+package shell
+
+import (
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// PromptInput contract that holds logic for prompt a text input question
+type PromptInput interface {
+	Input(string, string) (string, error)
+}
+
+// DefaultPromptInput holds data for prompting a text input question
+type DefaultPromptInput struct{}
+
+// NewPromptInput creates a new prompt input
+func NewPromptInput() PromptInput {
+	return &DefaultPromptInput{}
+}
+
+// Input prompt to the user a text input question with the given default answer
+func (p *DefaultPromptInput) Input(question string, defaultAnswer string) (answer string, err error) {
+	prompt := &survey.Input{
+		Message: question,
+		Default: defaultAnswer,
+	}
+	err = survey.AskOne(prompt, &answer)
+	return
+}
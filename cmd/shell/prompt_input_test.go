@@ -0,0 +1,42 @@
+package shell
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNewPromptInput(t *testing.T) {
+	p := NewPromptInput()
+
+	if _, ok := p.(*DefaultPromptInput); !ok {
+		t.Errorf("unexpected PromptInput on NewPromptInput")
+	}
+}
+
+func TestInputPromptInput(t *testing.T) {
+	oldStdout := os.Stdout
+
+	r, w, _ := os.Pipe()
+
+	os.Stdout = w
+
+	p := NewPromptInput()
+
+	_, _ = p.Input("testing_question", "testing_default")
+
+	w.Close()
+	out, err := ioutil.ReadAll(r)
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := string(out)
+
+	if !strings.Contains(output, "testing_question") || !strings.Contains(output, "testing_default") {
+		t.Error("failed to render the question and its default answer")
+	}
+}
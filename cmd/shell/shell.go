@@ -2,6 +2,7 @@ package shell
 
 import (
 	"fmt"
+	"io"
 	"kool-dev/kool/environment"
 	"log"
 	"os"
@@ -36,9 +37,47 @@ func Exec(exe string, args ...string) (outStr string, err error) {
 	return
 }
 
+// Stream runs the given command writing its standard output to the
+// given writer as it comes, while proxying current Stdin/Stderr.
+func Stream(w io.Writer, exe string, args ...string) (err error) {
+	var cmd *exec.Cmd
+
+	if exe == "docker-compose" {
+		args = append(dockerComposeDefaultArgs(), args...)
+	}
+
+	if environment.NewEnvStorage().IsTrue("KOOL_VERBOSE") {
+		fmt.Println("$", exe, strings.Join(args, " "))
+	}
+
+	if err = lookPath(exe); err != nil {
+		return
+	}
+
+	cmd = exec.Command(exe, args...)
+	cmd.Env = os.Environ()
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Run()
+	return
+}
+
 // Interactive runs the given command proxying current Stdin/Stdout/Stderr
 // which makes it interactive for running even something like `bash`.
 func Interactive(exe string, args ...string) (err error) {
+	return interactive(nil, true, exe, args...)
+}
+
+// InteractiveSignals runs the given command just like Interactive, also
+// forwarding it the signals sent through the given channel. The command
+// failure is returned as an error instead of exiting.
+func InteractiveSignals(signals <-chan os.Signal, exe string, args ...string) (err error) {
+	return interactive(signals, false, exe, args...)
+}
+
+func interactive(signals <-chan os.Signal, exitOnError bool, exe string, args ...string) (err error) {
 	var (
 		cmd            *exec.Cmd
 		parsedRedirect *DefaultParsedRedirect
@@ -83,11 +122,15 @@ func Interactive(exe string, args ...string) (err error) {
 	}()
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan)
+	defer signal.Stop(sigChan)
 
 	// You need a for loop to handle multiple signals
 	for {
 		select {
 		case err = <-waitCh:
+			if !exitOnError {
+				return
+			}
 			// Subprocess exited. Get the return code, if we can
 			var waitStatus syscall.WaitStatus
 			if exitError, ok := err.(*exec.ExitError); ok {
@@ -98,6 +141,10 @@ func Interactive(exe string, args ...string) (err error) {
 				log.Fatal(err)
 			}
 			return
+		case sig := <-signals:
+			if err := cmd.Process.Signal(sig); err != nil && err.Error() != "os: process already finished" {
+				outputWriter.Error(fmt.Errorf("error sending signal to child process %v %v", sig, err))
+			}
 		case sig := <-sigChan:
 			if err := cmd.Process.Signal(sig); err != nil {
 				// check if it is something we should care about
@@ -22,6 +22,18 @@ func TestShellExec(t *testing.T) {
 	}
 }
 
+func TestShellStream(t *testing.T) {
+	var output bytes.Buffer
+
+	if err := Stream(&output, "echo", "x"); err != nil {
+		t.Fatal(err)
+	}
+
+	if out := strings.TrimSpace(output.String()); out != "x" {
+		t.Errorf("Stream failed; expected output 'x', got '%s'", out)
+	}
+}
+
 func TestShellInteractive(t *testing.T) {
 	r, w, err := os.Pipe()
 
@@ -57,3 +69,18 @@ func TestShellInteractive(t *testing.T) {
 		t.Errorf("Interactive failed; expected output 'x', got '%s'", output)
 	}
 }
+
+func TestShellInteractiveSignals(t *testing.T) {
+	signals := make(chan os.Signal, 1)
+	signals <- os.Kill
+
+	err := InteractiveSignals(signals, "sleep", "10")
+
+	if err == nil || !strings.Contains(err.Error(), "killed") {
+		t.Errorf("expecting the command to be killed, got '%v'", err)
+	}
+
+	if err = InteractiveSignals(nil, "false"); err == nil {
+		t.Error("expecting the command failure to be returned")
+	}
+}
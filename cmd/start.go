@@ -1,32 +1,71 @@
 package cmd
 
 import (
+	"fmt"
 	"kool-dev/kool/cmd/builder"
 	"kool-dev/kool/cmd/checker"
+	"kool-dev/kool/cmd/compose"
 	"kool-dev/kool/cmd/network"
+	"kool-dev/kool/cmd/parser"
 	"kool-dev/kool/environment"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
+// defaultStartWaitTimeout is how long start --wait waits for the services
+const defaultStartWaitTimeout = 2 * time.Minute
+
+// KoolStartFlags holds the flags for the start command
+type KoolStartFlags struct {
+	Wait     bool
+	Timeout  time.Duration
+	Recreate bool
+	Build    bool
+	Pull     bool
+}
+
 // KoolStart holds handlers and functions for starting containers logic
 type KoolStart struct {
 	DefaultKoolService
+	Flags *KoolStartFlags
 
 	check      checker.Checker
 	net        network.Handler
 	envStorage environment.EnvStorage
 	start      builder.Runner
+	services   builder.Runner
+	readiness  *serviceReadiness
+	pull       builder.Runner
+	containers builder.Runner
+	inspect    builder.Runner
+
+	composeParser compose.Parser
 }
 
 // NewStartCommand initializes new kool start command
-func NewStartCommand(start *KoolStart) *cobra.Command {
-	return &cobra.Command{
-		Use:                   "start [SERVICE]",
-		Short:                 "Start the specified Kool environment containers. If no service is specified, start all.",
+func NewStartCommand(start *KoolStart) (startCmd *cobra.Command) {
+	startCmd = &cobra.Command{
+		Use:   "start [SERVICE]",
+		Short: "Start the specified Kool environment containers. If no service is specified, start all.",
+		Long: `Start the specified Kool environment containers. If no service is specified, start all.
+
+With --wait, or "start: {wait: true}" on kool.yml, it blocks until every started
+service is healthy through its docker-compose healthcheck or, for services
+without healthcheck, until their published ports accept connections.`,
 		Run:                   DefaultCommandRunFunction(start),
+		ValidArgsFunction:     completeServices(start.envStorage, start.composeParser),
 		DisableFlagsInUseLine: true,
 	}
+
+	startCmd.Flags().BoolVarP(&start.Flags.Recreate, "recreate", "", false, "Recreate the containers even if their configuration and image haven't changed")
+	startCmd.Flags().BoolVarP(&start.Flags.Build, "build", "", false, "Build the images before starting the containers")
+	startCmd.Flags().BoolVarP(&start.Flags.Pull, "pull", "", false, "Pull the latest images before starting the containers")
+	startCmd.Flags().BoolVarP(&start.Flags.Wait, "wait", "", false, "Wait for the services to be ready")
+	startCmd.Flags().DurationVarP(&start.Flags.Timeout, "timeout", "", 0, "How long to wait for the services to be ready (default 2m, or start.timeout seconds on kool.yml)")
+	return
 }
 
 // NewKoolStart creates a new pointer with default KoolStart service
@@ -34,10 +73,17 @@ func NewStartCommand(start *KoolStart) *cobra.Command {
 func NewKoolStart() *KoolStart {
 	return &KoolStart{
 		*newDefaultKoolService(),
+		&KoolStartFlags{false, 0, false, false, false},
 		checker.NewChecker(),
 		network.NewHandler(),
 		environment.NewEnvStorage(),
-		builder.NewCommand("docker-compose", "up", "-d", "--force-recreate"),
+		builder.NewCommand("docker-compose", "up", "-d"),
+		builder.NewCommand("docker-compose", "config", "--services"),
+		newServiceReadiness(),
+		builder.NewCommand("docker-compose", "pull"),
+		builder.NewCommand("docker-compose", "ps", "-a", "-q"),
+		builder.NewCommand("docker", "inspect", "--format", `{{index .Config.Labels "com.docker.compose.service"}} {{.Id}} {{.State.Running}}`),
+		compose.NewParser(),
 	}
 }
 
@@ -55,6 +101,114 @@ func (s *KoolStart) Execute(args []string) (err error) {
 		return
 	}
 
-	err = s.start.Interactive(args...)
+	if s.Flags.Pull {
+		if err = s.pull.Interactive(args...); err != nil {
+			return
+		}
+	}
+
+	// the summary is informational, so it's left out when
+	// the containers can't be listed
+	before, beforeErr := s.containersState()
+
+	if err = s.start.Interactive(append(s.upOptions(), args...)...); err != nil {
+		return
+	}
+
+	if after, afterErr := s.containersState(); beforeErr == nil && afterErr == nil {
+		s.printSummary(before, after)
+	}
+
+	err = s.wait(args)
+	return
+}
+
+// upOptions gets the docker-compose up options for the start flags
+func (s *KoolStart) upOptions() (options []string) {
+	if s.Flags.Recreate {
+		options = append(options, "--force-recreate")
+	}
+
+	if s.Flags.Build {
+		options = append(options, "--build")
+	}
+
+	return
+}
+
+// wait blocks until the started services are ready, in case
+// it's asked by the --wait flag or the kool.yml start options.
+func (s *KoolStart) wait(services []string) (err error) {
+	var (
+		options  parser.StartOptions
+		timeout  = s.Flags.Timeout
+		deadline time.Time
+		output   string
+	)
+
+	// kool.yml only matters for a plain start when it asks for
+	// waiting, so a broken one doesn't get in the way
+	if options, err = readStartOptions(); err != nil && !s.Flags.Wait {
+		s.Warning("Ignoring kool.yml start options: ", err)
+		err = nil
+	} else if err != nil {
+		return
+	}
+
+	if !s.Flags.Wait && !options.Wait {
+		return
+	}
+
+	if timeout == 0 && options.Timeout > 0 {
+		timeout = time.Duration(options.Timeout) * time.Second
+	} else if timeout == 0 {
+		timeout = defaultStartWaitTimeout
+	}
+
+	if len(services) == 0 {
+		if output, err = s.services.Exec(); err != nil {
+			return
+		}
+
+		services = strings.Fields(output)
+	}
+
+	deadline = time.Now().Add(timeout)
+
+	for _, service := range services {
+		task := NewKoolTask(fmt.Sprintf("Waiting for %s", service), &serviceWait{
+			s.DefaultKoolService,
+			service,
+			deadline,
+			s.readiness,
+		})
+
+		if err = task.Run(nil); err != nil {
+			err = fmt.Errorf("service %s did not become ready within %v: %v", service, timeout, err)
+			return
+		}
+	}
+
+	return
+}
+
+// readStartOptions reads the start options of the
+// project kool.yml, if there is one.
+func readStartOptions() (options parser.StartOptions, err error) {
+	var koolYaml *parser.KoolYaml
+
+	for _, file := range []string{"kool.yml", "kool.yaml"} {
+		if koolYaml, err = parser.ParseKoolYaml(file); os.IsNotExist(err) {
+			err = nil
+			continue
+		}
+
+		if err == nil {
+			options = koolYaml.Start
+		}
+
+		return
+	}
+
 	return
 }
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+)
+
+// containerState is the state of the containers of a service; scaled
+// services have many containers, so their ids are kept sorted
+type containerState struct {
+	ids     []string
+	running bool
+}
+
+// containersState gets the state of the project containers by service
+func (s *KoolStart) containersState() (states map[string]containerState, err error) {
+	var output string
+
+	states = make(map[string]containerState)
+
+	if output, err = s.containers.Exec(); err != nil {
+		return
+	}
+
+	ids := strings.Fields(output)
+
+	if len(ids) == 0 {
+		return
+	}
+
+	if output, err = s.inspect.Exec(ids...); err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+
+		if len(fields) != 3 {
+			continue
+		}
+
+		// scaled services have many containers; any running one counts
+		state := states[fields[0]]
+		state.ids = append(state.ids, fields[1])
+		state.running = state.running || fields[2] == "true"
+		states[fields[0]] = state
+	}
+
+	for _, state := range states {
+		sort.Strings(state.ids)
+	}
+
+	return
+}
+
+// printSummary tells which services got their containers created,
+// recreated or started, and which ones were already up to date.
+func (s *KoolStart) printSummary(before map[string]containerState, after map[string]containerState) {
+	var (
+		summary = make(map[string][]string)
+		labels  = []string{"Created", "Recreated", "Started", "Up to date"}
+	)
+
+	for service, state := range after {
+		previous, existed := before[service]
+
+		switch {
+		case !state.running:
+			continue
+		case !existed:
+			summary["Created"] = append(summary["Created"], service)
+		case strings.Join(previous.ids, " ") != strings.Join(state.ids, " "):
+			summary["Recreated"] = append(summary["Recreated"], service)
+		case !previous.running:
+			summary["Started"] = append(summary["Started"], service)
+		default:
+			summary["Up to date"] = append(summary["Up to date"], service)
+		}
+	}
+
+	for _, label := range labels {
+		if services := summary[label]; len(services) > 0 {
+			sort.Strings(services)
+			s.Println(label+":", strings.Join(services, ", "))
+		}
+	}
+}
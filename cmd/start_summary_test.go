@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/cmd/shell"
+	"reflect"
+	"testing"
+)
+
+func TestContainersState(t *testing.T) {
+	koolStart := newFakeWaitKoolStart()
+	koolStart.containers.(*builder.FakeCommand).MockExecOut = "id1\nid3\nid2\n"
+	koolStart.inspect.(*builder.FakeCommand).MockExecOut = "app id1 true\ndatabase id3 true\ndatabase id2 false\n"
+
+	states, err := koolStart.containersState()
+
+	if err != nil {
+		t.Fatalf("unexpected error getting containers state: %v", err)
+	}
+
+	if args := koolStart.inspect.(*builder.FakeCommand).ArgsExec; !reflect.DeepEqual(args, []string{"id1", "id3", "id2"}) {
+		t.Errorf("unexpected containers inspected: %v", args)
+	}
+
+	expected := map[string]containerState{
+		"app":      {[]string{"id1"}, true},
+		"database": {[]string{"id2", "id3"}, true},
+	}
+
+	if !reflect.DeepEqual(states, expected) {
+		t.Errorf("expecting states %v, got %v", expected, states)
+	}
+}
+
+func TestNoContainersState(t *testing.T) {
+	koolStart := newFakeWaitKoolStart()
+
+	states, err := koolStart.containersState()
+
+	if err != nil || len(states) != 0 {
+		t.Errorf("expecting no containers, got %v (err: %v)", states, err)
+	}
+
+	if koolStart.inspect.(*builder.FakeCommand).CalledExec {
+		t.Error("unexpected inspecting without containers")
+	}
+}
+
+func TestPrintSummary(t *testing.T) {
+	koolStart := newFakeWaitKoolStart()
+
+	koolStart.printSummary(map[string]containerState{
+		"database": {[]string{"id2"}, true},
+		"cache":    {[]string{"id3"}, false},
+		"mail":     {[]string{"id4"}, true},
+		"queue":    {[]string{"id5"}, false},
+		"web":      {[]string{"id8", "id9"}, true},
+		"scaled":   {[]string{"id10", "id11"}, true},
+	}, map[string]containerState{
+		"app":      {[]string{"id1"}, true},
+		"database": {[]string{"id2"}, true},
+		"cache":    {[]string{"id3"}, true},
+		"mail":     {[]string{"id6"}, true},
+		"queue":    {[]string{"id5"}, false},
+		"worker":   {[]string{"id7"}, true},
+		"web":      {[]string{"id8", "id9"}, true},
+		"scaled":   {[]string{"id10", "id12"}, true},
+	})
+
+	expected := []string{
+		"Created: app, worker",
+		"Recreated: mail, scaled",
+		"Started: cache",
+		"Up to date: database, web",
+	}
+
+	if lines := koolStart.out.(*shell.FakeOutputWriter).OutLines; !reflect.DeepEqual(lines, expected) {
+		t.Errorf("expecting summary %v, got %v", expected, lines)
+	}
+}
@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"errors"
 	"io/ioutil"
+	"kool-dev/kool/cmd/builder"
+	"kool-dev/kool/cmd/compose"
 	"kool-dev/kool/cmd/shell"
 	"kool-dev/kool/environment"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -21,6 +25,10 @@ func (c *FakeStartDependenciesChecker) Check() (err error) {
 	return
 }
 
+func (c *FakeStartDependenciesChecker) Versions() (docker string, dockerCompose string, err error) {
+	return
+}
+
 type FakeStartFailedDependenciesChecker struct{}
 
 func (c *FakeStartFailedDependenciesChecker) Check() (err error) {
@@ -28,6 +36,11 @@ func (c *FakeStartFailedDependenciesChecker) Check() (err error) {
 	return
 }
 
+func (c *FakeStartFailedDependenciesChecker) Versions() (docker string, dockerCompose string, err error) {
+	err = errors.New("dependencies")
+	return
+}
+
 type FakeStartNetworkHandler struct{}
 
 func (c *FakeStartNetworkHandler) HandleGlobalNetwork(networkName string) (err error) {
@@ -73,10 +86,17 @@ func (c *FakeFailedStartRunner) Interactive(args ...string) (err error) {
 func TestStartAllCommand(t *testing.T) {
 	koolStart := &KoolStart{
 		*newFakeKoolService(),
+		&KoolStartFlags{false, 0, false, false, false},
 		&FakeStartDependenciesChecker{},
 		&FakeStartNetworkHandler{},
 		environment.NewFakeEnvStorage(),
 		&FakeStartRunner{},
+		&builder.FakeCommand{},
+		newFakeServiceReadiness(),
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&compose.FakeParser{},
 	}
 
 	cmd := NewStartCommand(koolStart)
@@ -101,10 +121,17 @@ func TestStartAllCommand(t *testing.T) {
 func TestStartServicesCommand(t *testing.T) {
 	koolStart := &KoolStart{
 		*newFakeKoolService(),
+		&KoolStartFlags{false, 0, false, false, false},
 		&FakeStartDependenciesChecker{},
 		&FakeStartNetworkHandler{},
 		environment.NewFakeEnvStorage(),
 		&FakeStartRunner{},
+		&builder.FakeCommand{},
+		newFakeServiceReadiness(),
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&compose.FakeParser{},
 	}
 
 	cmd := NewStartCommand(koolStart)
@@ -127,10 +154,17 @@ func TestStartServicesCommand(t *testing.T) {
 func TestFailedDependenciesStartCommand(t *testing.T) {
 	koolStart := &KoolStart{
 		*newFakeKoolService(),
+		&KoolStartFlags{false, 0, false, false, false},
 		&FakeStartFailedDependenciesChecker{},
 		&FakeStartNetworkHandler{},
 		environment.NewFakeEnvStorage(),
 		&FakeStartRunner{},
+		&builder.FakeCommand{},
+		newFakeServiceReadiness(),
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&compose.FakeParser{},
 	}
 
 	cmd := NewStartCommand(koolStart)
@@ -149,10 +183,17 @@ func TestFailedDependenciesStartCommand(t *testing.T) {
 func TestFailedNetworkStartCommand(t *testing.T) {
 	koolStart := &KoolStart{
 		*newFakeKoolService(),
+		&KoolStartFlags{false, 0, false, false, false},
 		&FakeStartDependenciesChecker{},
 		&FakeStartFailedNetworkHandler{},
 		environment.NewFakeEnvStorage(),
 		&FakeStartRunner{},
+		&builder.FakeCommand{},
+		newFakeServiceReadiness(),
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&compose.FakeParser{},
 	}
 
 	cmd := NewStartCommand(koolStart)
@@ -171,10 +212,17 @@ func TestFailedNetworkStartCommand(t *testing.T) {
 func TestStartWithError(t *testing.T) {
 	koolStart := &KoolStart{
 		*newFakeKoolService(),
+		&KoolStartFlags{false, 0, false, false, false},
 		&FakeStartDependenciesChecker{},
 		&FakeStartNetworkHandler{},
 		environment.NewFakeEnvStorage(),
 		&FakeFailedStartRunner{},
+		&builder.FakeCommand{},
+		newFakeServiceReadiness(),
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&compose.FakeParser{},
 	}
 
 	cmd := NewStartCommand(koolStart)
@@ -218,3 +266,196 @@ func startedServicesAreEqual(a, b []string) bool {
 	}
 	return true
 }
+
+func newFakeWaitKoolStart() *KoolStart {
+	koolStart := &KoolStart{
+		*newFakeKoolService(),
+		&KoolStartFlags{false, 0, false, false, false},
+		&FakeStartDependenciesChecker{},
+		&FakeStartNetworkHandler{},
+		environment.NewFakeEnvStorage(),
+		&FakeStartRunner{},
+		&builder.FakeCommand{MockExecOut: "app\ndatabase\n"},
+		newFakeServiceReadiness(),
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&compose.FakeParser{},
+	}
+	koolStart.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+	koolStart.readiness.id.(*builder.FakeCommand).MockExecOut = "id"
+	return koolStart
+}
+
+func TestStartWaitCommand(t *testing.T) {
+	koolStart := newFakeWaitKoolStart()
+	koolStart.readiness.status.(*builder.FakeCommand).MockExecOut = "healthy"
+
+	cmd := NewStartCommand(koolStart)
+	cmd.SetArgs([]string{"--wait"})
+
+	if _, err := execStartCommand(cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	if koolStart.out.(*shell.FakeOutputWriter).CalledError {
+		t.Errorf("unexpected error: %v", koolStart.out.(*shell.FakeOutputWriter).Err)
+	}
+
+	if !koolStart.services.(*builder.FakeCommand).CalledExec {
+		t.Error("did not list the services to wait for")
+	}
+
+	if args := koolStart.readiness.id.(*builder.FakeCommand).ArgsExec; len(args) != 1 || args[0] != "database" {
+		t.Errorf("expecting to wait for the database service last, got %v", args)
+	}
+}
+
+func TestStartWaitTimeoutCommand(t *testing.T) {
+	startWaitInterval = 0
+	koolStart := newFakeWaitKoolStart()
+	koolStart.readiness.status.(*builder.FakeCommand).MockExecOut = "starting"
+
+	cmd := NewStartCommand(koolStart)
+	cmd.SetArgs([]string{"--wait", "--timeout", "1ms", "app"})
+
+	if _, err := execStartCommand(cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "service app did not become ready within 1ms: service app is not ready (starting)"
+
+	if err := koolStart.out.(*shell.FakeOutputWriter).Err; err == nil || err.Error() != expected {
+		t.Errorf("expecting error '%s', got '%v'", expected, err)
+	}
+
+	if koolStart.services.(*builder.FakeCommand).CalledExec {
+		t.Error("unexpected services listing when starting given services")
+	}
+}
+
+func TestStartNoWaitCommand(t *testing.T) {
+	koolStart := newFakeWaitKoolStart()
+
+	cmd := NewStartCommand(koolStart)
+
+	if _, err := execStartCommand(cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	if koolStart.readiness.id.(*builder.FakeCommand).CalledExec {
+		t.Error("unexpected waiting for services without --wait")
+	}
+}
+
+func TestStartWaitKoolYamlCommand(t *testing.T) {
+	wd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(wd) }()
+
+	tmp := t.TempDir()
+	_ = ioutil.WriteFile(filepath.Join(tmp, "kool.yml"), []byte("start:\n  wait: true\n  timeout: 1\n"), os.ModePerm)
+	_ = os.Chdir(tmp)
+
+	koolStart := newFakeWaitKoolStart()
+	koolStart.readiness.status.(*builder.FakeCommand).MockExecOut = "healthy"
+
+	cmd := NewStartCommand(koolStart)
+
+	if _, err := execStartCommand(cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	if !koolStart.readiness.id.(*builder.FakeCommand).CalledExec {
+		t.Error("did not wait for the services as set on kool.yml")
+	}
+}
+
+func TestStartInvalidKoolYamlCommand(t *testing.T) {
+	wd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(wd) }()
+
+	tmp := t.TempDir()
+	_ = ioutil.WriteFile(filepath.Join(tmp, "kool.yml"), []byte("scripts: ["), os.ModePerm)
+	_ = os.Chdir(tmp)
+
+	koolStart := newFakeWaitKoolStart()
+
+	if _, err := execStartCommand(NewStartCommand(koolStart)); err != nil {
+		t.Fatal(err)
+	}
+
+	if koolStart.out.(*shell.FakeOutputWriter).CalledError {
+		t.Errorf("unexpected error starting with a broken kool.yml: %v", koolStart.out.(*shell.FakeOutputWriter).Err)
+	}
+
+	if !koolStart.out.(*shell.FakeOutputWriter).CalledWarning {
+		t.Error("expected a warning about the broken kool.yml")
+	}
+
+	koolStart = newFakeWaitKoolStart()
+
+	cmd := NewStartCommand(koolStart)
+	cmd.SetArgs([]string{"--wait"})
+
+	if _, err := execStartCommand(cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	if !koolStart.out.(*shell.FakeOutputWriter).CalledError {
+		t.Error("expected error waiting with a broken kool.yml")
+	}
+}
+
+func TestStartOptionsCommand(t *testing.T) {
+	koolStart := newFakeWaitKoolStart()
+
+	cmd := NewStartCommand(koolStart)
+	cmd.SetArgs([]string{"--recreate", "--build", "--pull", "app"})
+
+	if _, err := execStartCommand(cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	if koolStart.out.(*shell.FakeOutputWriter).CalledError {
+		t.Errorf("unexpected error: %v", koolStart.out.(*shell.FakeOutputWriter).Err)
+	}
+
+	if args := koolStart.pull.(*builder.FakeCommand).ArgsInteractive; len(args) != 1 || args[0] != "app" {
+		t.Errorf("expecting to pull the app service image, got %v", args)
+	}
+
+	if expected := []string{"--force-recreate", "--build", "app"}; !startedServicesAreEqual(startedServices, expected) {
+		t.Errorf("expecting docker-compose up arguments %v, got %v", expected, startedServices)
+	}
+}
+
+func TestStartNoRecreateCommand(t *testing.T) {
+	koolStart := newFakeWaitKoolStart()
+
+	cmd := NewStartCommand(koolStart)
+	cmd.SetArgs([]string{"app"})
+
+	if _, err := execStartCommand(cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	if koolStart.pull.(*builder.FakeCommand).CalledInteractive {
+		t.Error("unexpected images pulling without --pull")
+	}
+
+	if expected := []string{"app"}; !startedServicesAreEqual(startedServices, expected) {
+		t.Errorf("expecting docker-compose up arguments %v, got %v", expected, startedServices)
+	}
+}
+
+func TestNewKoolStart(t *testing.T) {
+	k := NewKoolStart()
+
+	if k.Flags == nil || k.Flags.Recreate || k.Flags.Build || k.Flags.Pull || k.Flags.Wait {
+		t.Errorf("unexpected default flags on KoolStart instance")
+	}
+
+	if command := k.start.(*builder.DefaultCommand).String(); command != "docker-compose up -d" {
+		t.Errorf("unexpected start command %s on KoolStart instance", command)
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"kool-dev/kool/cmd/builder"
+	"net"
+	"strings"
+	"time"
+)
+
+// startWaitInterval is the time between the checks of a service readiness
+var startWaitInterval = time.Second
+
+// serviceReadiness checks whether the project services are ready
+type serviceReadiness struct {
+	id     builder.Runner
+	status builder.Runner
+	ports  builder.Runner
+	dial   func(address string) error
+}
+
+func newServiceReadiness() *serviceReadiness {
+	return &serviceReadiness{
+		builder.NewCommand("docker-compose", "ps", "-q"),
+		builder.NewCommand("docker", "inspect", "--format", "{{if .State.Health}}{{.State.Health.Status}}{{else}}{{.State.Status}}{{end}}"),
+		builder.NewCommand("docker", "inspect", "--format", "{{range $p, $conf := .NetworkSettings.Ports}}{{range $conf}}{{.HostPort}} {{end}}{{end}}"),
+		dialTCP,
+	}
+}
+
+func dialTCP(address string) (err error) {
+	var conn net.Conn
+
+	if conn, err = net.DialTimeout("tcp", address, time.Second); err == nil {
+		conn.Close()
+	}
+
+	return
+}
+
+// check tells whether the service is ready; that is all of its containers
+// healthy through their healthcheck or, lacking one, running and accepting
+// connections on their published ports.
+func (r *serviceReadiness) check(service string) (err error) {
+	var ids string
+
+	if ids, err = r.id.Exec(service); err != nil {
+		return
+	}
+
+	containers := strings.Fields(ids)
+
+	if len(containers) == 0 {
+		err = fmt.Errorf("service %s is not running", service)
+		return
+	}
+
+	for _, id := range containers {
+		if err = r.checkContainer(service, id); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// checkContainer tells whether the service container is ready
+func (r *serviceReadiness) checkContainer(service string, id string) (err error) {
+	var status, ports string
+
+	if status, err = r.status.Exec(id); err != nil {
+		return
+	}
+
+	switch status = strings.TrimSpace(status); status {
+	case "healthy":
+		return
+	case "running":
+		if ports, err = r.ports.Exec(id); err != nil {
+			return
+		}
+
+		for _, port := range strings.Fields(ports) {
+			if r.dial(net.JoinHostPort("127.0.0.1", port)) != nil {
+				err = fmt.Errorf("service %s is not accepting connections on port %s", service, port)
+				return
+			}
+		}
+	default:
+		err = fmt.Errorf("service %s is not ready (%s)", service, status)
+	}
+
+	return
+}
+
+// serviceWait waits for a service to be ready as the service of a KoolTask
+type serviceWait struct {
+	DefaultKoolService
+	service   string
+	deadline  time.Time
+	readiness *serviceReadiness
+}
+
+// Execute checks the service until it's ready or the deadline is reached
+func (w *serviceWait) Execute(args []string) (err error) {
+	for {
+		if err = w.readiness.check(w.service); err == nil || !time.Now().Before(w.deadline) {
+			return
+		}
+
+		time.Sleep(startWaitInterval)
+	}
+}
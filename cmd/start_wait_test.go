@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"kool-dev/kool/cmd/builder"
+	"testing"
+	"time"
+)
+
+func newFakeServiceReadiness() *serviceReadiness {
+	return &serviceReadiness{
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		func(string) error { return nil },
+	}
+}
+
+func TestServiceReadinessCheck(t *testing.T) {
+	tests := []struct {
+		id, status, ports string
+		dialErr           error
+		err               string
+	}{
+		{"id", "healthy", "", nil, ""},
+		{"id", "running", "", nil, ""},
+		{"id", "running", "3306 3306 ", nil, ""},
+		{"id", "running", "3306 ", errors.New("refused"), "service database is not accepting connections on port 3306"},
+		{"", "", "", nil, "service database is not running"},
+		{"id\n", "starting\n", "", nil, "service database is not ready (starting)"},
+		{"id", "unhealthy", "", nil, "service database is not ready (unhealthy)"},
+	}
+
+	for _, test := range tests {
+		var dialed []string
+
+		r := newFakeServiceReadiness()
+		r.id.(*builder.FakeCommand).MockExecOut = test.id
+		r.status.(*builder.FakeCommand).MockExecOut = test.status
+		r.ports.(*builder.FakeCommand).MockExecOut = test.ports
+		r.dial = func(address string) error {
+			dialed = append(dialed, address)
+			return test.dialErr
+		}
+
+		err := r.check("database")
+
+		if test.err == "" && err != nil {
+			t.Errorf("unexpected error checking %s service: %v", test.status, err)
+		} else if test.err != "" && (err == nil || err.Error() != test.err) {
+			t.Errorf("expecting error '%s', got '%v'", test.err, err)
+		}
+
+		if test.ports != "" && (len(dialed) == 0 || dialed[0] != "127.0.0.1:3306") {
+			t.Errorf("expecting to dial the service port, got %v", dialed)
+		}
+	}
+}
+
+// fakeContainersRunner outputs the mocked value for each container id
+type fakeContainersRunner struct {
+	builder.FakeCommand
+	outputs map[string]string
+}
+
+func (f *fakeContainersRunner) Exec(args ...string) (string, error) {
+	f.ArgsExec = append(f.ArgsExec, args...)
+	return f.outputs[args[0]], nil
+}
+
+func TestServiceReadinessCheckScaledService(t *testing.T) {
+	status := &fakeContainersRunner{outputs: map[string]string{"id1": "healthy", "id2": "starting"}}
+
+	r := newFakeServiceReadiness()
+	r.id.(*builder.FakeCommand).MockExecOut = "id1\nid2\n"
+	r.status = status
+
+	if err := r.check("app"); err == nil || err.Error() != "service app is not ready (starting)" {
+		t.Errorf("expecting the second container not to be ready, got %v", err)
+	}
+
+	status.ArgsExec = nil
+	status.outputs["id2"] = "healthy"
+
+	if err := r.check("app"); err != nil {
+		t.Errorf("unexpected error checking the ready containers: %v", err)
+	}
+
+	if len(status.ArgsExec) != 2 || status.ArgsExec[0] != "id1" || status.ArgsExec[1] != "id2" {
+		t.Errorf("expecting to check every container, checked %v", status.ArgsExec)
+	}
+}
+
+func TestServiceWait(t *testing.T) {
+	startWaitInterval = 0
+
+	w := &serviceWait{*newFakeKoolService(), "database", time.Now().Add(time.Millisecond), newFakeServiceReadiness()}
+	w.readiness.id.(*builder.FakeCommand).MockExecOut = "id"
+	w.readiness.status.(*builder.FakeCommand).MockExecOut = "starting"
+
+	if err := w.Execute(nil); err == nil || err.Error() != "service database is not ready (starting)" {
+		t.Errorf("expecting not ready error after the deadline, got %v", err)
+	}
+
+	w.readiness.status.(*builder.FakeCommand).MockExecOut = "healthy"
+
+	if err := w.Execute(nil); err != nil {
+		t.Errorf("unexpected error waiting for healthy service: %v", err)
+	}
+}
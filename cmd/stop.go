@@ -3,6 +3,8 @@ package cmd
 import (
 	"kool-dev/kool/cmd/builder"
 	"kool-dev/kool/cmd/checker"
+	"kool-dev/kool/cmd/compose"
+	"kool-dev/kool/environment"
 
 	"github.com/spf13/cobra"
 )
@@ -17,8 +19,12 @@ type KoolStop struct {
 	DefaultKoolService
 	Flags *KoolStopFlags
 
-	check  checker.Checker
-	doStop builder.Command
+	check         checker.Checker
+	doStop        builder.Command
+	stopServices  builder.Command
+	rmServices    builder.Command
+	envStorage    environment.EnvStorage
+	composeParser compose.Parser
 }
 
 func init() {
@@ -37,6 +43,10 @@ func NewKoolStop() *KoolStop {
 		&KoolStopFlags{false},
 		checker.NewChecker(),
 		builder.NewCommand("docker-compose", "down"),
+		builder.NewCommand("docker-compose", "stop"),
+		builder.NewCommand("docker-compose", "rm", "-f"),
+		environment.NewEnvStorage(),
+		compose.NewParser(),
 	}
 }
 
@@ -46,6 +56,11 @@ func (s *KoolStop) Execute(args []string) (err error) {
 		return
 	}
 
+	if len(args) > 0 {
+		err = s.stopOnly(args)
+		return
+	}
+
 	if s.Flags.Purge {
 		s.doStop.AppendArgs("--volumes", "--remove-orphans")
 	}
@@ -54,15 +69,33 @@ func (s *KoolStop) Execute(args []string) (err error) {
 	return
 }
 
+// stopOnly stops and removes the containers of the given services,
+// leaving the rest of the project and its network running.
+func (s *KoolStop) stopOnly(services []string) (err error) {
+	if err = s.stopServices.Interactive(services...); err != nil {
+		return
+	}
+
+	if s.Flags.Purge {
+		s.rmServices.AppendArgs("-v")
+	}
+
+	err = s.rmServices.Interactive(services...)
+	return
+}
+
 // NewStopCommand initializes new kool stop command
 func NewStopCommand(stop *KoolStop) (stopCmd *cobra.Command) {
 	stopCmd = &cobra.Command{
-		Use:   "stop",
+		Use:   "stop [SERVICE...]",
 		Short: "Stop all running containers started with 'kool start' command",
-		Args:  cobra.NoArgs,
-		Run:   DefaultCommandRunFunction(stop),
+		Long: `Stop all running containers started with 'kool start' command, tearing down
+the project network. When services are given, only their containers are stopped
+and removed, leaving the rest of the project running.`,
+		Run:               DefaultCommandRunFunction(stop),
+		ValidArgsFunction: completeServices(stop.envStorage, stop.composeParser),
 	}
 
-	stopCmd.Flags().BoolVarP(&stop.Flags.Purge, "purge", "", false, "Remove all persistent data from volume mounts on containers")
+	stopCmd.Flags().BoolVarP(&stop.Flags.Purge, "purge", "", false, "Remove all persistent data from volume mounts on containers; for given services, their anonymous volumes")
 	return
 }
@@ -4,7 +4,10 @@ import (
 	"errors"
 	"kool-dev/kool/cmd/builder"
 	"kool-dev/kool/cmd/checker"
+	"kool-dev/kool/cmd/compose"
 	"kool-dev/kool/cmd/shell"
+	"kool-dev/kool/environment"
+	"reflect"
 	"testing"
 )
 
@@ -14,6 +17,10 @@ func newFakeKoolStop() *KoolStop {
 		&KoolStopFlags{false},
 		&checker.FakeChecker{},
 		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		environment.NewFakeEnvStorage(),
+		&compose.FakeParser{},
 	}
 }
 
@@ -91,6 +98,51 @@ func TestNewStopPurgeCommand(t *testing.T) {
 	}
 }
 
+func TestStopServicesCommand(t *testing.T) {
+	f := newFakeKoolStop()
+	cmd := NewStopCommand(f)
+
+	cmd.SetArgs([]string{"--purge", "worker", "queue"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing stop command with args; error: %v", err)
+	}
+
+	if f.doStop.(*builder.FakeCommand).CalledInteractive {
+		t.Error("unexpected docker-compose down when stopping services")
+	}
+
+	if args := f.stopServices.(*builder.FakeCommand).ArgsInteractive; !reflect.DeepEqual(args, []string{"worker", "queue"}) {
+		t.Errorf("expecting to stop the worker and queue services, got %v", args)
+	}
+
+	if args := f.rmServices.(*builder.FakeCommand).ArgsInteractive; !reflect.DeepEqual(args, []string{"worker", "queue"}) {
+		t.Errorf("expecting to remove the worker and queue containers, got %v", args)
+	}
+
+	if args := f.rmServices.(*builder.FakeCommand).ArgsAppend; !reflect.DeepEqual(args, []string{"-v"}) {
+		t.Errorf("expecting to remove the services anonymous volumes on --purge, got %v", args)
+	}
+}
+
+func TestFailingStopServicesCommand(t *testing.T) {
+	f := newFakeKoolStop()
+	f.stopServices.(*builder.FakeCommand).MockError = errors.New("stop error")
+	cmd := NewStopCommand(f)
+
+	cmd.SetArgs([]string{"worker"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing stop command with args; error: %v", err)
+	}
+
+	if err := f.out.(*shell.FakeOutputWriter).Err; err == nil || err.Error() != "stop error" {
+		t.Errorf("expecting error 'stop error', got %v", err)
+	}
+
+	if f.rmServices.(*builder.FakeCommand).CalledInteractive {
+		t.Error("unexpected containers removal after failing to stop them")
+	}
+}
+
 func TestNewFailingDependenciesCheckStopCommand(t *testing.T) {
 	f := newFakeKoolStop()
 
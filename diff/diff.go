@@ -0,0 +1,123 @@
+package diff
+
+import (
+	"strings"
+)
+
+// Op is the kind of a line edit
+type Op int
+
+const (
+	// Equal line is kept from a to b
+	Equal Op = iota
+	// Delete line is in a but not in b
+	Delete
+	// Insert line is in b but not in a
+	Insert
+)
+
+// Edit is a single line edit turning a into b
+type Edit struct {
+	Op   Op
+	Line string
+}
+
+// SplitLines splits the text into lines keeping their line
+// endings, so joining the lines results in the original text.
+func SplitLines(text string) (lines []string) {
+	for text != "" {
+		i := strings.IndexByte(text, '\n')
+
+		if i == -1 {
+			lines = append(lines, text)
+			break
+		}
+
+		lines = append(lines, text[:i+1])
+		text = text[i+1:]
+	}
+
+	return
+}
+
+// Lines computes the edits turning the lines of a into the
+// lines of b, based on their longest common subsequence.
+func Lines(a, b []string) (edits []Edit) {
+	var (
+		i, j    int
+		lengths = lcsLengths(a, b)
+	)
+
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, Edit{Equal, a[i]})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			edits = append(edits, Edit{Delete, a[i]})
+			i++
+		default:
+			edits = append(edits, Edit{Insert, b[j]})
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		edits = append(edits, Edit{Delete, a[i]})
+	}
+
+	for ; j < len(b); j++ {
+		edits = append(edits, Edit{Insert, b[j]})
+	}
+
+	return
+}
+
+// matches maps each line of a to its matching line of b in
+// their longest common subsequence, or -1 when not matched.
+func matches(a, b []string) (matched []int) {
+	var i, j int
+
+	matched = make([]int, len(a))
+
+	for _, edit := range Lines(a, b) {
+		switch edit.Op {
+		case Equal:
+			matched[i] = j
+			i++
+			j++
+		case Delete:
+			matched[i] = -1
+			i++
+		case Insert:
+			j++
+		}
+	}
+
+	return
+}
+
+// lcsLengths computes the table of longest common subsequence
+// lengths of every pair of a and b suffixes.
+func lcsLengths(a, b []string) (lengths [][]int) {
+	lengths = make([][]int, len(a)+1)
+
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,102 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitLines(t *testing.T) {
+	cases := map[string][]string{
+		"":             nil,
+		"a":            {"a"},
+		"a\n":          {"a\n"},
+		"a\nb":         {"a\n", "b"},
+		"a\n\nb\n":     {"a\n", "\n", "b\n"},
+		"a\r\nb\r\n\n": {"a\r\n", "b\r\n", "\n"},
+	}
+
+	for text, expected := range cases {
+		lines := SplitLines(text)
+
+		if !reflect.DeepEqual(lines, expected) {
+			t.Errorf("expected %q lines for %q; got %q", expected, text, lines)
+		}
+
+		if joined := strings.Join(lines, ""); joined != text {
+			t.Errorf("joined lines %q differ from %q", joined, text)
+		}
+	}
+}
+
+func TestLines(t *testing.T) {
+	edits := Lines([]string{"a", "b", "c"}, []string{"a", "c", "d"})
+
+	expected := []Edit{{Equal, "a"}, {Delete, "b"}, {Equal, "c"}, {Insert, "d"}}
+
+	if !reflect.DeepEqual(edits, expected) {
+		t.Errorf("expected edits %v; got %v", expected, edits)
+	}
+
+	if edits = Lines(nil, []string{"a"}); !reflect.DeepEqual(edits, []Edit{{Insert, "a"}}) {
+		t.Errorf("unexpected edits from empty text: %v", edits)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+
+	expected := `--- a/file
++++ b/file
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+
+	if out := Unified("a/file", "b/file", a, b, 3); out != expected {
+		t.Errorf("unexpected unified diff:\n%s\nexpected:\n%s", out, expected)
+	}
+
+	if out := Unified("a/file", "b/file", a, a, 3); out != "" {
+		t.Errorf("expected no diff for equal texts; got:\n%s", out)
+	}
+}
+
+func TestUnifiedMergedHunksAndNoNewline(t *testing.T) {
+	expected := `--- a
++++ b
+@@ -1,3 +1,3 @@
+-1
++one
+ 2
+-3
+\ No newline at end of file
++3
+`
+
+	if out := Unified("a", "b", "1\n2\n3", "one\n2\n3\n", 3); out != expected {
+		t.Errorf("unexpected unified diff:\n%s\nexpected:\n%s", out, expected)
+	}
+
+	expected = `--- a
++++ b
+@@ -0,0 +1,2 @@
++1
++2
+`
+
+	if out := Unified("a", "b", "", "1\n2\n", 3); out != expected {
+		t.Errorf("unexpected unified diff:\n%s\nexpected:\n%s", out, expected)
+	}
+}
@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"strings"
+)
+
+// Merge does a three-way merge of the local and other texts, which
+// were both changed from base. Changes made to only one of them are
+// taken; when both changed the same lines differently, the lines are
+// enclosed in conflict markers labeled after localName and otherName,
+// and the number of conflicts is returned.
+func Merge(base, local, other, localName, otherName string) (merged string, conflicts int) {
+	var (
+		out                   strings.Builder
+		baseLines             = SplitLines(base)
+		localLines            = SplitLines(local)
+		otherLines            = SplitLines(other)
+		localMatches          = matches(baseLines, localLines)
+		otherMatches          = matches(baseLines, otherLines)
+		i, iLocal, iOther, j  int
+		nextLocal, nextOther  int
+		baseChunk, localChunk []string
+		otherChunk            []string
+	)
+
+	for i < len(baseLines) || iLocal < len(localLines) || iOther < len(otherLines) {
+		// stable line, unchanged on both sides
+		if i < len(baseLines) && localMatches[i] == iLocal && otherMatches[i] == iOther {
+			out.WriteString(baseLines[i])
+			i, iLocal, iOther = i+1, iLocal+1, iOther+1
+			continue
+		}
+
+		// the unstable chunk goes up to the next base line kept on both sides
+		for j = i; j < len(baseLines) && (localMatches[j] == -1 || otherMatches[j] == -1); {
+			j++
+		}
+
+		nextLocal, nextOther = len(localLines), len(otherLines)
+
+		if j < len(baseLines) {
+			nextLocal, nextOther = localMatches[j], otherMatches[j]
+		}
+
+		baseChunk = baseLines[i:j]
+		localChunk = localLines[iLocal:nextLocal]
+		otherChunk = otherLines[iOther:nextOther]
+
+		switch {
+		case equalLines(localChunk, baseChunk):
+			writeLines(&out, otherChunk)
+		case equalLines(otherChunk, baseChunk), equalLines(localChunk, otherChunk):
+			writeLines(&out, localChunk)
+		default:
+			conflicts++
+			writeConflictLine(&out, "<<<<<<< "+localName)
+			writeConflictLines(&out, localChunk)
+			writeConflictLine(&out, "=======")
+			writeConflictLines(&out, otherChunk)
+			writeConflictLine(&out, ">>>>>>> "+otherName)
+		}
+
+		i, iLocal, iOther = j, nextLocal, nextOther
+	}
+
+	merged = out.String()
+	return
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+	}
+}
+
+func writeConflictLines(out *strings.Builder, lines []string) {
+	for _, line := range lines {
+		writeConflictLine(out, strings.TrimSuffix(line, "\n"))
+	}
+}
+
+func writeConflictLine(out *strings.Builder, line string) {
+	out.WriteString(line + "\n")
+}
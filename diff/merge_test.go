@@ -0,0 +1,64 @@
+package diff
+
+import (
+	"testing"
+)
+
+func TestMergeCleanly(t *testing.T) {
+	base := "services:\n  app:\n    image: php:7.4\n  cache:\n    image: redis:5\n"
+	local := "services:\n  app:\n    image: php:7.4\n    ports: [80]\n  cache:\n    image: redis:5\n"
+	other := "services:\n  app:\n    image: php:7.4\n  cache:\n    image: redis:6\n"
+
+	merged, conflicts := Merge(base, local, other, "local", "preset")
+
+	expected := "services:\n  app:\n    image: php:7.4\n    ports: [80]\n  cache:\n    image: redis:6\n"
+
+	if conflicts != 0 || merged != expected {
+		t.Errorf("unexpected merge with %d conflicts:\n%s", conflicts, merged)
+	}
+}
+
+func TestMergeSameChanges(t *testing.T) {
+	base := "a\nb\nc\n"
+	changed := "a\nB\nc\nd\n"
+
+	if merged, conflicts := Merge(base, changed, changed, "local", "preset"); conflicts != 0 || merged != changed {
+		t.Errorf("unexpected merge with %d conflicts:\n%s", conflicts, merged)
+	}
+
+	if merged, conflicts := Merge(base, base, changed, "local", "preset"); conflicts != 0 || merged != changed {
+		t.Errorf("unexpected merge with %d conflicts:\n%s", conflicts, merged)
+	}
+
+	if merged, conflicts := Merge(base, changed, base, "local", "preset"); conflicts != 0 || merged != changed {
+		t.Errorf("unexpected merge with %d conflicts:\n%s", conflicts, merged)
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	base := "a\nimage: php:7.4\nc"
+	local := "a\nimage: php:7.4-custom\nc"
+	other := "a\nimage: php:8.0\nc"
+
+	merged, conflicts := Merge(base, local, other, "local", "preset")
+
+	expected := "a\n<<<<<<< local\nimage: php:7.4-custom\n=======\nimage: php:8.0\n>>>>>>> preset\nc"
+
+	if conflicts != 1 || merged != expected {
+		t.Errorf("unexpected merge with %d conflicts:\n%s", conflicts, merged)
+	}
+}
+
+func TestMergeInsertionsAndDeletions(t *testing.T) {
+	base := "1\n2\n3\n4\n"
+	local := "0\n1\n2\n3\n4\n"
+	other := "1\n3\n4\n5\n"
+
+	if merged, conflicts := Merge(base, local, other, "local", "preset"); conflicts != 0 || merged != "0\n1\n3\n4\n5\n" {
+		t.Errorf("unexpected merge with %d conflicts:\n%s", conflicts, merged)
+	}
+
+	if merged, conflicts := Merge("", "a\n", "b\n", "local", "preset"); conflicts != 1 || merged != "<<<<<<< local\na\n=======\nb\n>>>>>>> preset\n" {
+		t.Errorf("unexpected merge with %d conflicts:\n%s", conflicts, merged)
+	}
+}
@@ -0,0 +1,106 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unified renders the differences between the a and b texts in the
+// unified format, with the given number of context lines around
+// changes. It returns an empty string when both texts are equal.
+func Unified(fromName, toName, a, b string, context int) string {
+	var (
+		out   strings.Builder
+		edits = Lines(SplitLines(a), SplitLines(b))
+	)
+
+	for start := 0; start < len(edits); {
+		var (
+			first, last         = start, -1
+			aStart, bStart      int
+			aCount, bCount, end int
+		)
+
+		for first < len(edits) && edits[first].Op == Equal {
+			first++
+		}
+
+		if first == len(edits) {
+			break
+		}
+
+		// changes closer than twice the context go into the same hunk
+		for i, equals := first, 0; i < len(edits) && equals <= 2*context; i++ {
+			if edits[i].Op == Equal {
+				equals++
+			} else {
+				last, equals = i, 0
+			}
+		}
+
+		if start = first - context; start < 0 {
+			start = 0
+		}
+
+		if end = last + 1 + context; end > len(edits) {
+			end = len(edits)
+		}
+
+		for _, edit := range edits[:start] {
+			if edit.Op != Insert {
+				aStart++
+			}
+			if edit.Op != Delete {
+				bStart++
+			}
+		}
+
+		for _, edit := range edits[start:end] {
+			if edit.Op != Insert {
+				aCount++
+			}
+			if edit.Op != Delete {
+				bCount++
+			}
+		}
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+		}
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+
+		for _, edit := range edits[start:end] {
+			prefix := " "
+
+			switch edit.Op {
+			case Delete:
+				prefix = "-"
+			case Insert:
+				prefix = "+"
+			}
+
+			out.WriteString(prefix + edit.Line)
+
+			if !strings.HasSuffix(edit.Line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		start = end
+	}
+
+	return out.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
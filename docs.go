@@ -13,6 +13,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
 )
 
@@ -20,7 +21,6 @@ func main() {
 	var (
 		err          error
 		koolOutput   *bytes.Buffer
-		cmdFile      *os.File
 		koolFile     *os.File
 		outputWriter shell.OutputWriter
 	)
@@ -49,23 +49,7 @@ func main() {
 		newName := strings.Replace(childCmd.CommandPath(), " ", "-", -1)
 		koolMarkdown = strings.Replace(koolMarkdown, cmdName, newName, -1)
 
-		cmdOutput := new(bytes.Buffer)
-
-		err = doc.GenMarkdown(childCmd, cmdOutput)
-
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		cmdFile, err = CreateFile(newName, "docs/4-Commands")
-
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		defer cmdFile.Close()
-
-		_, err = cmdOutput.WriteTo(cmdFile)
+		err = GenCommandDocs(childCmd, "docs/4-Commands")
 
 		if err != nil {
 			log.Fatal(err)
@@ -95,6 +79,50 @@ func main() {
 	outputWriter.Success("Success!")
 }
 
+// GenCommandDocs writes the markdown docs of the command along with its
+// sub commands ones, linking them by their docs file names
+func GenCommandDocs(command *cobra.Command, dir string) (err error) {
+	var (
+		cmdFile   *os.File
+		cmdOutput = new(bytes.Buffer)
+	)
+
+	if err = doc.GenMarkdown(command, cmdOutput); err != nil {
+		return
+	}
+
+	cmdMarkdown := cmdOutput.String()
+
+	if parent := command.Parent(); parent != nil && parent.HasParent() {
+		cmdMarkdown = strings.Replace(cmdMarkdown, docsName(parent, "_"), docsName(parent, "-"), -1)
+	}
+
+	for _, childCmd := range command.Commands() {
+		if !childCmd.IsAvailableCommand() || childCmd.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+
+		cmdMarkdown = strings.Replace(cmdMarkdown, docsName(childCmd, "_"), docsName(childCmd, "-"), -1)
+
+		if err = GenCommandDocs(childCmd, dir); err != nil {
+			return
+		}
+	}
+
+	if cmdFile, err = CreateFile(docsName(command, "-"), dir); err != nil {
+		return
+	}
+
+	defer cmdFile.Close()
+
+	_, err = cmdFile.WriteString(cmdMarkdown)
+	return
+}
+
+func docsName(command *cobra.Command, separator string) string {
+	return strings.Replace(command.CommandPath(), " ", separator, -1)
+}
+
 // CreateFile Create file to write markdown content
 func CreateFile(filename string, dir string) (file *os.File, err error) {
 	basename := fmt.Sprintf("%s.md", filename)
@@ -0,0 +1,114 @@
+package git
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// excludesFile gets the global ignore file, as set by core.excludesFile
+// on the repository or the user git config, falling back to the
+// git default of $XDG_CONFIG_HOME/git/ignore.
+func (r *Repository) excludesFile() string {
+	var (
+		home, _    = os.UserHomeDir()
+		xdgConfig  = os.Getenv("XDG_CONFIG_HOME")
+		configured string
+	)
+
+	if xdgConfig == "" && home != "" {
+		xdgConfig = filepath.Join(home, ".config")
+	}
+
+	// later files take precedence, as git reads them in this order
+	for _, file := range []string{
+		filepath.Join(xdgConfig, "git", "config"),
+		filepath.Join(home, ".gitconfig"),
+		filepath.Join(r.gitDir, "config"),
+	} {
+		if value, found := readConfigValue(file, "core", "excludesfile"); found {
+			configured = value
+		}
+	}
+
+	switch {
+	case configured == "~" || strings.HasPrefix(configured, "~/"):
+		return filepath.Join(home, configured[1:])
+	case configured != "" && !filepath.IsAbs(configured):
+		return filepath.Join(r.workTree, configured)
+	case configured != "":
+		return configured
+	case xdgConfig != "":
+		return filepath.Join(xdgConfig, "git", "ignore")
+	}
+
+	return ""
+}
+
+// readConfigValue reads the last value of the given key within the
+// section of a git config file; section and key are case insensitive.
+func readConfigValue(filename string, section string, key string) (value string, found bool) {
+	var (
+		file    *os.File
+		current string
+		err     error
+	)
+
+	if file, err = os.Open(filename); err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			current = ""
+
+			if fields := strings.Fields(strings.Trim(line, "[]")); len(fields) > 0 {
+				current = strings.ToLower(fields[0])
+			}
+
+			continue
+		}
+
+		pair := strings.SplitN(line, "=", 2)
+
+		if current != section || len(pair) != 2 || strings.ToLower(strings.TrimSpace(pair[0])) != key {
+			continue
+		}
+
+		value, found = parseConfigValue(pair[1]), true
+	}
+
+	return
+}
+
+// parseConfigValue drops the comments and quotes of a config value
+func parseConfigValue(raw string) string {
+	var (
+		value  strings.Builder
+		quoted bool
+	)
+
+	for _, char := range strings.TrimSpace(raw) {
+		switch {
+		case char == '"':
+			quoted = !quoted
+		case !quoted && (char == '#' || char == ';'):
+			return strings.TrimSpace(value.String())
+		default:
+			value.WriteRune(char)
+		}
+	}
+
+	return strings.TrimSpace(value.String())
+}
@@ -0,0 +1,242 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	indexSignature     string = "DIRC"
+	indexEntryFixedLen int    = 62
+
+	flagExtended  uint16 = 0x4000
+	flagStageMask uint16 = 0x3000
+	flagNameMask  uint16 = 0x0fff
+
+	extFlagSkipWorktree uint16 = 0x4000
+	extFlagIntentToAdd  uint16 = 0x2000
+
+	modeTypeMask uint32 = 0170000
+	modeGitlink  uint32 = 0160000
+	modeDir      uint32 = 0040000
+)
+
+// ErrInvalidIndex is returned when the index file is not in a known format
+var ErrInvalidIndex = errors.New("invalid git index file")
+
+// ErrSplitIndex is returned when the repository uses a split index, which is not supported
+var ErrSplitIndex = errors.New("git split index is not supported")
+
+// IndexEntry holds the data of a single path tracked in the git index
+type IndexEntry struct {
+	Path         string
+	Mode         uint32
+	Stage        int
+	SkipWorktree bool
+	IntentToAdd  bool
+}
+
+// IsSubmodule tells whether the entry is a submodule (gitlink)
+func (e *IndexEntry) IsSubmodule() bool {
+	return e.Mode&modeTypeMask == modeGitlink
+}
+
+// IsSparseDir tells whether the entry is a sparse directory
+// from a sparse index, standing for a whole folder out of the
+// sparse checkout cone.
+func (e *IndexEntry) IsSparseDir() bool {
+	return e.Mode&modeTypeMask == modeDir
+}
+
+// ReadIndex parses the git index (versions 2, 3 and 4) from the given reader
+func ReadIndex(r io.Reader) (entries []*IndexEntry, err error) {
+	var (
+		header  [12]byte
+		version uint32
+		count   uint32
+		reader  = bufio.NewReader(r)
+		prev    string
+	)
+
+	if _, err = io.ReadFull(reader, header[:]); err != nil {
+		err = ErrInvalidIndex
+		return
+	}
+
+	if string(header[:4]) != indexSignature {
+		err = ErrInvalidIndex
+		return
+	}
+
+	version = binary.BigEndian.Uint32(header[4:8])
+	count = binary.BigEndian.Uint32(header[8:12])
+
+	if version < 2 || version > 4 {
+		err = fmt.Errorf("%v: unsupported version %d", ErrInvalidIndex, version)
+		return
+	}
+
+	entries = make([]*IndexEntry, 0, count)
+
+	for i := uint32(0); i < count; i++ {
+		var entry *IndexEntry
+
+		if entry, err = readIndexEntry(reader, version, prev); err != nil {
+			entries = nil
+			return
+		}
+
+		prev = entry.Path
+		entries = append(entries, entry)
+	}
+
+	err = checkIndexExtensions(reader)
+	return
+}
+
+func readIndexEntry(reader *bufio.Reader, version uint32, prev string) (entry *IndexEntry, err error) {
+	var (
+		fixed   [indexEntryFixedLen]byte
+		flags   uint16
+		read    = indexEntryFixedLen
+		nameLen int
+		name    []byte
+	)
+
+	if _, err = io.ReadFull(reader, fixed[:]); err != nil {
+		err = ErrInvalidIndex
+		return
+	}
+
+	entry = new(IndexEntry)
+	entry.Mode = binary.BigEndian.Uint32(fixed[24:28])
+	flags = binary.BigEndian.Uint16(fixed[60:62])
+	entry.Stage = int((flags & flagStageMask) >> 12)
+	nameLen = int(flags & flagNameMask)
+
+	if version >= 3 && flags&flagExtended != 0 {
+		var extended [2]byte
+
+		if _, err = io.ReadFull(reader, extended[:]); err != nil {
+			err = ErrInvalidIndex
+			return
+		}
+
+		read += 2
+		extFlags := binary.BigEndian.Uint16(extended[:])
+		entry.SkipWorktree = extFlags&extFlagSkipWorktree != 0
+		entry.IntentToAdd = extFlags&extFlagIntentToAdd != 0
+	}
+
+	if version == 4 {
+		var strip int
+
+		if strip, err = readOffsetVarint(reader); err != nil || strip > len(prev) {
+			err = ErrInvalidIndex
+			return
+		}
+
+		if name, err = reader.ReadBytes(0); err != nil {
+			err = ErrInvalidIndex
+			return
+		}
+
+		entry.Path = prev[:len(prev)-strip] + string(name[:len(name)-1])
+		return
+	}
+
+	if nameLen < int(flagNameMask) {
+		name = make([]byte, nameLen+1)
+
+		if _, err = io.ReadFull(reader, name); err != nil || name[nameLen] != 0 {
+			err = ErrInvalidIndex
+			return
+		}
+	} else if name, err = reader.ReadBytes(0); err != nil {
+		err = ErrInvalidIndex
+		return
+	}
+
+	read += len(name)
+	entry.Path = string(name[:len(name)-1])
+
+	// entries are padded with 1-8 nul bytes to a multiple of eight
+	// bytes, and the name terminator is part of that padding
+	if padding := (8 - read%8) % 8; padding > 0 {
+		if _, err = reader.Discard(padding); err != nil {
+			err = ErrInvalidIndex
+			return
+		}
+	}
+
+	return
+}
+
+// readOffsetVarint reads the variable length integer
+// used by index version 4 for path prefix compression.
+func readOffsetVarint(reader *bufio.Reader) (value int, err error) {
+	var b byte
+
+	if b, err = reader.ReadByte(); err != nil {
+		return
+	}
+
+	value = int(b & 0x7f)
+
+	for b&0x80 != 0 {
+		if b, err = reader.ReadByte(); err != nil {
+			return
+		}
+
+		value = ((value + 1) << 7) | int(b&0x7f)
+	}
+
+	return
+}
+
+func checkIndexExtensions(reader *bufio.Reader) (err error) {
+	var header [8]byte
+
+	for {
+		if _, err = io.ReadFull(reader, header[:]); err != nil {
+			// the trailing checksum is shorter than an extension
+			// header, so reaching the end here is expected
+			err = nil
+			return
+		}
+
+		if bytes.Equal(header[:4], []byte("link")) {
+			err = ErrSplitIndex
+			return
+		}
+
+		if _, err = reader.Discard(int(binary.BigEndian.Uint32(header[4:8]))); err != nil {
+			// not an extension but the trailing checksum
+			err = nil
+			return
+		}
+	}
+}
+
+// ReadIndexFile parses the git index from the given file path. A
+// missing index file means an empty index.
+func ReadIndexFile(filename string) (entries []*IndexEntry, err error) {
+	var file *os.File
+
+	if file, err = os.Open(filename); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+
+	defer file.Close()
+
+	entries, err = ReadIndex(file)
+	return
+}
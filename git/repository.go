@@ -0,0 +1,214 @@
+package git
+
+import (
+	"errors"
+	"io/ioutil"
+	"kool-dev/kool/ignore"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrNotRepository is returned when the given folder is not the root of a git working tree
+var ErrNotRepository = errors.New("not a git repository")
+
+// Repository represents a git working tree along with its git folder
+type Repository struct {
+	workTree string
+	gitDir   string
+}
+
+// Open opens the git repository whose working tree root is
+// the given folder. The .git entry might be either the git
+// folder itself or a file pointing to it, like submodules
+// and worktrees do.
+func Open(dir string) (repo *Repository, err error) {
+	var (
+		fi      os.FileInfo
+		dotGit  = filepath.Join(dir, ".git")
+		content []byte
+	)
+
+	if fi, err = os.Stat(dotGit); err != nil {
+		if os.IsNotExist(err) {
+			err = ErrNotRepository
+		}
+		return
+	}
+
+	repo = &Repository{workTree: dir, gitDir: dotGit}
+
+	if fi.IsDir() {
+		return
+	}
+
+	if content, err = ioutil.ReadFile(dotGit); err != nil {
+		repo = nil
+		return
+	}
+
+	gitDir := strings.TrimSpace(string(content))
+
+	if !strings.HasPrefix(gitDir, "gitdir:") {
+		repo = nil
+		err = ErrNotRepository
+		return
+	}
+
+	gitDir = filepath.FromSlash(strings.TrimSpace(strings.TrimPrefix(gitDir, "gitdir:")))
+
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+
+	repo.gitDir = gitDir
+	return
+}
+
+// Index reads the entries tracked in the repository index
+func (r *Repository) Index() ([]*IndexEntry, error) {
+	return ReadIndexFile(filepath.Join(r.gitDir, "index"))
+}
+
+// ListFiles returns the files that would be listed by both
+// `git ls-files --cached` and `git ls-files --others --exclude-standard`
+// except the ones deleted from the working tree. Files within
+// initialized submodules are listed as well. Paths are relative
+// to the working tree root, slash separated and sorted.
+func (r *Repository) ListFiles() (files []string, err error) {
+	var (
+		entries []*IndexEntry
+		tracked = make(map[string]bool)
+	)
+
+	if entries, err = r.Index(); err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if tracked[entry.Path] {
+			// conflicting entries show up once per stage
+			continue
+		}
+
+		tracked[entry.Path] = true
+
+		if entry.SkipWorktree || entry.IsSparseDir() {
+			// out of the sparse checkout, not in the working tree
+			continue
+		}
+
+		if entry.IsSubmodule() {
+			var subFiles []string
+
+			if subFiles, err = r.listSubmoduleFiles(entry.Path); err != nil {
+				return
+			}
+
+			files = append(files, subFiles...)
+			continue
+		}
+
+		if _, statErr := os.Lstat(r.path(entry.Path)); statErr != nil {
+			// deleted from the working tree
+			continue
+		}
+
+		files = append(files, entry.Path)
+	}
+
+	var untracked []string
+
+	if untracked, err = r.listUntrackedFiles(tracked); err != nil {
+		return
+	}
+
+	files = append(files, untracked...)
+	sort.Strings(files)
+	return
+}
+
+func (r *Repository) listSubmoduleFiles(subPath string) (files []string, err error) {
+	var (
+		sub      *Repository
+		subFiles []string
+	)
+
+	if sub, err = Open(r.path(subPath)); err != nil {
+		if err == ErrNotRepository {
+			// submodule not initialized
+			err = nil
+		}
+		return
+	}
+
+	if subFiles, err = sub.ListFiles(); err != nil {
+		return
+	}
+
+	for _, file := range subFiles {
+		files = append(files, path.Join(subPath, file))
+	}
+
+	return
+}
+
+func (r *Repository) listUntrackedFiles(tracked map[string]bool) (files []string, err error) {
+	matcher := ignore.NewMatcher()
+
+	// the global excludes file has the lowest precedence, so it's added first
+	if excludesFile := r.excludesFile(); excludesFile != "" {
+		if err = matcher.AddFile(excludesFile, ""); err != nil {
+			return
+		}
+	}
+
+	if err = matcher.AddFile(filepath.Join(r.gitDir, "info", "exclude"), ""); err != nil {
+		return
+	}
+
+	err = filepath.Walk(r.workTree, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		var relPath string
+
+		if relPath, err = filepath.Rel(r.workTree, file); err != nil {
+			return err
+		}
+
+		relPath = filepath.ToSlash(relPath)
+
+		if fi.IsDir() {
+			if relPath == "." {
+				return matcher.AddFile(filepath.Join(file, ".gitignore"), "")
+			}
+
+			if fi.Name() == ".git" || matcher.Match(relPath, true) {
+				return filepath.SkipDir
+			}
+
+			if _, err = os.Lstat(filepath.Join(file, ".git")); err == nil {
+				// nested repository or submodule, handled apart
+				return filepath.SkipDir
+			}
+
+			return matcher.AddFile(filepath.Join(file, ".gitignore"), relPath)
+		}
+
+		if !tracked[relPath] && !matcher.Match(relPath, false) {
+			files = append(files, relPath)
+		}
+
+		return nil
+	})
+
+	return
+}
+
+func (r *Repository) path(relPath string) string {
+	return filepath.Join(r.workTree, filepath.FromSlash(relPath))
+}
@@ -0,0 +1,262 @@
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=kool", "GIT_AUTHOR_EMAIL=kool@kool.dev",
+		"GIT_COMMITTER_NAME=kool", "GIT_COMMITTER_EMAIL=kool@kool.dev",
+		"GIT_CONFIG_NOSYSTEM=1", "HOME="+dir,
+	)
+
+	out, err := cmd.CombinedOutput()
+
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+
+	return string(out)
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed writing %s: %v", name, err)
+		}
+	}
+}
+
+func gitListFiles(t *testing.T, dir string) (files []string) {
+	deleted := make(map[string]bool)
+
+	for _, file := range strings.Split(runGit(t, dir, "ls-files", "-d"), "\n") {
+		deleted[file] = true
+	}
+
+	output := runGit(t, dir, "ls-files", "-c") + runGit(t, dir, "ls-files", "-o", "--exclude-standard")
+
+	for _, file := range strings.Split(output, "\n") {
+		if file != "" && !deleted[file] {
+			files = append(files, file)
+		}
+	}
+
+	sort.Strings(files)
+	return
+}
+
+func createTestRepository(t *testing.T) (dir string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is required for generating test repositories")
+	}
+
+	dir, _ = ioutil.TempDir("", "kool-git")
+
+	runGit(t, dir, "init", "-q")
+	writeFiles(t, dir, map[string]string{
+		".gitignore":               "*.log\n/build/\n!important.log\n",
+		"app.js":                   "app",
+		"deleted.txt":              "deleted",
+		"important.log":            "keep",
+		"src/index.js":             "index",
+		"src/.gitignore":           "generated/\n",
+		"src/generated/out.js":     "out",
+		"build/bundle.js":          "bundle",
+		"docs/a-very-long-name.md": "docs",
+	})
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-qm", "initial")
+
+	_ = os.Remove(filepath.Join(dir, "deleted.txt"))
+	writeFiles(t, dir, map[string]string{
+		"untracked.txt":   "new",
+		"debug.log":       "ignored",
+		"src/new.js":      "new",
+		"src/generated/x": "ignored",
+		"build/other.js":  "ignored",
+	})
+
+	return
+}
+
+func assertSameFiles(t *testing.T, dir string) {
+	repo, err := Open(dir)
+
+	if err != nil {
+		t.Fatalf("unexpected error opening repository: %v", err)
+	}
+
+	files, err := repo.ListFiles()
+
+	if err != nil {
+		t.Fatalf("unexpected error listing files: %v", err)
+	}
+
+	expected := gitListFiles(t, dir)
+
+	if strings.Join(files, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected files %v, got %v", expected, files)
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	dir := createTestRepository(t)
+	defer os.RemoveAll(dir)
+
+	assertSameFiles(t, dir)
+}
+
+// setTestEnv sets the environment variable for the test, restoring it afterwards
+func setTestEnv(t *testing.T, key string, value string) {
+	original, isSet := os.LookupEnv(key)
+
+	t.Cleanup(func() {
+		if isSet {
+			os.Setenv(key, original)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+
+	os.Setenv(key, value)
+}
+
+func TestListFilesGlobalExcludesFile(t *testing.T) {
+	dir := createTestRepository(t)
+	defer os.RemoveAll(dir)
+
+	// runGit uses the repository as HOME, so the user git config is there
+	setTestEnv(t, "HOME", dir)
+	setTestEnv(t, "XDG_CONFIG_HOME", filepath.Join(dir, "xdg"))
+
+	writeFiles(t, dir, map[string]string{
+		".gitconfig":    "[user]\n\tname = kool\n[core]\n\texcludesFile = \"~/global-ignore\" # global\n",
+		"global-ignore": "*.secret\n.gitconfig\nglobal-ignore\n",
+		"key.secret":    "ignored",
+		"src/a.secret":  "ignored",
+	})
+
+	assertSameFiles(t, dir)
+
+	repo, _ := Open(dir)
+	files, _ := repo.ListFiles()
+
+	for _, file := range files {
+		if strings.HasSuffix(file, ".secret") {
+			t.Errorf("expected %s to be ignored by the global excludes file", file)
+		}
+	}
+}
+
+func TestListFilesDefaultExcludesFile(t *testing.T) {
+	dir := createTestRepository(t)
+	defer os.RemoveAll(dir)
+
+	setTestEnv(t, "HOME", dir)
+	setTestEnv(t, "XDG_CONFIG_HOME", filepath.Join(dir, "xdg"))
+
+	writeFiles(t, dir, map[string]string{
+		"xdg/git/ignore": "*.secret\nxdg/\n",
+		"key.secret":     "ignored",
+	})
+
+	assertSameFiles(t, dir)
+}
+
+func TestListFilesIndexVersions(t *testing.T) {
+	for _, version := range []string{"2", "3", "4"} {
+		dir := createTestRepository(t)
+
+		runGit(t, dir, "update-index", "--index-version", version)
+		assertSameFiles(t, dir)
+
+		os.RemoveAll(dir)
+	}
+}
+
+func TestListFilesSkipWorktree(t *testing.T) {
+	dir := createTestRepository(t)
+	defer os.RemoveAll(dir)
+
+	runGit(t, dir, "update-index", "--skip-worktree", "app.js")
+	_ = os.Remove(filepath.Join(dir, "app.js"))
+
+	repo, _ := Open(dir)
+	files, _ := repo.ListFiles()
+
+	for _, file := range files {
+		if file == "app.js" {
+			t.Error("did not expect skip-worktree entry to be listed")
+		}
+	}
+}
+
+func TestListFilesSubmodule(t *testing.T) {
+	dir := createTestRepository(t)
+	defer os.RemoveAll(dir)
+
+	sub := createTestRepository(t)
+	defer os.RemoveAll(sub)
+
+	runGit(t, dir, "-c", "protocol.file.allow=always", "submodule", "add", "-q", sub, "lib")
+
+	repo, _ := Open(dir)
+	files, err := repo.ListFiles()
+
+	if err != nil {
+		t.Fatalf("unexpected error listing files: %v", err)
+	}
+
+	var found bool
+	for _, file := range files {
+		if file == "lib/src/index.js" {
+			found = true
+		}
+
+		if file == "lib" {
+			t.Error("did not expect the submodule folder itself to be listed")
+		}
+	}
+
+	if !found {
+		t.Errorf("expected submodule files to be listed, got %v", files)
+	}
+}
+
+func TestOpenNotRepository(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "kool-git")
+	defer os.RemoveAll(dir)
+
+	if _, err := Open(dir); err != ErrNotRepository {
+		t.Errorf("expected ErrNotRepository, got %v", err)
+	}
+
+	_ = ioutil.WriteFile(filepath.Join(dir, ".git"), []byte("garbage"), 0644)
+
+	if _, err := Open(dir); err != ErrNotRepository {
+		t.Errorf("expected ErrNotRepository for bad .git file, got %v", err)
+	}
+}
+
+func TestReadIndexInvalid(t *testing.T) {
+	if _, err := ReadIndex(strings.NewReader("garbage")); err != ErrInvalidIndex {
+		t.Errorf("expected ErrInvalidIndex, got %v", err)
+	}
+
+	if _, err := ReadIndex(strings.NewReader("DIRC\x00\x00\x00\x09\x00\x00\x00\x00")); err == nil {
+		t.Error("expected error for unsupported index version")
+	}
+}
@@ -8,15 +8,19 @@ require (
 	github.com/blang/semver v3.5.1+incompatible
 	github.com/creack/pty v1.1.11
 	github.com/fireworkweb/godotenv v1.3.1-0.20200525231918-bdecbe8dfc58
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510
 	github.com/gookit/color v1.2.9
 	github.com/jedib0t/go-pretty/v6 v6.0.2
+	github.com/klauspost/compress v1.11.13
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/moby/term v0.0.0-20200915141129-7f0af18e79f2
 	github.com/rhysd/go-github-selfupdate v1.2.2
 	github.com/spf13/afero v1.4.1
 	github.com/spf13/cobra v1.1.1
+	github.com/spf13/pflag v1.0.5
 	github.com/ugorji/go v1.1.4 // indirect
 	github.com/xordataexchange/crypt v0.0.3-0.20170626215501-b2862e3d0a77 // indirect
 	gopkg.in/yaml.v2 v2.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,250 @@
+package ignore
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Matcher holds a list of ignore patterns following the
+// gitignore semantics - the last matching pattern wins,
+// negation with a leading "!", directory only patterns
+// with a trailing "/" and "**" for matching across folders.
+type Matcher struct {
+	patterns []*pattern
+}
+
+type pattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// NewMatcher creates a new matcher with the given patterns,
+// relative to the root folder.
+func NewMatcher(patterns ...string) (m *Matcher) {
+	m = new(Matcher)
+	m.AddPatterns("", patterns)
+	return
+}
+
+// AddPatterns appends the given patterns to the matcher. The base
+// is the folder - relative to the root - the patterns were read
+// from, just like a .gitignore file within a sub folder.
+func (m *Matcher) AddPatterns(base string, patterns []string) {
+	base = strings.Trim(filepath.ToSlash(base), "/")
+
+	for _, line := range patterns {
+		if p := parsePattern(base, line); p != nil {
+			m.patterns = append(m.patterns, p)
+		}
+	}
+}
+
+// AddFile reads the patterns from the given file, if it exists,
+// and appends them to the matcher having base as reference folder.
+func (m *Matcher) AddFile(filename string, base string) (err error) {
+	var (
+		file     *os.File
+		patterns []string
+	)
+
+	if file, err = os.Open(filename); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+
+	defer file.Close()
+
+	if patterns, err = ReadPatterns(file); err != nil {
+		return
+	}
+
+	m.AddPatterns(base, patterns)
+	return
+}
+
+// ReadPatterns reads the lines of patterns from the given reader
+func ReadPatterns(r io.Reader) (patterns []string, err error) {
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+
+	err = scanner.Err()
+	return
+}
+
+// Match tells whether the given path - relative to the root
+// folder - should be ignored. A path is ignored as well when
+// any of its parent folders is ignored.
+func (m *Matcher) Match(relPath string, isDir bool) bool {
+	if m == nil || len(m.patterns) == 0 {
+		return false
+	}
+
+	relPath = strings.Trim(filepath.ToSlash(relPath), "/")
+
+	if relPath == "" {
+		return false
+	}
+
+	parts := strings.Split(relPath, "/")
+
+	for i := 1; i < len(parts); i++ {
+		if m.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+
+	return m.match(relPath, isDir)
+}
+
+func (m *Matcher) match(relPath string, isDir bool) (ignored bool) {
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+
+		if p.re.MatchString(relPath) {
+			ignored = !p.negate
+		}
+	}
+
+	return
+}
+
+func parsePattern(base string, line string) (p *pattern) {
+	line = trimTrailingSpaces(strings.TrimSuffix(line, "\r"))
+
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+
+	p = new(pattern)
+
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	if line == "" {
+		return nil
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("^")
+
+	if base != "" {
+		sb.WriteString(regexp.QuoteMeta(base + "/"))
+	}
+
+	// patterns with a slash at the beginning or middle are relative
+	// to the base folder, otherwise they can match at any level
+	if strings.Contains(line, "/") {
+		line = strings.TrimPrefix(line, "/")
+	} else {
+		sb.WriteString("(?:.*/)?")
+	}
+
+	sb.WriteString(globToRegexp(line))
+	sb.WriteString("$")
+
+	var err error
+	if p.re, err = regexp.Compile(sb.String()); err != nil {
+		return nil
+	}
+
+	return
+}
+
+func globToRegexp(glob string) string {
+	var (
+		sb    strings.Builder
+		runes = []rune(glob)
+	)
+
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				leading := i == 0 || runes[i-1] == '/'
+				trailing := i+2 == len(runes) || runes[i+2] == '/'
+
+				if leading && trailing {
+					if i+2 == len(runes) {
+						// "/**" matches everything inside
+						sb.WriteString(".*")
+					} else {
+						// "**/" matches zero or more folders
+						sb.WriteString("(?:.*/)?")
+						i++
+					}
+					i++
+					continue
+				}
+
+				sb.WriteString("[^/]*")
+				i++
+				continue
+			}
+
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			if end := closingBracket(runes, i); end > 0 {
+				class := string(runes[i+1 : end])
+				if strings.HasPrefix(class, "!") {
+					class = "^" + class[1:]
+				}
+				sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+				i = end
+				continue
+			}
+
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(runes[i])))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return sb.String()
+}
+
+func closingBracket(runes []rune, start int) int {
+	for i := start + 2; i < len(runes); i++ {
+		if runes[i] == ']' {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func trimTrailingSpaces(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+
+	return line
+}
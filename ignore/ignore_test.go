@@ -0,0 +1,116 @@
+package ignore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	var tests = []struct {
+		patterns []string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{[]string{".env"}, ".env", false, true},
+		{[]string{".env"}, "sub/.env", false, true},
+		{[]string{".env"}, ".env.example", false, false},
+		{[]string{"*.log"}, "storage/logs/app.log", false, true},
+		{[]string{"/build"}, "build", true, true},
+		{[]string{"/build"}, "sub/build", true, false},
+		{[]string{"node_modules"}, "node_modules/pkg/index.js", false, true},
+		{[]string{"node_modules/"}, "node_modules", false, false},
+		{[]string{"node_modules/"}, "node_modules", true, true},
+		{[]string{"docs/*.md"}, "docs/a.md", false, true},
+		{[]string{"docs/*.md"}, "docs/sub/a.md", false, false},
+		{[]string{"**/cache"}, "a/b/cache", true, true},
+		{[]string{"**/cache"}, "cache", true, true},
+		{[]string{"logs/**"}, "logs/a/b.txt", false, true},
+		{[]string{"a/**/b"}, "a/b", false, true},
+		{[]string{"a/**/b"}, "a/x/y/b", false, true},
+		{[]string{"*.log", "!keep.log"}, "keep.log", false, false},
+		{[]string{"*.log", "!keep.log"}, "other.log", false, true},
+		{[]string{"vendor", "!vendor/keep.php"}, "vendor/keep.php", false, true},
+		{[]string{"file?.txt"}, "file1.txt", false, true},
+		{[]string{"file[0-9].txt"}, "file5.txt", false, true},
+		{[]string{"file[!0-9].txt"}, "file5.txt", false, false},
+		{[]string{"# comment", ""}, "# comment", false, false},
+		{[]string{`\#file`}, "#file", false, true},
+		{[]string{`\!file`}, "!file", false, true},
+		{[]string{"trailing   "}, "trailing", false, true},
+	}
+
+	for _, test := range tests {
+		m := NewMatcher(test.patterns...)
+
+		if got := m.Match(test.path, test.isDir); got != test.expected {
+			t.Errorf("patterns %v matching %s (dir: %v): expected %v, got %v", test.patterns, test.path, test.isDir, test.expected, got)
+		}
+	}
+}
+
+func TestMatchNilMatcher(t *testing.T) {
+	var m *Matcher
+
+	if m.Match("any", false) {
+		t.Error("nil matcher should not match anything")
+	}
+}
+
+func TestAddPatternsWithBase(t *testing.T) {
+	m := NewMatcher()
+	m.AddPatterns("sub", []string{"/local", "*.tmp"})
+
+	if !m.Match("sub/local", false) {
+		t.Error("expected sub/local to match pattern anchored at sub")
+	}
+
+	if m.Match("local", false) {
+		t.Error("did not expect local to match pattern anchored at sub")
+	}
+
+	if !m.Match("sub/deep/file.tmp", false) {
+		t.Error("expected sub/deep/file.tmp to match")
+	}
+
+	if m.Match("other/file.tmp", false) {
+		t.Error("did not expect other/file.tmp to match pattern from sub")
+	}
+}
+
+func TestAddFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "kool-ignore")
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, ".koolignore")
+	_ = ioutil.WriteFile(filename, []byte("# ignore logs\r\n*.log\n!keep.log\n"), os.ModePerm)
+
+	m := NewMatcher()
+
+	if err := m.AddFile(filename, ""); err != nil {
+		t.Fatalf("unexpected error adding file: %v", err)
+	}
+
+	if !m.Match("app.log", false) || m.Match("keep.log", false) {
+		t.Error("patterns from file were not properly loaded")
+	}
+
+	if err := m.AddFile(filepath.Join(dir, "missing"), ""); err != nil {
+		t.Errorf("unexpected error adding missing file: %v", err)
+	}
+}
+
+func TestReadPatterns(t *testing.T) {
+	patterns, err := ReadPatterns(strings.NewReader("a\nb\n"))
+
+	if err != nil {
+		t.Fatalf("unexpected error reading patterns: %v", err)
+	}
+
+	if len(patterns) != 2 || patterns[0] != "a" || patterns[1] != "b" {
+		t.Errorf("unexpected patterns read: %v", patterns)
+	}
+}
@@ -5,10 +5,10 @@ package main
 import (
 	"fmt"
 	"io/ioutil"
+	presetsPkg "kool-dev/kool/cmd/presets"
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
 )
 
 const presetsTemplate string = `package presets
@@ -23,6 +23,9 @@ func main() {
 		files   []os.FileInfo
 		err     error
 	)
+
+	validateManifests()
+
 	presets, err := os.Create("cmd/presets/presets.go")
 
 	if err != nil {
@@ -76,17 +79,8 @@ func main() {
 
 			filecontent := string(filebytes)
 
-			if file.Name() == ".preset" {
-				lines := strings.Split(strings.TrimSpace(filecontent), "\n")
-
-				for _, line := range lines {
-					metadata := strings.Split(line, "=")
-					presets.WriteString(fmt.Sprintf("\t\t\"preset_%s\": \"%s\",\n", metadata[0], metadata[1]))
-				}
-			} else {
-				presets.WriteString(fmt.Sprintf("\t\t\"%s\": `%s`,\n", file.Name(), filecontent))
-				fmt.Println("Parsed file:", file.Name())
-			}
+			presets.WriteString(fmt.Sprintf("\t\t\"%s\": `%s`,\n", file.Name(), filecontent))
+			fmt.Println("Parsed file:", file.Name())
 
 			presetFile.Close()
 		}
@@ -95,7 +89,7 @@ func main() {
 	}
 
 	presets.WriteString("\treturn presets\n")
-	presets.WriteString("}\n")
+	presets.WriteString("}\n\n")
 
 	presets.WriteString("// GetTemplates get all templates\n")
 	presets.WriteString("func GetTemplates() map[string]map[string]string {\n")
@@ -151,3 +145,39 @@ func main() {
 
 	fmt.Println("Finished building cmd/presets/presets.go")
 }
+
+// validateManifests makes sure every preset has a valid
+// manifest, including the templates it refers to.
+func validateManifests() {
+	folders, err := ioutil.ReadDir("presets")
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	hasTemplate := func(path string) bool {
+		_, statErr := os.Stat(filepath.Join("templates", filepath.FromSlash(path)))
+		return statErr == nil
+	}
+
+	for _, folder := range folders {
+		var (
+			content  []byte
+			manifest *presetsPkg.Manifest
+		)
+
+		if !folder.IsDir() {
+			continue
+		}
+
+		if content, err = ioutil.ReadFile(filepath.Join("presets", folder.Name(), presetsPkg.PresetManifestFile)); err == nil {
+			if manifest, err = presetsPkg.ParseManifest(string(content)); err == nil {
+				err = manifest.Validate(hasTemplate)
+			}
+		}
+
+		if err != nil {
+			log.Fatalf("preset %s: %v", folder.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package tgz
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression represents the compression algorithm applied to the tarball
+type Compression int
+
+const (
+	// Gzip is the default compression, generating .tar.gz files
+	Gzip Compression = iota
+	// Zstd compression, generating .tar.zst files
+	Zstd
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// ErrUnknownCompression is returned when reading a tarball that
+// is neither Gzip nor Zstd compressed
+var ErrUnknownCompression = errors.New("unknown tarball compression")
+
+func newCompressWriter(compression Compression, w io.Writer) (io.WriteCloser, error) {
+	if compression == Zstd {
+		return zstd.NewWriter(w)
+	}
+
+	// the default Gzip header holds no file name nor modification
+	// time, so the compressed output depends only on its content
+	return gzip.NewWriter(w), nil
+}
+
+// newDecompressReader detects the compression of the given reader
+// based on its magic number and returns the decompressed stream.
+func newDecompressReader(r io.Reader) (io.ReadCloser, error) {
+	var (
+		buffered = bufio.NewReader(r)
+		magic    []byte
+		err      error
+	)
+
+	if magic, err = buffered.Peek(len(zstdMagic)); err != nil && len(magic) < len(gzipMagic) {
+		return nil, ErrUnknownCompression
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(buffered)
+	case bytes.HasPrefix(magic, zstdMagic):
+		var decoder *zstd.Decoder
+
+		if decoder, err = zstd.NewReader(buffered); err != nil {
+			return nil, err
+		}
+
+		return decoder.IOReadCloser(), nil
+	}
+
+	return nil, ErrUnknownCompression
+}
@@ -0,0 +1,134 @@
+package tgz
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Extract unpacks the given tarball - either Gzip or Zstd
+// compressed - into the destination folder, restoring folders,
+// regular files with their permissions and symbolic links.
+func Extract(filename string, dest string) (err error) {
+	var (
+		file   *os.File
+		r      io.ReadCloser
+		t      *tar.Reader
+		header *tar.Header
+	)
+
+	if file, err = os.Open(filename); err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	if r, err = newDecompressReader(file); err != nil {
+		return
+	}
+
+	defer r.Close()
+
+	t = tar.NewReader(r)
+
+	for {
+		if header, err = t.Next(); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			return
+		}
+
+		if err = extractEntry(t, header, dest); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func extractEntry(t *tar.Reader, header *tar.Header, dest string) (err error) {
+	dest = filepath.Clean(dest)
+
+	var (
+		target = filepath.Join(dest, filepath.FromSlash(header.Name))
+		mode   = os.FileMode(header.Mode).Perm()
+	)
+
+	// prevents entries from escaping the destination folder
+	if !isWithin(dest, target) {
+		return fmt.Errorf("invalid tarball entry %s", header.Name)
+	}
+
+	// prevents entries from being written through previously
+	// extracted symbolic links, which could point anywhere
+	if err = checkNoSymlinks(dest, target); err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return
+	}
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		err = os.MkdirAll(target, mode)
+	case tar.TypeSymlink:
+		link := filepath.FromSlash(header.Linkname)
+
+		if filepath.IsAbs(link) || !isWithin(dest, filepath.Join(filepath.Dir(target), link)) {
+			return fmt.Errorf("invalid tarball entry %s: link to %s is outside the destination", header.Name, header.Linkname)
+		}
+
+		err = os.Symlink(header.Linkname, target)
+	case tar.TypeReg:
+		err = extractFile(t, target, mode)
+	}
+
+	return
+}
+
+// isWithin tells whether the path is the folder or lies within it
+func isWithin(dir string, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
+
+// checkNoSymlinks makes sure neither the target nor any of its parent
+// folders below the destination folder are symbolic links.
+func checkNoSymlinks(dest string, target string) error {
+	for path := target; path != dest && isWithin(dest, path); path = filepath.Dir(path) {
+		fi, err := os.Lstat(path)
+
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if fi.Mode()&os.ModeSymlink != 0 {
+			rel, _ := filepath.Rel(dest, target)
+			return fmt.Errorf("invalid tarball entry %s: it goes through a symbolic link", filepath.ToSlash(rel))
+		}
+	}
+
+	return nil
+}
+
+func extractFile(t *tar.Reader, target string, mode os.FileMode) (err error) {
+	var fh *os.File
+
+	if fh, err = os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode); err != nil {
+		return
+	}
+
+	defer fh.Close()
+
+	_, err = io.Copy(fh, t)
+	return
+}
@@ -0,0 +1,59 @@
+package tgz
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+)
+
+// Entry describes a single item stored within a tarball
+type Entry struct {
+	Name     string
+	Size     int64
+	IsDir    bool
+	Linkname string
+}
+
+// List reads the given tarball - either Gzip or Zstd compressed -
+// and returns the entries it holds in the order they were stored.
+func List(filename string) (entries []*Entry, err error) {
+	var (
+		file   *os.File
+		r      io.ReadCloser
+		t      *tar.Reader
+		header *tar.Header
+	)
+
+	if file, err = os.Open(filename); err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	if r, err = newDecompressReader(file); err != nil {
+		return
+	}
+
+	defer r.Close()
+
+	t = tar.NewReader(r)
+
+	for {
+		if header, err = t.Next(); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			return
+		}
+
+		entries = append(entries, &Entry{
+			Name:  header.Name,
+			Size:  header.Size,
+			IsDir: header.Typeflag == tar.TypeDir,
+
+			Linkname: header.Linkname,
+		})
+	}
+
+	return
+}
@@ -2,14 +2,18 @@ package tgz
 
 import (
 	"archive/tar"
-	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
-	"kool-dev/kool/cmd/shell"
+	"kool-dev/kool/ignore"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 )
 
 // TarGz holds configuration for generating a new
@@ -18,52 +22,147 @@ type TarGz struct {
 	file      *os.File
 
 	ignoreFilesMap map[string]bool
+	ignoreMatcher  *ignore.Matcher
 
-	g *gzip.Writer
+	reproducible bool
+	modTime      time.Time
+
+	compression Compression
+	dereference bool
+	visitedDirs map[string]bool
+	errs        AddErrors
+
+	hash     hash.Hash
+	checksum string
+
+	c io.WriteCloser
 	t *tar.Writer
 }
 
+// AddErrors aggregates the errors of all the files
+// that failed being added to the tarball.
+type AddErrors []error
+
+// Error implements the error interface
+func (e AddErrors) Error() string {
+	messages := make([]string, len(e))
+
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return fmt.Sprintf("failed to add %d file(s) into archive: %s", len(e), strings.Join(messages, "; "))
+}
+
 // NewTemp allocates and opens files for generating a new tarball
 // with Gzip compression in a temporary file.
 func NewTemp() (tgz *TarGz, err error) {
-	tgz = new(TarGz)
-	tgz.file, err = ioutil.TempFile(os.TempDir(), "*.tgz")
-	if err != nil {
-		tgz = nil
+	var file *os.File
+
+	if file, err = ioutil.TempFile(os.TempDir(), "*.tgz"); err != nil {
 		return
 	}
-	tgz.g = gzip.NewWriter(tgz.file)
-	tgz.t = tar.NewWriter(tgz.g)
+
+	tgz = newTarGz(file)
 	return
 }
 
-// CompressFiles creates the tarball with the given files list
+// New allocates and opens the given file path for generating
+// a new tarball with Gzip compression.
+func New(filename string) (tgz *TarGz, err error) {
+	var file *os.File
+
+	if file, err = os.Create(filename); err != nil {
+		return
+	}
+
+	tgz = newTarGz(file)
+	return
+}
+
+func newTarGz(file *os.File) (tgz *TarGz) {
+	tgz = new(TarGz)
+	tgz.file = file
+	tgz.hash = sha256.New()
+	return
+}
+
+// SetCompression defines the compression algorithm to be
+// used; it must be set before compressing any files.
+func (tgz *TarGz) SetCompression(compression Compression) {
+	tgz.compression = compression
+}
+
+// SetDereference defines whether symbolic links should be
+// followed and their targets added in place of the links.
+// By default symbolic links are stored as link entries.
+func (tgz *TarGz) SetDereference(dereference bool) {
+	tgz.dereference = dereference
+}
+
+// SetReproducible turns on the reproducible mode, so building the
+// same files twice generates byte for byte the same tarball. Entries
+// are sorted and their modification time, ownership and permissions
+// are normalized - all entries get the given modification time.
+func (tgz *TarGz) SetReproducible(modTime time.Time) {
+	tgz.reproducible = true
+	tgz.modTime = modTime
+}
+
+// Name returns the name of the tarball file being generated
+func (tgz *TarGz) Name() string {
+	return tgz.file.Name()
+}
+
+// Hash returns the hex encoded SHA-256 checksum of the tarball
+// generated; it's only available after compressing is finished.
+func (tgz *TarGz) Hash() string {
+	return tgz.checksum
+}
+
+// CompressFiles creates the tarball with the given files list. In
+// case some of the files fail to be added the tarball is still
+// created and an AddErrors error is returned.
 func (tgz *TarGz) CompressFiles(files []string) (tmpfile string, err error) {
 	var (
 		file string
 		fi   os.FileInfo
 	)
 
+	if err = tgz.open(); err != nil {
+		return
+	}
+
+	if tgz.reproducible {
+		files = append([]string(nil), files...)
+		sort.Strings(files)
+	}
+
 	for _, file = range files {
 		if file == "" {
 			continue
 		}
 
-		fi, err = os.Stat(file)
-		if addErr := tgz.add(file, fi, err); err != nil {
-			shell.NewOutputWriter().Error(fmt.Errorf("failed to add file into archive: %v", addErr))
-		}
+		fi, err = os.Lstat(file)
+		tgz.collect(file, tgz.add(file, fi, err))
 	}
 
 	tmpfile, err = tgz.finishCompress()
 	return
 }
 
-// CompressFolder adds the given folder to the tarball archive
+// CompressFolder adds the given folder to the tarball archive. In
+// case some of the files fail to be added the tarball is still
+// created and an AddErrors error is returned.
 func (tgz *TarGz) CompressFolder(dir string) (tmpfile string, err error) {
 	tgz.sourceDir = dir
 
-	if err = filepath.Walk(tgz.sourceDir, tgz.add); err != nil {
+	if err = tgz.open(); err != nil {
+		return
+	}
+
+	if err = filepath.Walk(tgz.sourceDir, tgz.walk); err != nil {
+		tgz.close()
 		return
 	}
 
@@ -71,70 +170,184 @@ func (tgz *TarGz) CompressFolder(dir string) (tmpfile string, err error) {
 	return
 }
 
+func (tgz *TarGz) open() (err error) {
+	if tgz.c, err = newCompressWriter(tgz.compression, io.MultiWriter(tgz.file, tgz.hash)); err != nil {
+		tgz.close()
+		return
+	}
+
+	tgz.t = tar.NewWriter(tgz.c)
+	return
+}
+
+func (tgz *TarGz) close() {
+	tgz.file.Close()
+}
+
 func (tgz *TarGz) finishCompress() (tmpfile string, err error) {
+	defer tgz.close()
+
 	if err = tgz.t.Close(); err != nil {
 		return
 	}
-	if err = tgz.g.Close(); err != nil {
+	if err = tgz.c.Close(); err != nil {
 		return
 	}
 	if err = tgz.file.Sync(); err != nil {
 		return
 	}
 
+	tgz.checksum = hex.EncodeToString(tgz.hash.Sum(nil))
+
 	tmpfile = tgz.file.Name()
-	err = tgz.file.Close()
+
+	if len(tgz.errs) > 0 {
+		err = tgz.errs
+	}
 	return
 }
 
+// walk is the filepath.WalkFunc for adding folders, which collects
+// errors of single files so the walking goes on.
+func (tgz *TarGz) walk(file string, fi os.FileInfo, err error) error {
+	if err = tgz.add(file, fi, err); err == filepath.SkipDir {
+		return err
+	}
+
+	tgz.collect(file, err)
+	return nil
+}
+
+func (tgz *TarGz) collect(file string, err error) {
+	if err != nil && err != filepath.SkipDir {
+		tgz.errs = append(tgz.errs, fmt.Errorf("%s: %v", file, err))
+	}
+}
+
 func (tgz *TarGz) add(file string, fi os.FileInfo, err error) error {
 	var (
 		relPath string
 		header  *tar.Header
-		fh      *os.File
+		link    string
 	)
 
 	if err != nil {
 		return err
 	}
 
-	if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
-		// ignore symlink
-		return nil
-	}
-
 	relPath = strings.TrimPrefix(file, tgz.sourceDir)
 
 	if relPath == "" || relPath == "/" {
 		return nil
 	}
 
-	if tgz.shouldIgnore(relPath) {
+	if tgz.shouldIgnore(relPath) || tgz.isOwnFile(fi) {
 		return nil
 	}
 
-	header, err = tar.FileInfoHeader(fi, file)
+	isSymlink := fi.Mode()&os.ModeSymlink == os.ModeSymlink
+
+	if isSymlink && tgz.dereference {
+		return tgz.addDereferenced(file)
+	}
+
+	if tgz.ignoreMatcher.Match(relPath, fi.IsDir()) {
+		if fi.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	}
+
+	if isSymlink {
+		if link, err = os.Readlink(file); err != nil {
+			return err
+		}
+	}
+
+	header, err = tar.FileInfoHeader(fi, link)
 	if err != nil {
 		return err
 	}
-	header.Name = strings.TrimPrefix(relPath, "/")
+	header.Name = strings.TrimPrefix(filepath.ToSlash(relPath), "/")
+
+	if tgz.reproducible {
+		tgz.normalizeHeader(header, fi)
+	}
+
 	if err = tgz.t.WriteHeader(header); err != nil {
 		return err
 	}
 
-	if !fi.IsDir() {
-		fh, err = os.Open(file)
-		if err != nil {
-			return err
-		}
-		if _, err = io.Copy(tgz.t, fh); err != nil {
-			return err
-		}
+	if fi.Mode().IsRegular() {
+		return tgz.copyFile(file)
 	}
 
 	return nil
 }
 
+// addDereferenced adds the target of the given symbolic link in
+// its place - for folders, all of their contents are added.
+func (tgz *TarGz) addDereferenced(file string) (err error) {
+	var (
+		fi     os.FileInfo
+		target string
+	)
+
+	if fi, err = os.Stat(file); err != nil {
+		return
+	}
+
+	if !fi.IsDir() {
+		return tgz.add(file, fi, nil)
+	}
+
+	if target, err = filepath.EvalSymlinks(file); err != nil {
+		return
+	}
+
+	if tgz.visitedDirs == nil {
+		tgz.visitedDirs = make(map[string]bool)
+	}
+
+	if tgz.visitedDirs[target] {
+		return fmt.Errorf("symbolic link loop to %s", target)
+	}
+
+	tgz.visitedDirs[target] = true
+	defer delete(tgz.visitedDirs, target)
+
+	return filepath.Walk(target, func(path string, fi os.FileInfo, err error) error {
+		return tgz.walk(file+strings.TrimPrefix(path, target), fi, err)
+	})
+}
+
+func (tgz *TarGz) copyFile(file string) (err error) {
+	var fh *os.File
+
+	if fh, err = os.Open(file); err != nil {
+		return
+	}
+
+	defer fh.Close()
+
+	_, err = io.Copy(tgz.t, fh)
+	return
+}
+
+func (tgz *TarGz) normalizeHeader(header *tar.Header, fi os.FileInfo) {
+	header.ModTime = tgz.modTime
+	header.AccessTime = time.Time{}
+	header.ChangeTime = time.Time{}
+	header.Uid, header.Gid = 0, 0
+	header.Uname, header.Gname = "", ""
+
+	if fi.IsDir() || fi.Mode()&0111 != 0 {
+		header.Mode = 0755
+	} else {
+		header.Mode = 0644
+	}
+}
+
 // SetIgnoreList defines the list of file patterns
 // that must be ignored from the tarball created.
 func (tgz *TarGz) SetIgnoreList(ignoreList []string) {
@@ -145,6 +358,20 @@ func (tgz *TarGz) SetIgnoreList(ignoreList []string) {
 	}
 }
 
+// isOwnFile tells whether the given file is the tarball being
+// written itself, which happens when it's placed within the
+// folder being compressed.
+func (tgz *TarGz) isOwnFile(fi os.FileInfo) bool {
+	ownFi, err := tgz.file.Stat()
+	return err == nil && os.SameFile(ownFi, fi)
+}
+
+// SetIgnoreMatcher defines the gitignore like patterns matcher
+// for files and folders that must be ignored from the tarball created.
+func (tgz *TarGz) SetIgnoreMatcher(matcher *ignore.Matcher) {
+	tgz.ignoreMatcher = matcher
+}
+
 func (tgz *TarGz) shouldIgnore(relPath string) (ignores bool) {
 	_, ignores = tgz.ignoreFilesMap[strings.Trim(relPath, string(os.PathSeparator))]
 	return
@@ -0,0 +1,372 @@
+package tgz
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"kool-dev/kool/ignore"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func createTestFolder(t *testing.T, files map[string]string) (dir string) {
+	var err error
+
+	if dir, err = ioutil.TempDir("", "kool-tgz"); err != nil {
+		t.Fatalf("failed creating temporary folder: %v", err)
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+
+		if err = ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed creating file %s: %v", name, err)
+		}
+	}
+
+	return
+}
+
+func listFileNames(t *testing.T, filename string) (names []string) {
+	entries, err := List(filename)
+
+	if err != nil {
+		t.Fatalf("failed listing tarball: %v", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir {
+			names = append(names, entry.Name)
+		}
+	}
+
+	sort.Strings(names)
+	return
+}
+
+func TestCompressFolderIgnoreMatcher(t *testing.T) {
+	dir := createTestFolder(t, map[string]string{
+		"app.js":                  "app",
+		".env":                    "SECRET=1",
+		".env.example":            "SECRET=",
+		"node_modules/pkg/a.js":   "pkg",
+		"storage/logs/app.log":    "log",
+		"storage/logs/.gitignore": "*",
+	})
+	defer os.RemoveAll(dir)
+
+	tarball, err := New(filepath.Join(dir, "release.tgz"))
+
+	if err != nil {
+		t.Fatalf("failed creating tarball: %v", err)
+	}
+
+	tarball.SetIgnoreMatcher(ignore.NewMatcher(".env", "node_modules", "*.log"))
+
+	filename, err := tarball.CompressFolder(dir)
+
+	if err != nil {
+		t.Fatalf("failed compressing folder: %v", err)
+	}
+
+	names := strings.Join(listFileNames(t, filename), ",")
+
+	if names != ".env.example,app.js,storage/logs/.gitignore" {
+		t.Errorf("unexpected tarball contents: %s", names)
+	}
+}
+
+func TestCompressFilesIgnoreMatcher(t *testing.T) {
+	dir := createTestFolder(t, map[string]string{
+		"app.js":                "app",
+		"node_modules/pkg/a.js": "pkg",
+	})
+	defer os.RemoveAll(dir)
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(dir)
+	defer func() { _ = os.Chdir(cwd) }()
+
+	tarball, err := NewTemp()
+
+	if err != nil {
+		t.Fatalf("failed creating tarball: %v", err)
+	}
+
+	tarball.SetIgnoreMatcher(ignore.NewMatcher("node_modules"))
+
+	filename, err := tarball.CompressFiles([]string{"app.js", "node_modules/pkg/a.js", ""})
+
+	if err != nil {
+		t.Fatalf("failed compressing files: %v", err)
+	}
+
+	defer os.Remove(filename)
+
+	names := strings.Join(listFileNames(t, filename), ",")
+
+	if names != "app.js" {
+		t.Errorf("unexpected tarball contents: %s", names)
+	}
+}
+
+func TestReproducible(t *testing.T) {
+	dir := createTestFolder(t, map[string]string{
+		"b.txt":     "b",
+		"a.txt":     "a",
+		"sub/c.txt": "c",
+	})
+	defer os.RemoveAll(dir)
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(dir)
+	defer func() { _ = os.Chdir(cwd) }()
+
+	build := func() (checksum string, content []byte) {
+		tarball, err := NewTemp()
+
+		if err != nil {
+			t.Fatalf("failed creating tarball: %v", err)
+		}
+
+		tarball.SetReproducible(time.Unix(0, 0))
+
+		filename, err := tarball.CompressFiles([]string{"b.txt", "sub/c.txt", "a.txt"})
+
+		if err != nil {
+			t.Fatalf("failed compressing files: %v", err)
+		}
+
+		defer os.Remove(filename)
+
+		content, _ = ioutil.ReadFile(filename)
+		checksum = tarball.Hash()
+		return
+	}
+
+	firstChecksum, firstContent := build()
+
+	later := time.Now().Add(time.Hour)
+	_ = os.Chtimes("a.txt", later, later)
+	_ = os.Chmod("b.txt", 0600)
+
+	secondChecksum, secondContent := build()
+
+	if firstChecksum != secondChecksum || !bytes.Equal(firstContent, secondContent) {
+		t.Error("expected reproducible tarballs to be identical")
+	}
+
+	sum := sha256.Sum256(secondContent)
+	if hex.EncodeToString(sum[:]) != secondChecksum {
+		t.Errorf("Hash() does not match the SHA-256 of the tarball file")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, compression := range []Compression{Gzip, Zstd} {
+		dir := createTestFolder(t, map[string]string{
+			"app.js":     "app",
+			"bin/run.sh": "#!/bin/sh",
+			"sub/c.txt":  "c",
+		})
+
+		_ = os.Chmod(filepath.Join(dir, "bin", "run.sh"), 0755)
+		_ = os.Symlink("app.js", filepath.Join(dir, "link.js"))
+		_ = os.Symlink("sub", filepath.Join(dir, "linkdir"))
+
+		tarball, _ := NewTemp()
+		tarball.SetCompression(compression)
+
+		filename, err := tarball.CompressFolder(dir)
+
+		if err != nil {
+			t.Fatalf("failed compressing folder: %v", err)
+		}
+
+		dest, _ := ioutil.TempDir("", "kool-extract")
+
+		if err = Extract(filename, dest); err != nil {
+			t.Fatalf("failed extracting tarball: %v", err)
+		}
+
+		if content, _ := ioutil.ReadFile(filepath.Join(dest, "sub", "c.txt")); string(content) != "c" {
+			t.Errorf("unexpected extracted content: %s", content)
+		}
+
+		if fi, err := os.Stat(filepath.Join(dest, "bin", "run.sh")); err != nil || fi.Mode()&0100 == 0 {
+			t.Error("expected executable bit to be preserved")
+		}
+
+		if link, err := os.Readlink(filepath.Join(dest, "link.js")); err != nil || link != "app.js" {
+			t.Errorf("expected link.js to be preserved as symbolic link, got %s (%v)", link, err)
+		}
+
+		if link, err := os.Readlink(filepath.Join(dest, "linkdir")); err != nil || link != "sub" {
+			t.Errorf("expected linkdir to be preserved as symbolic link, got %s (%v)", link, err)
+		}
+
+		os.RemoveAll(dir)
+		os.RemoveAll(dest)
+		os.Remove(filename)
+	}
+}
+
+func TestDereference(t *testing.T) {
+	dir := createTestFolder(t, map[string]string{
+		"app.js":    "app",
+		"sub/c.txt": "c",
+	})
+	defer os.RemoveAll(dir)
+
+	_ = os.Symlink("app.js", filepath.Join(dir, "link.js"))
+	_ = os.Symlink("sub", filepath.Join(dir, "linkdir"))
+	_ = os.Symlink("..", filepath.Join(dir, "sub", "loop"))
+
+	tarball, _ := NewTemp()
+	tarball.SetDereference(true)
+
+	filename, err := tarball.CompressFolder(dir)
+	defer os.Remove(filename)
+
+	if _, ok := err.(AddErrors); !ok {
+		t.Errorf("expected AddErrors for the symbolic link loop, got %v", err)
+	}
+
+	names := strings.Join(listFileNames(t, filename), ",")
+
+	if !strings.Contains(names, "link.js") || !strings.Contains(names, "linkdir/c.txt") {
+		t.Errorf("expected symbolic links to be dereferenced, got %s", names)
+	}
+
+	entries, _ := List(filename)
+
+	for _, entry := range entries {
+		if entry.Linkname != "" {
+			t.Errorf("did not expect link entries when dereferencing, got %s", entry.Name)
+		}
+	}
+}
+
+func TestCompressFilesAggregatesErrors(t *testing.T) {
+	dir := createTestFolder(t, map[string]string{
+		"app.js": "app",
+	})
+	defer os.RemoveAll(dir)
+
+	tarball, _ := NewTemp()
+
+	filename, err := tarball.CompressFiles([]string{
+		filepath.Join(dir, "missing1"),
+		filepath.Join(dir, "app.js"),
+		filepath.Join(dir, "missing2"),
+	})
+	defer os.Remove(filename)
+
+	errs, ok := err.(AddErrors)
+
+	if !ok || len(errs) != 2 {
+		t.Fatalf("expected two aggregated errors, got %v", err)
+	}
+
+	if !strings.Contains(errs.Error(), "missing1") || !strings.Contains(errs.Error(), "missing2") {
+		t.Errorf("unexpected aggregated error message: %s", errs.Error())
+	}
+
+	if names := listFileNames(t, filename); len(names) != 1 {
+		t.Errorf("expected the tarball to be created with the valid files, got %v", names)
+	}
+}
+
+func TestExtractInvalidEntry(t *testing.T) {
+	dir := createTestFolder(t, map[string]string{
+		"app.js": "app",
+	})
+	defer os.RemoveAll(dir)
+
+	if err := Extract(filepath.Join(dir, "app.js"), dir); err != ErrUnknownCompression {
+		t.Errorf("expected ErrUnknownCompression, got %v", err)
+	}
+}
+
+// writeTestTarball writes a tarball with the given entries as they are,
+// for generating the malicious tarballs kool itself would never create.
+func writeTestTarball(t *testing.T, filename string, headers []*tar.Header) {
+	file, err := os.Create(filename)
+
+	if err != nil {
+		t.Fatalf("failed creating tarball: %v", err)
+	}
+
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	for _, header := range headers {
+		if err = tw.WriteHeader(header); err != nil {
+			t.Fatalf("failed writing tarball entry %s: %v", header.Name, err)
+		}
+
+		if header.Typeflag == tar.TypeReg {
+			_, _ = tw.Write([]byte("pwned"))
+		}
+	}
+
+	_ = tw.Close()
+	_ = gz.Close()
+}
+
+func TestExtractMaliciousSymlinks(t *testing.T) {
+	for name, headers := range map[string][]*tar.Header{
+		"absolute link": {
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "OUTSIDE"},
+			{Name: "link/passwd", Typeflag: tar.TypeReg, Mode: 0644, Size: 5},
+		},
+		"relative link": {
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../outside"},
+			{Name: "link/passwd", Typeflag: tar.TypeReg, Mode: 0644, Size: 5},
+		},
+		"file through inner link": {
+			{Name: "inner/", Typeflag: tar.TypeDir, Mode: 0755},
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "inner"},
+			{Name: "link/passwd", Typeflag: tar.TypeReg, Mode: 0644, Size: 5},
+		},
+	} {
+		root, _ := ioutil.TempDir("", "kool-tgz")
+		dest := filepath.Join(root, "dest")
+		outside := filepath.Join(root, "outside")
+		_ = os.Mkdir(dest, os.ModePerm)
+		_ = os.Mkdir(outside, os.ModePerm)
+
+		for _, header := range headers {
+			if header.Linkname == "OUTSIDE" {
+				header.Linkname = outside
+			}
+		}
+
+		filename := filepath.Join(root, "malicious.tgz")
+		writeTestTarball(t, filename, headers)
+
+		if err := Extract(filename, dest); err == nil {
+			t.Errorf("%s: expected an error extracting the malicious tarball", name)
+		}
+
+		if _, err := os.Stat(filepath.Join(outside, "passwd")); !os.IsNotExist(err) {
+			t.Errorf("%s: expected no file written outside the destination", name)
+		}
+
+		if _, err := os.Stat(filepath.Join(dest, "inner", "passwd")); !os.IsNotExist(err) {
+			t.Errorf("%s: expected no file written through a symbolic link", name)
+		}
+
+		os.RemoveAll(root)
+	}
+}